@@ -32,11 +32,12 @@ func SelectOpenAPIVersion(version openapi.APIVersion) error {
 
 // NewOpenAPI 创建新的 openapi 实例，会返回当前的 openapi 实现的实例
 // 如果需要使用其他版本的实现，需要在调用这个方法之前调用 SelectOpenAPIVersion 方法
-func NewOpenAPI(token *token.Token) openapi.OpenAPI {
-	return openapi.DefaultImpl.Setup(token, false)
+// opts 用于指定限流、重试等可选配置，如 openapi.WithRetryPolicy
+func NewOpenAPI(token *token.Token, opts ...openapi.Option) openapi.OpenAPI {
+	return openapi.DefaultImpl.Setup(token, false, opts...)
 }
 
 // NewSandboxOpenAPI 创建测试环境的 openapi 实例
-func NewSandboxOpenAPI(token *token.Token) openapi.OpenAPI {
-	return openapi.DefaultImpl.Setup(token, true)
+func NewSandboxOpenAPI(token *token.Token, opts ...openapi.Option) openapi.OpenAPI {
+	return openapi.DefaultImpl.Setup(token, true, opts...)
 }
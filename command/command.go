@@ -0,0 +1,151 @@
+// Package command 提供基于文本消息的命令路由，支持别名，类型化的参数与选项，子命令，权限，冷却时间与帮助信息
+package command
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/tencent-connect/botgo/dto"
+)
+
+// RoleGuildOwner 频道主的身份组 ID
+const RoleGuildOwner = "4"
+
+// Handler 命令处理函数，参数与选项的值通过 Context 获取
+type Handler func(ctx context.Context, c *Context) error
+
+// Command 命令定义
+type Command struct {
+	Name        string        // 命令名称
+	Aliases     []string      // 别名
+	Description string        // 说明，用于生成帮助信息
+	Args        []Arg         // 位置参数，按照顺序解析
+	Flags       []Flag        // 选项，支持 --name value，--name=value 与 -n value 的格式
+	Permission  *Permission   // 使用命令需要的权限，为空时所有人都可以使用，子命令需要同时满足父命令的权限
+	Cooldown    time.Duration // 同一个用户两次使用命令的最小间隔，0 表示不限制
+	Hidden      bool          // 是否在帮助信息中隐藏
+	Handler     Handler       // 处理函数，为空时回复子命令的帮助信息
+	Subcommands []*Command    // 子命令
+
+	parent *Command
+}
+
+// Arg 位置参数
+type Arg struct {
+	Name        string  // 参数名称
+	Type        ArgType // 参数类型，默认为字符串
+	Description string  // 说明
+	Required    bool    // 是否必填
+	Default     string  // 没有填写时的默认值，按照 Type 解析
+	Rest        bool    // 是否接收剩余的全部内容，只能用于最后一个参数
+}
+
+// Flag 选项
+type Flag struct {
+	Name        string  // 选项名称，使用 --name 指定
+	Short       string  // 短名称，使用 -n 指定
+	Type        ArgType // 选项类型，Bool 类型的选项不需要值
+	Description string  // 说明
+	Default     string  // 没有指定时的默认值，按照 Type 解析
+}
+
+// Permission 使用命令需要的权限，满足其中任意一项即可
+//
+// 只有频道内的消息携带成员的身份组，私信，单聊与群聊中需要权限的命令不能使用
+type Permission struct {
+	Roles      []string // 允许使用的身份组 ID
+	GuildOwner bool     // 是否允许频道主使用
+}
+
+// Path 返回包含父命令的完整命令，如 "role add"
+func (c *Command) Path() string {
+	if c.parent == nil {
+		return c.Name
+	}
+	return c.parent.Path() + " " + c.Name
+}
+
+// Usage 返回命令的用法，如 "role add <user> [reason...] [--days int]"
+func (c *Command) Usage() string {
+	parts := []string{c.Path()}
+	if len(c.Subcommands) > 0 && c.Handler == nil {
+		parts = append(parts, "<subcommand>")
+	}
+	for _, arg := range c.Args {
+		name := arg.Name
+		if arg.Rest {
+			name += "..."
+		}
+		if arg.Required {
+			parts = append(parts, "<"+name+">")
+		} else {
+			parts = append(parts, "["+name+"]")
+		}
+	}
+	for _, flag := range c.Flags {
+		if flag.Type == Bool {
+			parts = append(parts, "[--"+flag.Name+"]")
+		} else {
+			parts = append(parts, "[--"+flag.Name+" "+flag.Type.String()+"]")
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// names 返回命令的名称与别名
+func (c *Command) names() []string {
+	return append([]string{c.Name}, c.Aliases...)
+}
+
+// subcommand 查找子命令
+func (c *Command) subcommand(name string) *Command {
+	for _, sub := range c.Subcommands {
+		for _, n := range sub.names() {
+			if strings.EqualFold(n, name) {
+				return sub
+			}
+		}
+	}
+	return nil
+}
+
+// flag 根据名称或者短名称查找选项
+func (c *Command) flag(name string, short bool) *Flag {
+	for i := range c.Flags {
+		if (!short && c.Flags[i].Name == name) || (short && c.Flags[i].Short != "" && c.Flags[i].Short == name) {
+			return &c.Flags[i]
+		}
+	}
+	return nil
+}
+
+// allowed 成员是否满足权限要求，包括父命令的权限
+func (c *Command) allowed(member *dto.Member) bool {
+	for cmd := c; cmd != nil; cmd = cmd.parent {
+		if !cmd.Permission.allowed(member) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *Permission) allowed(member *dto.Member) bool {
+	if p == nil || (len(p.Roles) == 0 && !p.GuildOwner) {
+		return true
+	}
+	if member == nil {
+		return false
+	}
+	for _, role := range member.Roles {
+		if p.GuildOwner && role == RoleGuildOwner {
+			return true
+		}
+		for _, allowed := range p.Roles {
+			if role == allowed {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package command
+
+import (
+	"context"
+	"time"
+
+	"github.com/tencent-connect/botgo/dto"
+	"github.com/tencent-connect/botgo/reply"
+)
+
+// Scene 收到命令的场景
+type Scene = reply.Scene
+
+// 支持的场景
+const (
+	SceneGuild  = reply.SceneGuild  // 频道内 at 机器人的消息
+	SceneDirect = reply.SceneDirect // 频道私信
+	SceneC2C    = reply.SceneC2C    // 单聊
+	SceneGroup  = reply.SceneGroup  // 群聊 at 机器人的消息
+)
+
+// Context 命令的上下文，包括收到的消息，匹配到的命令与解析后的参数
+type Context struct {
+	Scene   Scene          // 收到命令的场景
+	Payload *dto.WSPayload // 收到的事件
+	Message *dto.Message   // 收到的消息
+	Command *Command       // 匹配到的命令，没有匹配到时为空
+
+	values  map[string]interface{}
+	router  *Router
+	replier *reply.Replier // OpenAPIReplier 使用的被动回复工具，同一条消息的多次回复共用
+}
+
+// UserID 发送命令的用户，频道与私信中为用户 id，单聊中为用户 openid，群聊中为成员 openid
+func (c *Context) UserID() string {
+	author := c.Message.Author
+	if author == nil {
+		return ""
+	}
+	switch {
+	case c.Scene == SceneC2C && author.OpenID != "":
+		return author.OpenID
+	case c.Scene == SceneGroup && author.MemberOpenID != "":
+		return author.MemberOpenID
+	default:
+		return author.ID
+	}
+}
+
+// member 返回用于校验权限的频道成员，只有频道内的消息携带有效的身份组
+func (c *Context) member() *dto.Member {
+	if c.Scene != SceneGuild {
+		return nil
+	}
+	return c.Message.Member
+}
+
+// Value 返回参数或者选项的值，没有填写且没有默认值时返回 false
+func (c *Context) Value(name string) (interface{}, bool) {
+	v, ok := c.values[name]
+	return v, ok
+}
+
+// String 返回字符串或者用户类型的参数
+func (c *Context) String(name string) string {
+	v, _ := c.values[name].(string)
+	return v
+}
+
+// Int 返回整数类型的参数
+func (c *Context) Int(name string) int64 {
+	v, _ := c.values[name].(int64)
+	return v
+}
+
+// Float 返回浮点数类型的参数
+func (c *Context) Float(name string) float64 {
+	v, _ := c.values[name].(float64)
+	return v
+}
+
+// Bool 返回布尔类型的参数或者选项
+func (c *Context) Bool(name string) bool {
+	v, _ := c.values[name].(bool)
+	return v
+}
+
+// Duration 返回时长类型的参数
+func (c *Context) Duration(name string) time.Duration {
+	v, _ := c.values[name].(time.Duration)
+	return v
+}
+
+// Reply 回复文本消息
+func (c *Context) Reply(ctx context.Context, content string) error {
+	return c.ReplyMessage(ctx, &dto.MessageToCreate{Content: content})
+}
+
+// ReplyMessage 回复消息，没有指定 MsgID 与 EventID 时，作为收到的消息的被动回复
+func (c *Context) ReplyMessage(ctx context.Context, msg *dto.MessageToCreate) error {
+	if c.router.replier == nil {
+		return ErrNoReplier
+	}
+	if msg.MsgID == "" && msg.EventID == "" {
+		// 复制后再设置，调用方复用同一个消息回复不同的事件时，不会带上之前的 MsgID
+		reply := *msg
+		reply.MsgID = c.Message.ID
+		msg = &reply
+	}
+	return c.router.replier(ctx, c, msg)
+}
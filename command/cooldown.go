@@ -0,0 +1,37 @@
+package command
+
+import (
+	"sync"
+	"time"
+)
+
+// cooldownPruneSize 记录数量超过这个值时清理已经过期的记录
+const cooldownPruneSize = 1024
+
+// cooldowns 记录用户使用命令的冷却时间
+type cooldowns struct {
+	lock  sync.Mutex
+	until map[string]time.Time
+}
+
+func newCooldowns() *cooldowns {
+	return &cooldowns{until: map[string]time.Time{}}
+}
+
+// take 检查冷却时间，没有在冷却中时记录本次使用并返回 0，否则返回剩余的冷却时间
+func (c *cooldowns) take(key string, cooldown time.Duration, now time.Time) time.Duration {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if until, ok := c.until[key]; ok && until.After(now) {
+		return until.Sub(now)
+	}
+	if len(c.until) >= cooldownPruneSize {
+		for k, until := range c.until {
+			if !until.After(now) {
+				delete(c.until, k)
+			}
+		}
+	}
+	c.until[key] = now.Add(cooldown)
+	return 0
+}
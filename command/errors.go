@@ -0,0 +1,51 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrUnknownCommand 没有找到命令
+	ErrUnknownCommand = errors.New("unknown command")
+	// ErrPermissionDenied 没有使用命令的权限
+	ErrPermissionDenied = errors.New("permission denied")
+	// ErrMissingArgument 缺少必填的参数
+	ErrMissingArgument = errors.New("missing argument")
+	// ErrInvalidArgument 参数或者选项的值不符合类型
+	ErrInvalidArgument = errors.New("invalid argument")
+	// ErrUnknownFlag 命令没有定义这个选项
+	ErrUnknownFlag = errors.New("unknown flag")
+	// ErrTooManyArguments 参数的数量超过了命令定义的参数
+	ErrTooManyArguments = errors.New("too many arguments")
+	// ErrNoReplier 没有设置 Replier，不能回复消息
+	ErrNoReplier = errors.New("no replier, use WithReplier to set one")
+)
+
+// UsageError 参数解析失败的错误，可以通过 errors.Is 判断具体的原因
+type UsageError struct {
+	Command *Command
+	Err     error
+}
+
+// Error 实现 error 接口
+func (e *UsageError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Command.Path(), e.Err)
+}
+
+// Unwrap 返回具体的原因
+func (e *UsageError) Unwrap() error {
+	return e.Err
+}
+
+// CooldownError 命令还在冷却中
+type CooldownError struct {
+	Command   *Command
+	Remaining time.Duration // 距离可以再次使用的时长
+}
+
+// Error 实现 error 接口
+func (e *CooldownError) Error() string {
+	return fmt.Sprintf("%s: cooling down, retry after %s", e.Command.Path(), e.Remaining)
+}
@@ -0,0 +1,110 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Help 返回发送命令的用户可以使用的命令列表
+func (r *Router) Help(c *Context) string {
+	var b strings.Builder
+	b.WriteString("可用的命令：")
+	for _, cmd := range r.commands {
+		if cmd.Hidden || !cmd.allowed(c.member()) {
+			continue
+		}
+		b.WriteString("\n" + r.usage(cmd))
+		if cmd.Description != "" {
+			b.WriteString("  " + cmd.Description)
+		}
+	}
+	if r.helpName != "" {
+		fmt.Fprintf(&b, "\n使用 %s <命令> 查看详细说明", r.prefix()+r.helpName)
+	}
+	return b.String()
+}
+
+// CommandHelp 返回命令的详细说明，包括参数，选项，子命令与别名
+func (r *Router) CommandHelp(cmd *Command) string {
+	var b strings.Builder
+	b.WriteString(r.usage(cmd))
+	if cmd.Description != "" {
+		b.WriteString("\n" + cmd.Description)
+	}
+	if len(cmd.Aliases) > 0 {
+		b.WriteString("\n别名：" + strings.Join(cmd.Aliases, ", "))
+	}
+	if len(cmd.Args) > 0 {
+		b.WriteString("\n参数：")
+		for _, arg := range cmd.Args {
+			b.WriteString(helpLine(arg.Name, arg.Type, arg.Description, arg.Default))
+		}
+	}
+	if len(cmd.Flags) > 0 {
+		b.WriteString("\n选项：")
+		for _, flag := range cmd.Flags {
+			name := "--" + flag.Name
+			if flag.Short != "" {
+				name += ", -" + flag.Short
+			}
+			b.WriteString(helpLine(name, flag.Type, flag.Description, flag.Default))
+		}
+	}
+	if len(cmd.Subcommands) > 0 {
+		b.WriteString("\n子命令：")
+		for _, sub := range cmd.Subcommands {
+			if !sub.Hidden {
+				b.WriteString("\n  " + sub.Name + "  " + sub.Description)
+			}
+		}
+	}
+	return b.String()
+}
+
+// help 内置的帮助命令
+func (r *Router) help(ctx context.Context, c *Context) error {
+	path := tokenize(c.String("command"))
+	if len(path) == 0 {
+		return c.Reply(ctx, r.Help(c))
+	}
+	name, ok := r.trimPrefix(path[0])
+	if !ok {
+		name = path[0]
+	}
+	cmd := r.lookup(name)
+	for _, sub := range path[1:] {
+		if cmd == nil {
+			break
+		}
+		cmd = cmd.subcommand(sub)
+	}
+	if cmd == nil || cmd.Hidden {
+		return c.Reply(ctx, fmt.Sprintf("没有找到命令 %s", strings.Join(path, " ")))
+	}
+	return c.Reply(ctx, r.CommandHelp(cmd))
+}
+
+// usage 返回带有前缀的用法
+func (r *Router) usage(cmd *Command) string {
+	return r.prefix() + cmd.Usage()
+}
+
+// prefix 帮助信息中使用的前缀
+func (r *Router) prefix() string {
+	if len(r.prefixes) == 0 {
+		return ""
+	}
+	return r.prefixes[0]
+}
+
+func helpLine(name string, t ArgType, description, defaultValue string) string {
+	line := fmt.Sprintf("\n  %s (%s)", name, t)
+	if description != "" {
+		line += " " + description
+	}
+	if defaultValue != "" {
+		line += "，默认 " + defaultValue
+	}
+	return line
+}
@@ -0,0 +1,40 @@
+package command
+
+// Option 命令路由的配置
+type Option func(r *Router)
+
+// WithPrefix 设置命令的前缀，如 "/"，设置后只处理带有前缀的消息，可以设置多个
+func WithPrefix(prefixes ...string) Option {
+	return func(r *Router) {
+		r.prefixes = prefixes
+	}
+}
+
+// WithReplier 设置回复消息的方式，一般使用 OpenAPIReplier，不设置时不会回复帮助信息与错误提示
+func WithReplier(replier Replier) Option {
+	return func(r *Router) {
+		r.replier = replier
+	}
+}
+
+// WithErrorHandler 设置错误处理，默认回复参数错误，没有权限与冷却中的提示
+func WithErrorHandler(handler ErrorHandler) Option {
+	return func(r *Router) {
+		r.onError = handler
+	}
+}
+
+// WithNotFound 设置没有匹配到命令时的处理，默认忽略
+func WithNotFound(handler Handler) Option {
+	return func(r *Router) {
+		r.notFound = handler
+	}
+}
+
+// WithHelp 设置帮助命令的名称与别名，名称为空时不注册帮助命令
+func WithHelp(name string, aliases ...string) Option {
+	return func(r *Router) {
+		r.helpName = name
+		r.helpAliases = aliases
+	}
+}
@@ -0,0 +1,166 @@
+package command
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tencent-connect/botgo/dto/message"
+)
+
+// 用于分割参数的空白字符，\u00A0 是 &nbsp; 的 unicode 编码，与 message.ETLInput 保持一致
+const spaceCharSet = " \u00A0\t\n"
+
+// quotes 支持的引号，引号内的空格不会分割参数
+var quotes = map[rune]rune{'"': '"', '\'': '\'', '“': '”'}
+
+// tokenize 按照空白字符分割输入，引号内的内容作为一个参数
+func tokenize(input string) []string {
+	var tokens []string
+	var current strings.Builder
+	var closing rune
+	inToken := false
+	for _, r := range input {
+		switch {
+		case closing != 0 && r == closing:
+			closing = 0
+		case closing != 0:
+			current.WriteRune(r)
+		case quotes[r] != 0:
+			closing, inToken = quotes[r], true
+		case strings.ContainsRune(spaceCharSet, r):
+			if inToken {
+				tokens = append(tokens, current.String())
+				current.Reset()
+				inToken = false
+			}
+		default:
+			current.WriteRune(r)
+			inToken = true
+		}
+	}
+	if inToken {
+		tokens = append(tokens, current.String())
+	}
+	return tokens
+}
+
+// commandInput 去掉开头 at 机器人的内容，文本反转义，at 用户等内嵌格式保持原样，用于分割参数
+func commandInput(content string) string {
+	var b strings.Builder
+	for _, segment := range message.Parse(content).TrimMentions() {
+		if segment.Type == message.SegmentText {
+			b.WriteString(segment.Text)
+		} else {
+			b.WriteString(segment.String())
+		}
+	}
+	return b.String()
+}
+
+// parseValues 解析命令的参数与选项
+func parseValues(cmd *Command, tokens []string) (map[string]interface{}, error) {
+	values := make(map[string]interface{}, len(cmd.Args)+len(cmd.Flags))
+	var positional []string
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		if token == "--" {
+			positional = append(positional, tokens[i+1:]...)
+			break
+		}
+		if !isFlag(token) {
+			positional = append(positional, token)
+			continue
+		}
+		consumed, err := parseFlag(cmd, token, tokens[i+1:], values)
+		if err != nil {
+			return nil, err
+		}
+		i += consumed
+	}
+	if err := parseArgs(cmd, positional, values); err != nil {
+		return nil, err
+	}
+	return values, defaultFlags(cmd, values)
+}
+
+// isFlag 是否是选项，负数不作为选项处理
+func isFlag(token string) bool {
+	if len(token) < 2 || token[0] != '-' {
+		return false
+	}
+	_, err := strconv.ParseFloat(token, 64)
+	return err != nil
+}
+
+// parseFlag 解析一个选项，返回额外使用的参数数量
+func parseFlag(cmd *Command, token string, rest []string, values map[string]interface{}) (int, error) {
+	short := !strings.HasPrefix(token, "--")
+	name := strings.TrimLeft(token, "-")
+	value, hasValue := "", false
+	if idx := strings.Index(name, "="); idx >= 0 {
+		name, value, hasValue = name[:idx], name[idx+1:], true
+	}
+	flag := cmd.flag(name, short)
+	if flag == nil {
+		return 0, fmt.Errorf("%w %s", ErrUnknownFlag, token)
+	}
+	consumed := 0
+	if !hasValue {
+		if flag.Type == Bool {
+			value = "true"
+		} else if len(rest) > 0 {
+			value, consumed = rest[0], 1
+		} else {
+			return 0, fmt.Errorf("%w --%s", ErrMissingArgument, flag.Name)
+		}
+	}
+	parsed, err := flag.Type.parse(value)
+	if err != nil {
+		return 0, fmt.Errorf("%w --%s: %s", ErrInvalidArgument, flag.Name, value)
+	}
+	values[flag.Name] = parsed
+	return consumed, nil
+}
+
+// parseArgs 按照顺序解析位置参数
+func parseArgs(cmd *Command, positional []string, values map[string]interface{}) error {
+	for i, arg := range cmd.Args {
+		value := arg.Default
+		switch {
+		case arg.Rest && i < len(positional):
+			value = strings.Join(positional[i:], " ")
+			positional = positional[:i+1]
+		case i < len(positional):
+			value = positional[i]
+		case arg.Required:
+			return fmt.Errorf("%w <%s>", ErrMissingArgument, arg.Name)
+		case value == "":
+			continue
+		}
+		parsed, err := arg.Type.parse(value)
+		if err != nil {
+			return fmt.Errorf("%w <%s>: %s", ErrInvalidArgument, arg.Name, value)
+		}
+		values[arg.Name] = parsed
+	}
+	if len(positional) > len(cmd.Args) {
+		return fmt.Errorf("%w: %s", ErrTooManyArguments, strings.Join(positional[len(cmd.Args):], " "))
+	}
+	return nil
+}
+
+// defaultFlags 设置没有指定的选项的默认值
+func defaultFlags(cmd *Command, values map[string]interface{}) error {
+	for _, flag := range cmd.Flags {
+		if _, ok := values[flag.Name]; ok || flag.Default == "" {
+			continue
+		}
+		parsed, err := flag.Type.parse(flag.Default)
+		if err != nil {
+			return fmt.Errorf("%w --%s: %s", ErrInvalidArgument, flag.Name, flag.Default)
+		}
+		values[flag.Name] = parsed
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package command
+
+import (
+	"context"
+
+	"github.com/tencent-connect/botgo/dto"
+	"github.com/tencent-connect/botgo/openapi"
+	"github.com/tencent-connect/botgo/reply"
+)
+
+// Replier 回复命令的消息，用于帮助信息，错误提示与 Context.Reply
+type Replier func(ctx context.Context, c *Context, msg *dto.MessageToCreate) error
+
+// OpenAPIReplier 使用 openapi 按照收到命令的场景回复消息
+// 同一条消息的多次回复会自动递增 msg_seq，被动回复的有效期已过时按照 opts 的配置返回错误或者作为主动消息发送
+func OpenAPIReplier(api openapi.OpenAPI, opts ...reply.Option) Replier {
+	return func(ctx context.Context, c *Context, msg *dto.MessageToCreate) error {
+		if c.replier == nil {
+			c.replier = reply.New(api, c.Scene, c.Message, opts...)
+		}
+		_, err := c.replier.Send(ctx, msg)
+		return err
+	}
+}
@@ -0,0 +1,221 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/tencent-connect/botgo/dto"
+	"github.com/tencent-connect/botgo/event"
+)
+
+// ErrorHandler 处理命令执行过程中的错误，返回的错误会交给事件分发器记录
+type ErrorHandler func(ctx context.Context, c *Context, err error) error
+
+// Router 命令路由，根据消息的内容匹配命令，校验权限与冷却时间，解析参数后调用命令的 Handler
+//
+// 通过 Handlers 注册到事件分发器后，可以统一处理频道 at 消息，频道私信，单聊与群聊 at 消息
+type Router struct {
+	prefixes    []string
+	commands    []*Command
+	replier     Replier
+	onError     ErrorHandler
+	notFound    Handler
+	helpName    string
+	helpAliases []string
+	cooldowns   *cooldowns
+}
+
+// NewRouter 创建命令路由，默认注册 help 命令
+func NewRouter(opts ...Option) *Router {
+	r := &Router{
+		onError:     defaultErrorHandler,
+		helpName:    "help",
+		helpAliases: []string{"帮助"},
+		cooldowns:   newCooldowns(),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.helpName != "" {
+		r.Register(&Command{
+			Name:        r.helpName,
+			Aliases:     r.helpAliases,
+			Description: "查看命令的帮助信息",
+			Args:        []Arg{{Name: "command", Rest: true}},
+			Handler:     r.help,
+		})
+	}
+	return r
+}
+
+// Register 注册命令，命令的名称与别名重复时 panic
+func (r *Router) Register(commands ...*Command) *Router {
+	for _, cmd := range commands {
+		for _, name := range cmd.names() {
+			if r.lookup(name) != nil {
+				panic(fmt.Sprintf("command: duplicate command %s", name))
+			}
+		}
+		link(cmd, nil)
+		r.commands = append(r.commands, cmd)
+	}
+	return r
+}
+
+// Commands 返回注册的命令
+func (r *Router) Commands() []*Command {
+	return r.commands
+}
+
+// Handlers 返回处理消息的 handler，通过事件分发器的 RegisterHandlers 注册
+func (r *Router) Handlers() []interface{} {
+	return []interface{}{
+		event.ATMessageEventContextHandler(
+			func(ctx context.Context, payload *dto.WSPayload, data *dto.WSATMessageData) error {
+				return r.Handle(ctx, SceneGuild, payload, (*dto.Message)(data))
+			},
+		),
+		event.DirectMessageEventContextHandler(
+			func(ctx context.Context, payload *dto.WSPayload, data *dto.WSDirectMessageData) error {
+				return r.Handle(ctx, SceneDirect, payload, (*dto.Message)(data))
+			},
+		),
+		event.UserQueryEventContextHandler(
+			func(ctx context.Context, payload *dto.WSPayload, data *dto.WSUserQuery) error {
+				return r.Handle(ctx, SceneC2C, payload, (*dto.Message)(data))
+			},
+		),
+		event.GroupAtMessageEventContextHandler(
+			func(ctx context.Context, payload *dto.WSPayload, data *dto.WSGroupAtMessage) error {
+				return r.Handle(ctx, SceneGroup, payload, (*dto.Message)(data))
+			},
+		),
+	}
+}
+
+// Handle 处理一条消息，没有匹配到命令时调用 WithNotFound 设置的 Handler
+func (r *Router) Handle(ctx context.Context, scene Scene, payload *dto.WSPayload, msg *dto.Message) error {
+	c := &Context{Scene: scene, Payload: payload, Message: msg, router: r}
+	tokens := tokenize(commandInput(msg.Content))
+	if len(tokens) == 0 {
+		return r.handleNotFound(ctx, c)
+	}
+	name, ok := r.trimPrefix(tokens[0])
+	if !ok {
+		return nil
+	}
+	cmd := r.lookup(name)
+	if cmd == nil {
+		return r.handleNotFound(ctx, c)
+	}
+	tokens = tokens[1:]
+	for len(tokens) > 0 {
+		sub := cmd.subcommand(tokens[0])
+		if sub == nil {
+			break
+		}
+		cmd, tokens = sub, tokens[1:]
+	}
+	c.Command = cmd
+	if err := r.run(ctx, c, tokens); err != nil {
+		return r.onError(ctx, c, err)
+	}
+	return nil
+}
+
+// run 校验权限，解析参数，校验冷却时间后执行命令
+func (r *Router) run(ctx context.Context, c *Context, tokens []string) error {
+	cmd := c.Command
+	if !cmd.allowed(c.member()) {
+		return ErrPermissionDenied
+	}
+	if cmd.Handler == nil || wantsHelp(cmd, tokens) {
+		return c.Reply(ctx, r.CommandHelp(cmd))
+	}
+	values, err := parseValues(cmd, tokens)
+	if err != nil {
+		return &UsageError{Command: cmd, Err: err}
+	}
+	c.values = values
+	if cmd.Cooldown > 0 {
+		key := c.UserID() + "\x00" + cmd.Path()
+		if remaining := r.cooldowns.take(key, cmd.Cooldown, time.Now()); remaining > 0 {
+			return &CooldownError{Command: cmd, Remaining: remaining}
+		}
+	}
+	return cmd.Handler(ctx, c)
+}
+
+func (r *Router) handleNotFound(ctx context.Context, c *Context) error {
+	if r.notFound == nil {
+		return nil
+	}
+	return r.notFound(ctx, c)
+}
+
+// trimPrefix 去掉命令的前缀，设置了前缀但是没有匹配时返回 false
+func (r *Router) trimPrefix(token string) (string, bool) {
+	if len(r.prefixes) == 0 {
+		return token, true
+	}
+	for _, prefix := range r.prefixes {
+		if strings.HasPrefix(token, prefix) && len(token) > len(prefix) {
+			return token[len(prefix):], true
+		}
+	}
+	return "", false
+}
+
+// lookup 根据名称或者别名查找顶层命令
+func (r *Router) lookup(name string) *Command {
+	for _, cmd := range r.commands {
+		for _, n := range cmd.names() {
+			if strings.EqualFold(n, name) {
+				return cmd
+			}
+		}
+	}
+	return nil
+}
+
+// link 设置子命令的父命令
+func link(cmd, parent *Command) {
+	cmd.parent = parent
+	for _, sub := range cmd.Subcommands {
+		link(sub, cmd)
+	}
+}
+
+// wantsHelp 参数中是否有 --help 或者 -h，命令自己定义了同名的选项时不处理
+func wantsHelp(cmd *Command, tokens []string) bool {
+	for _, token := range tokens {
+		if (token == "--help" && cmd.flag("help", false) == nil) || (token == "-h" && cmd.flag("h", true) == nil) {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultErrorHandler 回复参数错误，没有权限与冷却中的提示，其他错误交给事件分发器
+func defaultErrorHandler(ctx context.Context, c *Context, err error) error {
+	if c.router.replier == nil {
+		return err
+	}
+	var usage *UsageError
+	var cooldown *CooldownError
+	switch {
+	case errors.As(err, &usage):
+		return c.Reply(ctx, fmt.Sprintf("参数错误：%v\n用法：%s", usage.Err, c.router.usage(usage.Command)))
+	case errors.As(err, &cooldown):
+		seconds := int(math.Ceil(cooldown.Remaining.Seconds()))
+		return c.Reply(ctx, fmt.Sprintf("操作太频繁，请 %d 秒后再试", seconds))
+	case errors.Is(err, ErrPermissionDenied):
+		return c.Reply(ctx, "没有权限使用该命令")
+	default:
+		return err
+	}
+}
@@ -0,0 +1,195 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tencent-connect/botgo/dto"
+)
+
+type recorder struct {
+	replies []string
+}
+
+func (r *recorder) reply(_ context.Context, _ *Context, msg *dto.MessageToCreate) error {
+	r.replies = append(r.replies, msg.Content)
+	return nil
+}
+
+func (r *recorder) last() string {
+	if len(r.replies) == 0 {
+		return ""
+	}
+	return r.replies[len(r.replies)-1]
+}
+
+func newTestRouter(rec *recorder, got *Context) *Router {
+	save := func(_ context.Context, c *Context) error {
+		*got = *c
+		return nil
+	}
+	return NewRouter(WithPrefix("/"), WithReplier(rec.reply)).Register(
+		&Command{
+			Name:        "roll",
+			Aliases:     []string{"r", "掷骰子"},
+			Description: "掷骰子",
+			Args:        []Arg{{Name: "sides", Type: Int, Default: "6"}},
+			Flags: []Flag{
+				{Name: "count", Short: "c", Type: Int, Default: "1"},
+				{Name: "secret", Type: Bool},
+			},
+			Cooldown: time.Minute,
+			Handler:  save,
+		},
+		&Command{
+			Name:       "role",
+			Permission: &Permission{Roles: []string{"2"}, GuildOwner: true},
+			Subcommands: []*Command{{
+				Name:        "add",
+				Description: "添加身份组",
+				Args: []Arg{
+					{Name: "user", Type: User, Required: true},
+					{Name: "reason", Rest: true},
+				},
+				Flags:   []Flag{{Name: "expire", Short: "e", Type: Duration}},
+				Handler: save,
+			}},
+		},
+	)
+}
+
+func guildMessage(content string, roles ...string) *dto.Message {
+	return &dto.Message{
+		ID:        "msg",
+		ChannelID: "channel",
+		Content:   content,
+		Author:    &dto.User{ID: "user"},
+		Member:    &dto.Member{Roles: roles},
+	}
+}
+
+func TestRouter_Handle(t *testing.T) {
+	ctx := context.Background()
+	t.Run("alias args and flags", func(t *testing.T) {
+		rec, got := &recorder{}, &Context{}
+		r := newTestRouter(rec, got)
+		err := r.Handle(ctx, SceneGuild, nil, guildMessage("<@!10> /掷骰子 20 -c 3 --secret"))
+		assert.Nil(t, err)
+		assert.Equal(t, "roll", got.Command.Name)
+		assert.Equal(t, int64(20), got.Int("sides"))
+		assert.Equal(t, int64(3), got.Int("count"))
+		assert.True(t, got.Bool("secret"))
+
+		// 冷却中
+		assert.Nil(t, r.Handle(ctx, SceneGuild, nil, guildMessage("/r")))
+		assert.Contains(t, rec.last(), "操作太频繁")
+		// 其他用户不受影响
+		msg := guildMessage("/r --count=2")
+		msg.Author.ID = "other"
+		assert.Nil(t, r.Handle(ctx, SceneGuild, nil, msg))
+		assert.Equal(t, int64(6), got.Int("sides"))
+		assert.Equal(t, int64(2), got.Int("count"))
+	})
+	t.Run("subcommand and permission", func(t *testing.T) {
+		rec, got := &recorder{}, &Context{}
+		r := newTestRouter(rec, got)
+		content := `/role add <@!20> "spam  links" again -e 10m`
+		assert.Nil(t, r.Handle(ctx, SceneGuild, nil, guildMessage(content, "1")))
+		assert.Equal(t, "没有权限使用该命令", rec.last())
+		assert.Nil(t, got.Command)
+
+		assert.Nil(t, r.Handle(ctx, SceneGuild, nil, guildMessage(content, "1", RoleGuildOwner)))
+		assert.Equal(t, "role add", got.Command.Path())
+		assert.Equal(t, "20", got.String("user"))
+		assert.Equal(t, "spam  links again", got.String("reason"))
+		assert.Equal(t, 10*time.Minute, got.Duration("expire"))
+
+		// 群聊中没有身份组，需要权限的命令不能使用
+		group := &dto.Message{Content: content, GroupOpenID: "group", Author: &dto.User{MemberOpenID: "member"}}
+		assert.Nil(t, r.Handle(ctx, SceneGroup, nil, group))
+		assert.Equal(t, "没有权限使用该命令", rec.last())
+	})
+	t.Run("usage errors", func(t *testing.T) {
+		rec, got := &recorder{}, &Context{}
+		r := newTestRouter(rec, got)
+		tests := []struct {
+			content string
+			want    error
+		}{
+			{"/roll abc", ErrInvalidArgument},
+			{"/roll 1 2", ErrTooManyArguments},
+			{"/roll --unknown", ErrUnknownFlag},
+			{"/roll -c", ErrMissingArgument},
+			{"/role add", ErrMissingArgument},
+		}
+		for _, tt := range tests {
+			var usageErr *UsageError
+			r.onError = func(_ context.Context, _ *Context, err error) error {
+				assert.True(t, errors.As(err, &usageErr), tt.content)
+				return err
+			}
+			err := r.Handle(ctx, SceneGuild, nil, guildMessage(tt.content, RoleGuildOwner))
+			assert.True(t, errors.Is(err, tt.want), tt.content)
+		}
+	})
+	t.Run("reply does not modify the message", func(t *testing.T) {
+		var msgIDs []string
+		shared := &dto.MessageToCreate{Content: "pong"}
+		replier := func(_ context.Context, _ *Context, msg *dto.MessageToCreate) error {
+			msgIDs = append(msgIDs, msg.MsgID)
+			return nil
+		}
+		ping := func(ctx context.Context, c *Context) error {
+			return c.ReplyMessage(ctx, shared)
+		}
+		r := NewRouter(WithPrefix("/"), WithReplier(replier)).Register(&Command{Name: "ping", Handler: ping})
+		for _, id := range []string{"m1", "m2"} {
+			msg := guildMessage("/ping")
+			msg.ID = id
+			assert.Nil(t, r.Handle(ctx, SceneGuild, nil, msg))
+		}
+		assert.Equal(t, []string{"m1", "m2"}, msgIDs)
+		assert.Empty(t, shared.MsgID)
+	})
+	t.Run("not a command", func(t *testing.T) {
+		rec, got := &recorder{}, &Context{}
+		r := newTestRouter(rec, got)
+		assert.Nil(t, r.Handle(ctx, SceneGuild, nil, guildMessage("roll")))
+		assert.Nil(t, r.Handle(ctx, SceneGuild, nil, guildMessage("/unknown")))
+		assert.Nil(t, got.Command)
+		assert.Empty(t, rec.replies)
+	})
+}
+
+func TestRouter_Help(t *testing.T) {
+	ctx := context.Background()
+	rec, got := &recorder{}, &Context{}
+	r := newTestRouter(rec, got)
+
+	assert.Nil(t, r.Handle(ctx, SceneC2C, nil, &dto.Message{Content: "/help", Author: &dto.User{OpenID: "user"}}))
+	assert.Contains(t, rec.last(), "/roll [sides] [--count int] [--secret]  掷骰子")
+	assert.NotContains(t, rec.last(), "/role")
+
+	assert.Nil(t, r.Handle(ctx, SceneGuild, nil, guildMessage("/帮助 role add")))
+	assert.Contains(t, rec.last(), "/role add <user> [reason...] [--expire duration]")
+	assert.Contains(t, rec.last(), "--expire, -e (duration)")
+
+	// 没有 Handler 的命令回复子命令的帮助
+	assert.Nil(t, r.Handle(ctx, SceneGuild, nil, guildMessage("/role", "2")))
+	assert.Contains(t, rec.last(), "子命令：\n  add  添加身份组")
+
+	assert.Nil(t, r.Handle(ctx, SceneGuild, nil, guildMessage("/roll -h")))
+	assert.Contains(t, rec.last(), "别名：r, 掷骰子")
+	assert.Nil(t, got.Command)
+
+	assert.Panics(t, func() { r.Register(&Command{Name: "R"}) })
+}
+
+func TestTokenize(t *testing.T) {
+	assert.Equal(t, []string{"a", "b c", "d", "e f"}, tokenize(" a \"b c\" d  “e f”"))
+	assert.Empty(t, tokenize("  "))
+	assert.Equal(t, "/say a&b <@!1>", commandInput("<@!10> \u00A0/say a&amp;b <@!1>"))
+}
@@ -0,0 +1,61 @@
+package command
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// ArgType 参数与选项的类型
+type ArgType int
+
+// 支持的参数类型
+const (
+	String   ArgType = iota // 字符串
+	Int                     // 整数，值为 int64
+	Float                   // 浮点数，值为 float64
+	Bool                    // 布尔值
+	Duration                // 时长，如 10s、5m，值为 time.Duration
+	User                    // 用户，支持 <@!id>，<@id> 或者直接填写 id，值为用户 id
+)
+
+var typeNames = map[ArgType]string{
+	String:   "string",
+	Int:      "int",
+	Float:    "float",
+	Bool:     "bool",
+	Duration: "duration",
+	User:     "user",
+}
+
+// String 类型名称，用于帮助信息
+func (t ArgType) String() string {
+	if name, ok := typeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("ArgType(%d)", int(t))
+}
+
+var userRE = regexp.MustCompile(`^<@!?(\w+)>$`)
+
+// parse 按照类型解析参数的值
+func (t ArgType) parse(value string) (interface{}, error) {
+	switch t {
+	case Int:
+		return strconv.ParseInt(value, 10, 64)
+	case Float:
+		return strconv.ParseFloat(value, 64)
+	case Bool:
+		return strconv.ParseBool(value)
+	case Duration:
+		return time.ParseDuration(value)
+	case User:
+		if m := userRE.FindStringSubmatch(value); m != nil {
+			return m[1], nil
+		}
+		return value, nil
+	default:
+		return value, nil
+	}
+}
@@ -0,0 +1,130 @@
+package builder
+
+import (
+	"fmt"
+
+	"github.com/tencent-connect/botgo/dto"
+)
+
+// 常用的 ark 模版 ID，参考 https://bot.q.qq.com/wiki/develop/api/openapi/message/message_template.html
+const (
+	ArkTemplateLinkList  = 23 // 链接与文本列表模版
+	ArkTemplateThumbnail = 24 // 文本与缩略图模版
+	ArkTemplateBigImage  = 37 // 大图模版
+)
+
+// ArkBuilder ark 的构造器
+type ArkBuilder struct {
+	ark dto.Ark
+}
+
+// Ark 创建 ark
+func Ark(templateID int) *ArkBuilder {
+	return &ArkBuilder{ark: dto.Ark{TemplateID: templateID}}
+}
+
+// KV 添加键值对，值为空时忽略
+func (b *ArkBuilder) KV(key, value string) *ArkBuilder {
+	if value != "" {
+		b.ark.KV = append(b.ark.KV, &dto.ArkKV{Key: key, Value: value})
+	}
+	return b
+}
+
+// Obj 添加对象列表，每个对象由 ArkObj 生成
+func (b *ArkBuilder) Obj(key string, objs ...*dto.ArkObj) *ArkBuilder {
+	b.ark.KV = append(b.ark.KV, &dto.ArkKV{Key: key, Obj: objs})
+	return b
+}
+
+// ArkObj 使用 key，value 交替的参数生成 ark 对象，如 ArkObj("desc", "描述", "link", "https://qq.com")
+// 参数数量为奇数时最后一个 key 的值为空
+func ArkObj(pairs ...string) *dto.ArkObj {
+	obj := &dto.ArkObj{}
+	for i := 0; i < len(pairs); i += 2 {
+		kv := &dto.ArkObjKV{Key: pairs[i]}
+		if i+1 < len(pairs) {
+			kv.Value = pairs[i+1]
+		}
+		obj.ObjKV = append(obj.ObjKV, kv)
+	}
+	return obj
+}
+
+// Build 校验并生成 ark
+func (b *ArkBuilder) Build() (*dto.Ark, error) {
+	if b.ark.TemplateID == 0 {
+		return nil, fmt.Errorf("%w: template id is required", ErrInvalidArk)
+	}
+	keys := map[string]bool{}
+	for _, kv := range b.ark.KV {
+		if kv.Key == "" || keys[kv.Key] {
+			return nil, fmt.Errorf("%w: invalid key %q", ErrInvalidArk, kv.Key)
+		}
+		keys[kv.Key] = true
+	}
+	ark := b.ark
+	ark.KV = append([]*dto.ArkKV(nil), ark.KV...)
+	return &ark, nil
+}
+
+// ArkLink 链接列表模版中的一项，Link 为空时只展示文本
+type ArkLink struct {
+	Desc string
+	Link string
+}
+
+// LinkListArk 23 号模版，链接与文本列表
+func LinkListArk(desc, prompt string, links ...ArkLink) *ArkBuilder {
+	objs := make([]*dto.ArkObj, 0, len(links))
+	for _, link := range links {
+		if link.Link == "" {
+			objs = append(objs, ArkObj("desc", link.Desc))
+		} else {
+			objs = append(objs, ArkObj("desc", link.Desc, "link", link.Link))
+		}
+	}
+	return Ark(ArkTemplateLinkList).KV("#DESC#", desc).KV("#PROMPT#", prompt).Obj("#LIST#", objs...)
+}
+
+// ThumbnailArkData 24 号模版的内容
+type ThumbnailArkData struct {
+	Desc     string // 描述
+	Prompt   string // 提示消息
+	Title    string // 标题
+	MetaDesc string // 详情描述
+	Image    string // 缩略图链接
+	Link     string // 跳转链接
+	Subtitle string // 来源
+}
+
+// ThumbnailArk 24 号模版，文本与缩略图
+func ThumbnailArk(data ThumbnailArkData) *ArkBuilder {
+	return Ark(ArkTemplateThumbnail).
+		KV("#DESC#", data.Desc).
+		KV("#PROMPT#", data.Prompt).
+		KV("#TITLE#", data.Title).
+		KV("#METADESC#", data.MetaDesc).
+		KV("#IMG#", data.Image).
+		KV("#LINK#", data.Link).
+		KV("#SUBTITLE#", data.Subtitle)
+}
+
+// BigImageArkData 37 号模版的内容
+type BigImageArkData struct {
+	Prompt   string // 提示消息
+	Title    string // 标题
+	Subtitle string // 子标题
+	Cover    string // 大图链接，尺寸为 975*540
+	URL      string // 跳转链接
+}
+
+// BigImageArk 37 号模版，大图
+func BigImageArk(data BigImageArkData) *ArkBuilder {
+	return Ark(ArkTemplateBigImage).
+		KV("#PROMPT#", data.Prompt).
+		KV("#METATITLE#", data.Title).
+		KV("#METASUBTITLE#", data.Subtitle).
+		KV("#METACOVER#", data.Cover).
+		KV("#METAURL#", data.URL)
+}
@@ -0,0 +1,113 @@
+package builder
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tencent-connect/botgo/dto"
+)
+
+func TestMessageBuilder(t *testing.T) {
+	t.Run("markdown with keyboard", func(t *testing.T) {
+		msg, err := Message().
+			Markdown(MarkdownTemplate(1).Param("title", "hello")).
+			Keyboard(NewKeyboard().
+				Row(Button("1", "确认").Callback("ok"), Button("2", "取消").Callback("cancel").OnlyRoles("2")).
+				Row(Button("3", "文档").URL("https://bot.q.qq.com"))).
+			ReplyTo("msg").Seq(2).
+			Build()
+		assert.Nil(t, err)
+		assert.Equal(t, dto.MsgTypeMarkdown, msg.Type)
+		assert.Equal(t, "msg", msg.MsgID)
+		assert.Equal(t, 2, msg.MessageSeq)
+		data, _ := json.Marshal(msg.Keyboard)
+		assert.Contains(t, string(data), `"permission":{"type":3,"specify_role_ids":["2"]}`)
+		assert.Contains(t, string(data), `"permission":{"type":2}`)
+	})
+	t.Run("msg type", func(t *testing.T) {
+		tests := []struct {
+			builder *MessageBuilder
+			want    int
+		}{
+			{Text("hello"), dto.MsgTypeText},
+			{Message().Ark(LinkListArk("desc", "prompt", ArkLink{Desc: "a", Link: "https://qq.com"})), dto.MsgTypeArk},
+			{Message().Embed(Embed("title").Field("line")), dto.MsgTypeEmbed},
+			{Message().Media(&dto.Media{FileType: 1, URL: "https://qq.com/a.png"}), dto.MsgTypeMedia},
+		}
+		for _, tt := range tests {
+			msg, err := tt.builder.Build()
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, msg.Type)
+		}
+	})
+	t.Run("invalid", func(t *testing.T) {
+		tests := []struct {
+			builder *MessageBuilder
+			want    error
+		}{
+			{Message(), ErrEmptyContent},
+			{Message().Markdown(Markdown("**a**")).Ark(Ark(ArkTemplateLinkList)), ErrConflictContent},
+			{Text("a").Keyboard(NewKeyboard().Row(Button("1", "a"))), ErrKeyboardWithoutMarkdown},
+			{
+				Message().Markdown(Markdown("a")).Keyboard(NewKeyboard().Row(Button("1", "a"))).KeyboardTemplate("1"),
+				ErrConflictKeyboard,
+			},
+			{Message().Markdown(Markdown("a").Param("k", "v")), ErrInvalidMarkdown},
+			{Message().Ark(Ark(0)), ErrInvalidArk},
+			{Message().Embed(Embed("")), ErrInvalidEmbed},
+		}
+		for _, tt := range tests {
+			_, err := tt.builder.Build()
+			assert.True(t, errors.Is(err, tt.want), "%v", err)
+		}
+	})
+}
+
+func TestKeyboardBuilder(t *testing.T) {
+	row := func(n int) []*ButtonBuilder {
+		buttons := make([]*ButtonBuilder, 0, n)
+		for i := 0; i < n; i++ {
+			buttons = append(buttons, Button("", "按钮"))
+		}
+		return buttons
+	}
+	tests := []struct {
+		name     string
+		keyboard *KeyboardBuilder
+		want     error
+	}{
+		{"no rows", NewKeyboard(), ErrEmptyRow},
+		{"empty row", NewKeyboard().Row(), ErrEmptyRow},
+		{"too many rows", NewKeyboard().Row(row(1)...).Row(row(1)...).Row(row(1)...).Row(row(1)...).Row(row(1)...).
+			Row(row(1)...), ErrTooManyRows},
+		{"too many buttons", NewKeyboard().Row(row(MaxButtonsPerRow + 1)...), ErrTooManyButtons},
+		{"empty label", NewKeyboard().Row(Button("1", "")), ErrInvalidLabel},
+		{"long label", NewKeyboard().Row(Button("1", strings.Repeat("长", MaxLabelLength+1))), ErrInvalidLabel},
+		{"duplicate id", NewKeyboard().Row(Button("1", "a"), Button("1", "b")), ErrDuplicateButtonID},
+		{"url without link", NewKeyboard().Row(Button("1", "a").URL("")), ErrInvalidAction},
+		{"ok", NewKeyboard().Row(row(MaxButtonsPerRow)...), nil},
+	}
+	for _, tt := range tests {
+		_, err := tt.keyboard.Build()
+		assert.True(t, errors.Is(err, tt.want), "%s: %v", tt.name, err)
+	}
+}
+
+func TestArkTemplates(t *testing.T) {
+	ark, err := ThumbnailArk(ThumbnailArkData{Desc: "desc", Prompt: "prompt", Title: "title", Image: "img"}).Build()
+	assert.Nil(t, err)
+	assert.Equal(t, ArkTemplateThumbnail, ark.TemplateID)
+	assert.Len(t, ark.KV, 4)
+
+	ark, err = BigImageArk(BigImageArkData{Prompt: "prompt", Cover: "cover", URL: "url"}).Build()
+	assert.Nil(t, err)
+	assert.Equal(t, "#METACOVER#", ark.KV[1].Key)
+
+	ark, err = LinkListArk("desc", "prompt", ArkLink{Desc: "text"}, ArkLink{Desc: "link", Link: "url"}).Build()
+	assert.Nil(t, err)
+	assert.Len(t, ark.KV[2].Obj[0].ObjKV, 1)
+	assert.Equal(t, "url", ark.KV[2].Obj[1].ObjKV[1].Value)
+}
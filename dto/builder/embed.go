@@ -0,0 +1,54 @@
+package builder
+
+import (
+	"fmt"
+
+	"github.com/tencent-connect/botgo/dto"
+)
+
+// EmbedBuilder embed 的构造器
+type EmbedBuilder struct {
+	embed dto.Embed
+}
+
+// Embed 创建 embed
+func Embed(title string) *EmbedBuilder {
+	return &EmbedBuilder{embed: dto.Embed{Title: title}}
+}
+
+// Description 设置描述
+func (b *EmbedBuilder) Description(description string) *EmbedBuilder {
+	b.embed.Description = description
+	return b
+}
+
+// Prompt 设置消息弹窗与消息列表的摘要，默认使用标题
+func (b *EmbedBuilder) Prompt(prompt string) *EmbedBuilder {
+	b.embed.Prompt = prompt
+	return b
+}
+
+// Thumbnail 设置缩略图
+func (b *EmbedBuilder) Thumbnail(url string) *EmbedBuilder {
+	b.embed.Thumbnail.URL = url
+	return b
+}
+
+// Field 添加一行内容
+func (b *EmbedBuilder) Field(name string) *EmbedBuilder {
+	b.embed.Fields = append(b.embed.Fields, &dto.EmbedField{Name: name})
+	return b
+}
+
+// Build 校验并生成 embed
+func (b *EmbedBuilder) Build() (*dto.Embed, error) {
+	embed := b.embed
+	if embed.Title == "" && embed.Description == "" {
+		return nil, fmt.Errorf("%w: title or description is required", ErrInvalidEmbed)
+	}
+	if embed.Prompt == "" {
+		embed.Prompt = embed.Title
+	}
+	embed.Fields = append([]*dto.EmbedField(nil), embed.Fields...)
+	return &embed, nil
+}
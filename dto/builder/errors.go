@@ -0,0 +1,32 @@
+package builder
+
+import "errors"
+
+var (
+	// ErrEmptyContent 消息没有任何内容
+	ErrEmptyContent = errors.New("builder: empty content")
+	// ErrConflictContent 消息同时设置了 markdown，ark，embed，富媒体中的多种
+	ErrConflictContent = errors.New("builder: only one of markdown, ark, embed and media is allowed")
+	// ErrKeyboardWithoutMarkdown 消息按钮需要与 markdown 一起发送
+	ErrKeyboardWithoutMarkdown = errors.New("builder: keyboard requires markdown")
+	// ErrConflictKeyboard 消息同时设置了自定义消息按钮与消息按钮模版
+	ErrConflictKeyboard = errors.New("builder: only one of keyboard and keyboard template is allowed")
+	// ErrTooManyRows 按钮的行数超过上限
+	ErrTooManyRows = errors.New("builder: too many keyboard rows")
+	// ErrTooManyButtons 一行的按钮数量超过上限
+	ErrTooManyButtons = errors.New("builder: too many buttons in a row")
+	// ErrEmptyRow 一行没有按钮
+	ErrEmptyRow = errors.New("builder: empty keyboard row")
+	// ErrInvalidLabel 按钮的文字为空或者超过长度上限
+	ErrInvalidLabel = errors.New("builder: invalid button label")
+	// ErrDuplicateButtonID 按钮 ID 重复
+	ErrDuplicateButtonID = errors.New("builder: duplicate button id")
+	// ErrInvalidAction 按钮的操作不完整，如跳转按钮没有链接
+	ErrInvalidAction = errors.New("builder: invalid button action")
+	// ErrInvalidMarkdown markdown 同时设置了原生内容与模版，或者模版参数不合法
+	ErrInvalidMarkdown = errors.New("builder: invalid markdown")
+	// ErrInvalidArk ark 没有模版 ID，或者键值对不合法
+	ErrInvalidArk = errors.New("builder: invalid ark")
+	// ErrInvalidEmbed embed 没有标题与描述
+	ErrInvalidEmbed = errors.New("builder: invalid embed")
+)
@@ -0,0 +1,172 @@
+package builder
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/tencent-connect/botgo/dto/keyboard"
+)
+
+// 消息按钮的限制，与开放平台的校验保持一致
+var (
+	// MaxKeyboardRows 最多的行数
+	MaxKeyboardRows = 5
+	// MaxButtonsPerRow 每行最多的按钮数量
+	MaxButtonsPerRow = 5
+	// MaxLabelLength 按钮文字的最大长度，按照字符计算
+	MaxLabelLength = 20
+)
+
+// KeyboardBuilder 自定义消息按钮的构造器
+type KeyboardBuilder struct {
+	rows [][]*ButtonBuilder
+}
+
+// NewKeyboard 创建自定义消息按钮
+func NewKeyboard() *KeyboardBuilder {
+	return &KeyboardBuilder{}
+}
+
+// Row 添加一行按钮
+func (b *KeyboardBuilder) Row(buttons ...*ButtonBuilder) *KeyboardBuilder {
+	b.rows = append(b.rows, buttons)
+	return b
+}
+
+// Build 校验并生成消息按钮
+func (b *KeyboardBuilder) Build() (*keyboard.MessageKeyboard, error) {
+	if len(b.rows) == 0 {
+		return nil, fmt.Errorf("%w: keyboard has no rows", ErrEmptyRow)
+	}
+	if len(b.rows) > MaxKeyboardRows {
+		return nil, fmt.Errorf("%w: %d rows, max %d", ErrTooManyRows, len(b.rows), MaxKeyboardRows)
+	}
+	ids := map[string]bool{}
+	content := &keyboard.CustomKeyboard{}
+	for i, buttons := range b.rows {
+		if len(buttons) == 0 {
+			return nil, fmt.Errorf("%w: row %d", ErrEmptyRow, i)
+		}
+		if len(buttons) > MaxButtonsPerRow {
+			return nil, fmt.Errorf("%w: row %d has %d buttons, max %d",
+				ErrTooManyButtons, i, len(buttons), MaxButtonsPerRow)
+		}
+		row := &keyboard.Row{}
+		for _, button := range buttons {
+			built, err := button.Build()
+			if err != nil {
+				return nil, err
+			}
+			if built.ID != "" && ids[built.ID] {
+				return nil, fmt.Errorf("%w: %s", ErrDuplicateButtonID, built.ID)
+			}
+			ids[built.ID] = true
+			row.Buttons = append(row.Buttons, built)
+		}
+		content.Rows = append(content.Rows, row)
+	}
+	return &keyboard.MessageKeyboard{Content: content}, nil
+}
+
+// KeyboardTemplate 使用在开放平台申请的按钮模版
+func KeyboardTemplate(id string) *keyboard.MessageKeyboard {
+	return &keyboard.MessageKeyboard{ID: id}
+}
+
+// ButtonBuilder 按钮的构造器，默认为所有人可以点击的回调按钮
+type ButtonBuilder struct {
+	button keyboard.Button
+}
+
+// Button 创建按钮
+func Button(id, label string) *ButtonBuilder {
+	return &ButtonBuilder{button: keyboard.Button{
+		ID:         id,
+		RenderData: &keyboard.RenderData{Label: label, VisitedLabel: label},
+		Action: &keyboard.Action{
+			Type:       keyboard.ActionTypeCallback,
+			Permission: &keyboard.Permission{Type: keyboard.PermissionTypAll},
+		},
+	}}
+}
+
+// URL 点击后跳转链接
+func (b *ButtonBuilder) URL(url string) *ButtonBuilder {
+	b.button.Action.Type, b.button.Action.Data = keyboard.ActionTypeURL, url
+	return b
+}
+
+// Callback 点击后触发互动事件，data 会在事件中回传
+func (b *ButtonBuilder) Callback(data string) *ButtonBuilder {
+	b.button.Action.Type, b.button.Action.Data = keyboard.ActionTypeCallback, data
+	return b
+}
+
+// AtBot 点击后在输入框中 at 机器人并填入 data，showChannelList 为 true 时弹出子频道选择器
+func (b *ButtonBuilder) AtBot(data string, showChannelList bool) *ButtonBuilder {
+	b.button.Action.Type, b.button.Action.Data = keyboard.ActionTypeAtBot, data
+	b.button.Action.AtBotShowChannelList = showChannelList
+	return b
+}
+
+// VisitedLabel 设置点击后按钮上的文字，默认与按钮文字相同
+func (b *ButtonBuilder) VisitedLabel(label string) *ButtonBuilder {
+	b.button.RenderData.VisitedLabel = label
+	return b
+}
+
+// Style 设置按钮样式，0：灰色线框，1：蓝色线框
+func (b *ButtonBuilder) Style(style int) *ButtonBuilder {
+	b.button.RenderData.Style = style
+	return b
+}
+
+// ClickLimit 设置可以点击的次数，默认不限制
+func (b *ButtonBuilder) ClickLimit(limit uint32) *ButtonBuilder {
+	b.button.Action.ClickLimit = limit
+	return b
+}
+
+// OnlyUsers 仅指定的用户可以点击
+func (b *ButtonBuilder) OnlyUsers(userIDs ...string) *ButtonBuilder {
+	b.button.Action.Permission = &keyboard.Permission{
+		Type:           keyboard.PermissionTypeSpecifyUserIDs,
+		SpecifyUserIDs: userIDs,
+	}
+	return b
+}
+
+// OnlyRoles 仅指定身份组的成员可以点击
+func (b *ButtonBuilder) OnlyRoles(roleIDs ...string) *ButtonBuilder {
+	b.button.Action.Permission = &keyboard.Permission{
+		Type:           keyboard.PermissionTypSpecifyRoleIDs,
+		SpecifyRoleIDs: roleIDs,
+	}
+	return b
+}
+
+// OnlyManager 仅频道管理者可以点击
+func (b *ButtonBuilder) OnlyManager() *ButtonBuilder {
+	b.button.Action.Permission = &keyboard.Permission{Type: keyboard.PermissionTypManager}
+	return b
+}
+
+// Build 校验并生成按钮
+func (b *ButtonBuilder) Build() (*keyboard.Button, error) {
+	button := b.button
+	renderData, action := *button.RenderData, *button.Action
+	if action.Permission != nil {
+		permission := *action.Permission
+		action.Permission = &permission
+	}
+	button.RenderData, button.Action = &renderData, &action
+	for _, label := range []string{button.RenderData.Label, button.RenderData.VisitedLabel} {
+		if label == "" || utf8.RuneCountInString(label) > MaxLabelLength {
+			return nil, fmt.Errorf("%w: %q, max length %d", ErrInvalidLabel, label, MaxLabelLength)
+		}
+	}
+	if button.Action.Type == keyboard.ActionTypeURL && button.Action.Data == "" {
+		return nil, fmt.Errorf("%w: button %s has no url", ErrInvalidAction, button.ID)
+	}
+	return &button, nil
+}
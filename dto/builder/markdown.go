@@ -0,0 +1,50 @@
+package builder
+
+import (
+	"fmt"
+
+	"github.com/tencent-connect/botgo/dto"
+)
+
+// MarkdownBuilder markdown 的构造器，支持原生 markdown 与模版两种方式
+type MarkdownBuilder struct {
+	markdown dto.Markdown
+}
+
+// Markdown 创建原生 markdown
+func Markdown(content string) *MarkdownBuilder {
+	return &MarkdownBuilder{markdown: dto.Markdown{Content: content}}
+}
+
+// MarkdownTemplate 使用在开放平台申请的 markdown 模版
+func MarkdownTemplate(templateID int) *MarkdownBuilder {
+	return &MarkdownBuilder{markdown: dto.Markdown{TemplateID: templateID}}
+}
+
+// Param 设置模版参数
+func (b *MarkdownBuilder) Param(key string, values ...string) *MarkdownBuilder {
+	b.markdown.Params = append(b.markdown.Params, &dto.MarkdownParams{Key: key, Values: values})
+	return b
+}
+
+// Build 校验并生成 markdown
+func (b *MarkdownBuilder) Build() (*dto.Markdown, error) {
+	markdown := b.markdown
+	switch {
+	case markdown.TemplateID == 0 && markdown.Content == "":
+		return nil, fmt.Errorf("%w: neither content nor template is set", ErrInvalidMarkdown)
+	case markdown.TemplateID != 0 && markdown.Content != "":
+		return nil, fmt.Errorf("%w: content and template are exclusive", ErrInvalidMarkdown)
+	case markdown.TemplateID == 0 && len(markdown.Params) > 0:
+		return nil, fmt.Errorf("%w: params require a template", ErrInvalidMarkdown)
+	}
+	keys := map[string]bool{}
+	for _, param := range markdown.Params {
+		if param.Key == "" || keys[param.Key] || len(param.Values) == 0 {
+			return nil, fmt.Errorf("%w: invalid param %q", ErrInvalidMarkdown, param.Key)
+		}
+		keys[param.Key] = true
+	}
+	markdown.Params = append([]*dto.MarkdownParams(nil), markdown.Params...)
+	return &markdown, nil
+}
@@ -0,0 +1,159 @@
+// Package builder 提供 markdown，消息按钮，ark，embed 与消息的构造器，在发送前按照开放平台的限制进行校验
+package builder
+
+import (
+	"github.com/tencent-connect/botgo/dto"
+	"github.com/tencent-connect/botgo/dto/keyboard"
+)
+
+// MessageBuilder 消息的构造器，根据设置的内容生成 v2 接口的 msg_type
+type MessageBuilder struct {
+	msg      dto.MessageToCreate
+	markdown *MarkdownBuilder
+	keyboard *KeyboardBuilder
+	ark      *ArkBuilder
+	embed    *EmbedBuilder
+}
+
+// Message 创建消息
+func Message() *MessageBuilder {
+	return &MessageBuilder{}
+}
+
+// Text 创建文本消息
+func Text(content string) *MessageBuilder {
+	return Message().Content(content)
+}
+
+// Content 设置文本内容
+func (b *MessageBuilder) Content(content string) *MessageBuilder {
+	b.msg.Content = content
+	return b
+}
+
+// Image 设置图片链接，仅频道消息支持
+func (b *MessageBuilder) Image(url string) *MessageBuilder {
+	b.msg.Image = url
+	return b
+}
+
+// Markdown 设置 markdown
+func (b *MessageBuilder) Markdown(markdown *MarkdownBuilder) *MessageBuilder {
+	b.markdown = markdown
+	return b
+}
+
+// Keyboard 设置自定义消息按钮，需要与 markdown 一起发送，不能与消息按钮模版同时设置
+func (b *MessageBuilder) Keyboard(keyboard *KeyboardBuilder) *MessageBuilder {
+	b.keyboard = keyboard
+	return b
+}
+
+// KeyboardTemplate 设置消息按钮模版，需要与 markdown 一起发送，不能与自定义消息按钮同时设置
+func (b *MessageBuilder) KeyboardTemplate(id string) *MessageBuilder {
+	b.msg.Keyboard = KeyboardTemplate(id)
+	return b
+}
+
+// Ark 设置 ark
+func (b *MessageBuilder) Ark(ark *ArkBuilder) *MessageBuilder {
+	b.ark = ark
+	return b
+}
+
+// Embed 设置 embed
+func (b *MessageBuilder) Embed(embed *EmbedBuilder) *MessageBuilder {
+	b.embed = embed
+	return b
+}
+
+// Media 设置富媒体
+func (b *MessageBuilder) Media(media *dto.Media) *MessageBuilder {
+	b.msg.Media = media
+	return b
+}
+
+// ReplyTo 作为被动消息回复指定的消息
+func (b *MessageBuilder) ReplyTo(msgID string) *MessageBuilder {
+	b.msg.MsgID = msgID
+	return b
+}
+
+// ReplyEvent 作为被动消息回复指定的事件
+func (b *MessageBuilder) ReplyEvent(eventID string) *MessageBuilder {
+	b.msg.EventID = eventID
+	return b
+}
+
+// Seq 设置回复的序号，同一条消息的多次回复需要使用不同的序号
+func (b *MessageBuilder) Seq(seq int) *MessageBuilder {
+	b.msg.MessageSeq = seq
+	return b
+}
+
+// Reference 引用消息
+func (b *MessageBuilder) Reference(msgID string) *MessageBuilder {
+	b.msg.MessageReference = &dto.MessageReference{MessageID: msgID, IgnoreGetMessageError: true}
+	return b
+}
+
+// Build 校验并生成消息，设置对应的 msg_type
+func (b *MessageBuilder) Build() (*dto.MessageToCreate, error) {
+	msg := b.msg
+	if err := b.buildParts(&msg); err != nil {
+		return nil, err
+	}
+	kinds := 0
+	for kind, set := range map[int]bool{
+		dto.MsgTypeMarkdown: msg.Markdown != nil,
+		dto.MsgTypeArk:      msg.Ark != nil,
+		dto.MsgTypeEmbed:    msg.Embed != nil,
+		dto.MsgTypeMedia:    msg.Media != nil,
+	} {
+		if set {
+			msg.Type = kind
+			kinds++
+		}
+	}
+	if kinds > 1 {
+		return nil, ErrConflictContent
+	}
+	if msg.Keyboard != nil && msg.Markdown == nil {
+		return nil, ErrKeyboardWithoutMarkdown
+	}
+	if kinds == 0 && msg.Content == "" && msg.Image == "" {
+		return nil, ErrEmptyContent
+	}
+	return &msg, nil
+}
+
+// buildParts 生成 markdown，消息按钮，ark 与 embed
+func (b *MessageBuilder) buildParts(msg *dto.MessageToCreate) error {
+	var err error
+	if b.markdown != nil {
+		if msg.Markdown, err = b.markdown.Build(); err != nil {
+			return err
+		}
+	}
+	if b.keyboard != nil {
+		if msg.Keyboard != nil {
+			return ErrConflictKeyboard
+		}
+		var custom *keyboard.MessageKeyboard
+		if custom, err = b.keyboard.Build(); err != nil {
+			return err
+		}
+		msg.Keyboard = custom
+	}
+	if b.ark != nil {
+		if msg.Ark, err = b.ark.Build(); err != nil {
+			return err
+		}
+	}
+	if b.embed != nil {
+		if msg.Embed, err = b.embed.Build(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,5 +1,7 @@
 package dto
 
+import "encoding/json"
+
 // Thread 主题事件主体内容
 type Thread struct {
 	GuildID    string     `json:"guild_id"`
@@ -63,3 +65,152 @@ type ForumAuditResult struct {
 	ErrMsg      string `json:"err_msg"`
 	DateTime    string `json:"date_time"`
 }
+
+// ThreadFormat 发表主题的内容格式
+type ThreadFormat uint32
+
+// 发表主题的内容格式
+const (
+	ThreadFormatText     ThreadFormat = 1 // 普通文本
+	ThreadFormatHTML     ThreadFormat = 2 // HTML
+	ThreadFormatMarkdown ThreadFormat = 3 // Markdown
+	ThreadFormatJSON     ThreadFormat = 4 // 富文本 JSON，见 RichText
+)
+
+// ThreadToCreate 发表主题的结构体定义
+type ThreadToCreate struct {
+	Title   string       `json:"title"`
+	Content string       `json:"content"`
+	Format  ThreadFormat `json:"format"`
+}
+
+// ThreadsList 子频道下的主题列表
+type ThreadsList struct {
+	Threads  []*Thread `json:"threads"`
+	IsFinish uint32    `json:"is_finish"` // 是否拉取完毕，1 表示已经是最后一页
+}
+
+// ThreadWrapper 获取主题详情的返回
+type ThreadWrapper struct {
+	Thread *Thread `json:"thread"`
+}
+
+// ThreadPublished 发表主题的返回，主题需要审核，审核结果通过 FORUM_PUBLISH_AUDIT_RESULT 事件通知
+type ThreadPublished struct {
+	TaskID     string `json:"task_id"`
+	CreateTime string `json:"create_time"`
+}
+
+// RichText 论坛富文本内容，主题与帖子的 content 为 RichText 的 json，发表主题时使用 ThreadFormatJSON
+type RichText struct {
+	Paragraphs []*Paragraph `json:"paragraphs"`
+}
+
+// Paragraph 富文本的段落
+type Paragraph struct {
+	Elems []*Elem         `json:"elems"`
+	Props *ParagraphProps `json:"props,omitempty"`
+}
+
+// ParagraphAlignment 段落的对齐方式
+type ParagraphAlignment uint32
+
+// 段落的对齐方式
+const (
+	AlignmentLeft   ParagraphAlignment = 0 // 左对齐
+	AlignmentCenter ParagraphAlignment = 1 // 居中
+	AlignmentRight  ParagraphAlignment = 2 // 右对齐
+)
+
+// ParagraphProps 段落的属性
+type ParagraphProps struct {
+	Alignment ParagraphAlignment `json:"alignment"`
+}
+
+// ElemType 富文本元素的类型
+type ElemType uint32
+
+// 富文本元素的类型
+const (
+	ElemTypeText  ElemType = 1 // 文本
+	ElemTypeImage ElemType = 2 // 图片
+	ElemTypeVideo ElemType = 3 // 视频
+	ElemTypeURL   ElemType = 4 // 链接
+)
+
+// Elem 富文本元素，按照 Type 设置对应的字段
+type Elem struct {
+	Type  ElemType   `json:"type"`
+	Text  *TextElem  `json:"text,omitempty"`
+	Image *ImageElem `json:"image,omitempty"`
+	Video *VideoElem `json:"video,omitempty"`
+	URL   *URLElem   `json:"url,omitempty"`
+}
+
+// TextElem 文本元素
+type TextElem struct {
+	Text  string     `json:"text"`
+	Props *TextProps `json:"props,omitempty"`
+}
+
+// TextProps 文本的样式
+type TextProps struct {
+	Bold      bool `json:"font_bold,omitempty"` // 加粗
+	Italic    bool `json:"italic,omitempty"`    // 斜体
+	Underline bool `json:"underline,omitempty"` // 下划线
+}
+
+// ImageElem 图片元素
+type ImageElem struct {
+	ThirdURL     string     `json:"third_url,omitempty"`     // 第三方图片链接，发表主题时使用
+	WidthPercent float64    `json:"width_percent,omitempty"` // 宽度比例
+	PlatImage    *PlatImage `json:"plat_image,omitempty"`    // 平台图片，收到的内容中使用
+}
+
+// PlatImage 平台上的图片
+type PlatImage struct {
+	URL     string `json:"url"`
+	Width   uint32 `json:"width"`
+	Height  uint32 `json:"height"`
+	ImageID string `json:"image_id"`
+}
+
+// VideoElem 视频元素
+type VideoElem struct {
+	ThirdURL  string     `json:"third_url,omitempty"`  // 第三方视频链接，发表主题时使用
+	PlatVideo *PlatVideo `json:"plat_video,omitempty"` // 平台视频，收到的内容中使用
+}
+
+// PlatVideo 平台上的视频
+type PlatVideo struct {
+	URL      string     `json:"url"`
+	Width    uint32     `json:"width"`
+	Height   uint32     `json:"height"`
+	VideoID  string     `json:"video_id"`
+	Duration uint32     `json:"duration"` // 时长，单位秒
+	Cover    *PlatImage `json:"cover,omitempty"`
+}
+
+// URLElem 链接元素
+type URLElem struct {
+	URL  string `json:"url"`
+	Desc string `json:"desc"`
+}
+
+// ParseRichText 解析主题，帖子与回复中 json 格式的 content
+func ParseRichText(content string) (*RichText, error) {
+	richText := &RichText{}
+	if err := json.Unmarshal([]byte(content), richText); err != nil {
+		return nil, err
+	}
+	return richText, nil
+}
+
+// NewRichTextThread 使用富文本内容构造发表的主题
+func NewRichTextThread(title string, content *RichText) (*ThreadToCreate, error) {
+	data, err := json.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+	return &ThreadToCreate{Title: title, Content: string(data), Format: ThreadFormatJSON}, nil
+}
@@ -81,3 +81,8 @@ type MediaReturnParam struct {
 	Info string `json:"file_info"`
 	TTL  int    `json:"ttl"` // 有效期，表示剩余多少秒到期，到期后 file_info 失效，当等于 0 时，表示可长期使用
 }
+
+// Media 使用上传返回的 file_info 构造发送的富媒体消息
+func (m *MediaReturnParam) Media() *Media {
+	return &Media{FileInfo: m.Info}
+}
@@ -0,0 +1,171 @@
+package message
+
+import (
+	"regexp"
+	"strings"
+)
+
+// SegmentType 消息内容片段的类型
+type SegmentType int
+
+// 消息内容片段的类型
+const (
+	SegmentText            SegmentType = iota // 文本
+	SegmentMentionUser                        // at 用户，<@id> 或 <@!id>
+	SegmentMentionChannel                     // 提到子频道，<#id>
+	SegmentEmoji                              // 系统表情，<emoji:id>
+	SegmentMentionEveryone                    // at 全体成员，@everyone
+)
+
+// Segment 消息内容的片段
+type Segment struct {
+	Type   SegmentType
+	Text   string // 文本片段的内容，已经反转义
+	ID     string // 用户，子频道或者表情的 ID
+	Legacy bool   // at 用户是否为 <@!id> 格式
+}
+
+// Segments 解析后的消息内容
+type Segments []Segment
+
+// 内嵌格式的正则，参考 https://bot.q.qq.com/wiki/develop/api/openapi/message/message_format.html
+var segmentRE = regexp.MustCompile(`<@(!?)(\w+)>|<#(\w+)>|<emoji:(\d+)>|@everyone`)
+
+// 消息内容中的转义字符
+var (
+	unescaper = strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&")
+	escaper   = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+)
+
+// Parse 将消息内容解析为片段，文本片段会反转义 &lt; &gt; &amp;
+func Parse(content string) Segments {
+	var segments Segments
+	last := 0
+	for _, m := range segmentRE.FindAllStringSubmatchIndex(content, -1) {
+		if m[0] > last {
+			segments = append(segments, textSegment(content[last:m[0]]))
+		}
+		segments = append(segments, inlineSegment(content, m))
+		last = m[1]
+	}
+	if last < len(content) {
+		segments = append(segments, textSegment(content[last:]))
+	}
+	return segments
+}
+
+func textSegment(text string) Segment {
+	return Segment{Type: SegmentText, Text: unescaper.Replace(text)}
+}
+
+// inlineSegment 根据正则匹配的子表达式位置生成内嵌格式的片段
+func inlineSegment(content string, m []int) Segment {
+	switch {
+	case m[4] >= 0:
+		return Segment{Type: SegmentMentionUser, ID: content[m[4]:m[5]], Legacy: m[3] > m[2]}
+	case m[6] >= 0:
+		return Segment{Type: SegmentMentionChannel, ID: content[m[6]:m[7]]}
+	case m[8] >= 0:
+		return Segment{Type: SegmentEmoji, ID: content[m[8]:m[9]]}
+	default:
+		return Segment{Type: SegmentMentionEveryone}
+	}
+}
+
+// String 返回片段在消息内容中的格式，文本片段会进行转义
+func (s Segment) String() string {
+	switch s.Type {
+	case SegmentMentionUser:
+		if s.Legacy {
+			return "<@!" + s.ID + ">"
+		}
+		return MentionUser(s.ID)
+	case SegmentMentionChannel:
+		return "<#" + s.ID + ">"
+	case SegmentEmoji:
+		return "<emoji:" + s.ID + ">"
+	case SegmentMentionEveryone:
+		return MentionAllUser()
+	default:
+		return escaper.Replace(s.Text)
+	}
+}
+
+// Render 将片段渲染为消息内容，Parse 的逆过程
+func Render(segments Segments) string {
+	var b strings.Builder
+	for _, s := range segments {
+		b.WriteString(s.String())
+	}
+	return b.String()
+}
+
+// String 渲染为消息内容
+func (s Segments) String() string {
+	return Render(s)
+}
+
+// Text 返回所有文本片段拼接后的内容，用于内容审核等只关注文本的场景
+func (s Segments) Text() string {
+	var b strings.Builder
+	for _, segment := range s {
+		if segment.Type == SegmentText {
+			b.WriteString(segment.Text)
+		}
+	}
+	return b.String()
+}
+
+// Mentions 返回 at 的用户 ID
+func (s Segments) Mentions() []string {
+	return s.ids(SegmentMentionUser)
+}
+
+// Channels 返回提到的子频道 ID
+func (s Segments) Channels() []string {
+	return s.ids(SegmentMentionChannel)
+}
+
+// Emojis 返回使用的表情 ID
+func (s Segments) Emojis() []string {
+	return s.ids(SegmentEmoji)
+}
+
+// MentionsEveryone 是否 at 了全体成员
+func (s Segments) MentionsEveryone() bool {
+	for _, segment := range s {
+		if segment.Type == SegmentMentionEveryone {
+			return true
+		}
+	}
+	return false
+}
+
+// TrimMentions 去掉开头的 at 与空白，如 "<@!bot> /help" 返回 "/help" 对应的片段
+func (s Segments) TrimMentions() Segments {
+	for len(s) > 0 {
+		switch {
+		case s[0].Type == SegmentMentionUser:
+			s = s[1:]
+		case s[0].Type == SegmentText && strings.Trim(s[0].Text, spaceCharSet) == "":
+			s = s[1:]
+		case s[0].Type == SegmentText:
+			trimmed := s[0]
+			trimmed.Text = strings.TrimLeft(trimmed.Text, spaceCharSet)
+			return append(Segments{trimmed}, s[1:]...)
+		default:
+			return s
+		}
+	}
+	return s
+}
+
+func (s Segments) ids(t SegmentType) []string {
+	var ids []string
+	for _, segment := range s {
+		if segment.Type == t {
+			ids = append(ids, segment.ID)
+		}
+	}
+	return ids
+}
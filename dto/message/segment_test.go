@@ -0,0 +1,38 @@
+package message
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	content := "<@!123> hi <@abc>，看看 <#456> &lt;b&gt; &amp; <emoji:4> @everyone"
+	segments := Parse(content)
+	assert.Equal(t, Segments{
+		{Type: SegmentMentionUser, ID: "123", Legacy: true},
+		{Type: SegmentText, Text: " hi "},
+		{Type: SegmentMentionUser, ID: "abc"},
+		{Type: SegmentText, Text: "，看看 "},
+		{Type: SegmentMentionChannel, ID: "456"},
+		{Type: SegmentText, Text: " <b> & "},
+		{Type: SegmentEmoji, ID: "4"},
+		{Type: SegmentText, Text: " "},
+		{Type: SegmentMentionEveryone},
+	}, segments)
+	assert.Equal(t, content, Render(segments))
+	assert.Equal(t, " hi ，看看  <b> &  ", segments.Text())
+	assert.Equal(t, []string{"123", "abc"}, segments.Mentions())
+	assert.Equal(t, []string{"456"}, segments.Channels())
+	assert.Equal(t, []string{"4"}, segments.Emojis())
+	assert.True(t, segments.MentionsEveryone())
+
+	assert.Empty(t, Parse(""))
+	assert.Equal(t, "<not a mention>", Parse("&lt;not a mention&gt;").Text())
+}
+
+func TestSegments_TrimMentions(t *testing.T) {
+	assert.Equal(t, "/help <@1>", Parse("<@!bot> \u00A0/help <@1>").TrimMentions().String())
+	assert.Equal(t, "<#1>", Parse("<@!bot> <#1>").TrimMentions().String())
+	assert.Empty(t, Parse("<@!bot>  ").TrimMentions())
+}
@@ -2,6 +2,15 @@ package dto
 
 import "github.com/tencent-connect/botgo/dto/keyboard"
 
+// v2 接口的消息类型，对应 MessageToCreate 的 msg_type
+const (
+	MsgTypeText     = 0 // 文本
+	MsgTypeMarkdown = 2 // markdown
+	MsgTypeArk      = 3 // ark
+	MsgTypeEmbed    = 4 // embed
+	MsgTypeMedia    = 7 // 富媒体
+)
+
 // MessageToCreate 发送消息结构体定义，同时复用给v2接口
 type MessageToCreate struct {
 	Content string `json:"content,omitempty"`
@@ -18,7 +27,7 @@ type MessageToCreate struct {
 	// v2特有字段
 
 	Type       int    `json:"msg_type,omitempty"` // 消息类型：0 是文本，2 是 markdown， 3 ark，4 embed，7 media 富媒体
-	Media      *Media `json:"med,omitempty"`      // 富文本消息
+	Media      *Media `json:"media,omitempty"`    // 富媒体消息，使用上传富媒体接口返回的 file_info
 	MessageSeq int    `json:"msg_seq,omitempty"` //  回复消息的序号，与 msg_id 联合使用，避免相同消息id回复重复发送，不填默认是1，相同的 msg_id + msg_seq 重复发送会失败
 }
 
@@ -53,11 +62,24 @@ type SettingGuide struct {
 	GuildID string `json:"guild_id"`
 }
 
-// Media 富文本消息
+// MediaFileType 富媒体的类型
+type MediaFileType int
+
+// 富媒体的类型，资源格式要求 图片：png/jpg，视频：mp4，语音：silk
+const (
+	MediaFileTypeImage MediaFileType = 1 // 图片
+	MediaFileTypeVideo MediaFileType = 2 // 视频
+	MediaFileTypeVoice MediaFileType = 3 // 语音
+	MediaFileTypeFile  MediaFileType = 4 // 文件（暂不开放）
+)
+
+// Media 富媒体消息，上传时设置 FileType 与 URL 或者 FileData，发送时设置上传返回的 FileInfo
 //
 // https://bot.q.qq.com/wiki/develop/api-v2/server-inter/message/send-receive/rich-media.html
 type Media struct {
-	FileType    int    `json:"file_type"`    //媒体类型：1 图片，2 视频，3 语音，4 文件（暂不开放）资源格式要求 图片：png/jpg，视频：mp4，语音：silk
-	URL         string `json:"url"`          // 需要发送媒体资源的url
-	SendMessage bool   `json:"srv_send_msg"` // 设置 true 会直接发送消息到目标端，且会占用主动消息频次
+	FileType    MediaFileType `json:"file_type,omitempty"`    // 媒体类型
+	URL         string        `json:"url,omitempty"`          // 需要发送媒体资源的url
+	FileData    string        `json:"file_data,omitempty"`    // base64 编码的媒体资源内容，与 URL 二选一
+	SendMessage bool          `json:"srv_send_msg,omitempty"` // 设置 true 会直接发送消息到目标端，且会占用主动消息频次
+	FileInfo    string        `json:"file_info,omitempty"`    // 上传富媒体接口返回的 file_info，发送消息时使用
 }
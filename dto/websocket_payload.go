@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 // EventType 事件类型
 type EventType string
 
@@ -8,6 +10,7 @@ type WSPayload struct {
 	WSPayloadBase
 	Data       interface{} `json:"d,omitempty"`
 	RawMessage []byte      `json:"-"` // 原始的 message 数据
+	ReceivedAt time.Time   `json:"-"` // 收到消息的时间
 }
 
 // WSPayloadBase 基础消息结构，排除了 data
@@ -15,6 +18,7 @@ type WSPayloadBase struct {
 	OPCode OPCode    `json:"op"`
 	Seq    uint32    `json:"s,omitempty"`
 	Type   EventType `json:"t,omitempty"`
+	ID     string    `json:"id,omitempty"` // 事件 ID
 }
 
 // 以下为发送到 websocket 的 data
@@ -0,0 +1,106 @@
+package errs
+
+import (
+	"net/http"
+)
+
+// 开放平台业务错误码，完整列表见 https://bot.q.qq.com/wiki/develop/api/openapi/error/error.html
+const (
+	// CodeUnknownAccount 未知账号
+	CodeUnknownAccount = 10001
+	// CodeUnknownChannel 未知子频道
+	CodeUnknownChannel = 10003
+	// CodeUnknownGuild 未知频道
+	CodeUnknownGuild = 10004
+	// CodeWrongToken token 错误
+	CodeWrongToken = 11241
+	// CodeCheckTokenFailed 校验 token 失败
+	CodeCheckTokenFailed = 11242
+	// CodeCheckTokenNotPass token 校验未通过
+	CodeCheckTokenNotPass = 11243
+	// CodeCheckAppPrivilegeNotPass 机器人没有接口权限
+	CodeCheckAppPrivilegeNotPass = 11253
+	// CodeInterfaceForbidden 接口被封禁
+	CodeInterfaceForbidden = 11254
+	// CodeGuildAuthNotPass 频道没有授权机器人
+	CodeGuildAuthNotPass = 11264
+	// CodeRobotHasBanned 机器人已经被封禁
+	CodeRobotHasBanned = 11265
+	// CodeUserAuthNotPass 用户没有授权机器人
+	CodeUserAuthNotPass = 11274
+	// CodeCheckAdminNotPass 机器人不是管理员
+	CodeCheckAdminNotPass = 11282
+	// CodeMessageTooFrequent 消息发送超频
+	CodeMessageTooFrequent = 22009
+	// CodeMessageAuditing 消息需要审核，审核结果通过 MESSAGE_AUDIT_PASS 或 MESSAGE_AUDIT_REJECT 事件通知
+	CodeMessageAuditing = 304023
+	// CodeMessageAuditingAsync 消息需要审核，审核是异步的
+	CodeMessageAuditingAsync = 304024
+	// CodeMessageExpired 被动回复的消息已经过期，超过了可以回复的时间
+	CodeMessageExpired = 304027
+)
+
+// 平台错误目录，可以通过 errors.Is 判断 openapi 返回的错误
+// 业务错误码哨兵匹配返回包中的 code 字段，状态码哨兵匹配 http 状态码
+var (
+	// ErrUnauthorized 鉴权失败
+	ErrUnauthorized = newStatusSentinel(http.StatusUnauthorized)
+	// ErrForbidden 没有权限
+	ErrForbidden = newStatusSentinel(http.StatusForbidden)
+	// ErrNotFound 资源不存在
+	ErrNotFound = newStatusSentinel(http.StatusNotFound)
+	// ErrTooManyRequests 请求超过频率限制
+	ErrTooManyRequests = newStatusSentinel(http.StatusTooManyRequests)
+
+	// ErrUnknownAccount 未知账号
+	ErrUnknownAccount = newBizSentinel(CodeUnknownAccount, "unknown account")
+	// ErrUnknownChannel 未知子频道
+	ErrUnknownChannel = newBizSentinel(CodeUnknownChannel, "unknown channel")
+	// ErrUnknownGuild 未知频道
+	ErrUnknownGuild = newBizSentinel(CodeUnknownGuild, "unknown guild")
+	// ErrWrongToken token 错误
+	ErrWrongToken = newBizSentinel(CodeWrongToken, "wrong token")
+	// ErrCheckTokenFailed 校验 token 失败
+	ErrCheckTokenFailed = newBizSentinel(CodeCheckTokenFailed, "check token failed")
+	// ErrCheckTokenNotPass token 校验未通过
+	ErrCheckTokenNotPass = newBizSentinel(CodeCheckTokenNotPass, "check token not pass")
+	// ErrNoAppPrivilege 机器人没有接口权限
+	ErrNoAppPrivilege = newBizSentinel(CodeCheckAppPrivilegeNotPass, "app privilege not pass")
+	// ErrInterfaceForbidden 接口被封禁
+	ErrInterfaceForbidden = newBizSentinel(CodeInterfaceForbidden, "interface forbidden")
+	// ErrGuildAuthNotPass 频道没有授权机器人
+	ErrGuildAuthNotPass = newBizSentinel(CodeGuildAuthNotPass, "guild auth not pass")
+	// ErrRobotHasBanned 机器人已经被封禁
+	ErrRobotHasBanned = newBizSentinel(CodeRobotHasBanned, "robot has banned")
+	// ErrUserAuthNotPass 用户没有授权机器人
+	ErrUserAuthNotPass = newBizSentinel(CodeUserAuthNotPass, "user auth not pass")
+	// ErrNotAdmin 机器人不是管理员
+	ErrNotAdmin = newBizSentinel(CodeCheckAdminNotPass, "check admin not pass")
+	// ErrMessageTooFrequent 消息发送超频
+	ErrMessageTooFrequent = newBizSentinel(CodeMessageTooFrequent, "message too frequent")
+	// ErrMessageAuditing 消息需要审核，不是发送失败
+	ErrMessageAuditing = newBizSentinel(CodeMessageAuditing, "message is auditing")
+	// ErrMessageAuditingAsync 消息需要异步审核，不是发送失败
+	ErrMessageAuditingAsync = newBizSentinel(CodeMessageAuditingAsync, "message is auditing async")
+	// ErrPassiveReplyExpired 被动回复的消息已经过期
+	ErrPassiveReplyExpired = newBizSentinel(CodeMessageExpired, "passive reply expired")
+)
+
+func newStatusSentinel(status int) error {
+	return &Err{
+		code:     status,
+		text:     http.StatusText(status),
+		status:   status,
+		sentinel: true,
+	}
+}
+
+func newBizSentinel(bizCode int, message string) error {
+	return &Err{
+		code:     bizCode,
+		text:     message,
+		bizCode:  bizCode,
+		message:  message,
+		sentinel: true,
+	}
+}
@@ -0,0 +1,117 @@
+package errs
+
+import (
+	"errors"
+	"net"
+	"net/http"
+
+	wss "github.com/gorilla/websocket"
+)
+
+// websocket 关闭连接的错误码 https://bot.q.qq.com/wiki/develop/api/gateway/error/error.html
+const (
+	closeCodeAuthFailed     = 4004 // 鉴权失败
+	closeCodeSessionTimeout = 4009 // 连接过期，可以 resume
+	closeCodeBotOffline     = 4914 // 机器人已下架
+	closeCodeBotBanned      = 4915 // 机器人已封禁
+)
+
+// permissionBizCodes 没有权限的业务错误码
+var permissionBizCodes = map[int]bool{
+	CodeCheckAppPrivilegeNotPass: true,
+	CodeInterfaceForbidden:       true,
+	CodeGuildAuthNotPass:         true,
+	CodeUserAuthNotPass:          true,
+	CodeCheckAdminNotPass:        true,
+}
+
+// authBizCodes 鉴权失败的业务错误码
+var authBizCodes = map[int]bool{
+	CodeWrongToken:        true,
+	CodeCheckTokenFailed:  true,
+	CodeCheckTokenNotPass: true,
+	CodeRobotHasBanned:    true,
+}
+
+// rateLimitBizCodes 频率限制的业务错误码
+var rateLimitBizCodes = map[int]bool{
+	CodeMessageTooFrequent: true,
+}
+
+// retryableStatus 可以重试的 http 状态码
+var retryableStatus = map[int]bool{
+	http.StatusTooManyRequests:    true,
+	http.StatusBadGateway:         true,
+	http.StatusServiceUnavailable: true,
+	http.StatusGatewayTimeout:     true,
+}
+
+// retryableCodes 可以重试的 sdk 错误码，websocket 连接可以 resume 的错误
+// 不能 resume 的错误（CodeConnCloseCantResume）需要重新鉴权，不能直接重试
+var retryableCodes = map[int]bool{
+	CodeNeedReConnect:    true,
+	CodeHeartbeatTimeout: true,
+}
+
+// IsRetryable 是否是可以重试的错误
+// 包括频率限制，网关类的 5xx 状态码，网络超时，以及 websocket 需要重连的错误，鉴权失败的错误不能重试
+func IsRetryable(err error) bool {
+	if err == nil || IsAuth(err) {
+		return false
+	}
+	if IsRateLimit(err) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	if isCloseError(err, closeCodeSessionTimeout) {
+		return true
+	}
+	e := &Err{}
+	if !errors.As(err, &e) {
+		return false
+	}
+	return retryableStatus[e.status] || retryableCodes[e.code]
+}
+
+// IsPermission 是否是没有权限的错误，如机器人没有接口权限，不是管理员，频道没有授权等
+func IsPermission(err error) bool {
+	e := &Err{}
+	if !errors.As(err, &e) {
+		return false
+	}
+	return e.status == http.StatusForbidden || permissionBizCodes[e.bizCode]
+}
+
+// IsRateLimit 是否是频率限制的错误，如 429 状态码，消息发送超频
+func IsRateLimit(err error) bool {
+	e := &Err{}
+	if !errors.As(err, &e) {
+		return false
+	}
+	return e.status == http.StatusTooManyRequests || rateLimitBizCodes[e.bizCode]
+}
+
+// IsAuth 是否是鉴权失败的错误，如 token 错误，机器人被封禁
+// 对于 websocket，包括鉴权失败，机器人下架与封禁的连接关闭错误
+func IsAuth(err error) bool {
+	if isCloseError(err, closeCodeAuthFailed, closeCodeBotOffline, closeCodeBotBanned) {
+		return true
+	}
+	e := &Err{}
+	if !errors.As(err, &e) {
+		return false
+	}
+	return e.status == http.StatusUnauthorized || authBizCodes[e.bizCode]
+}
+
+// isCloseError 错误链中是否有指定错误码的 websocket 关闭连接错误
+func isCloseError(err error, codes ...int) bool {
+	var closeErr *wss.CloseError
+	if !errors.As(err, &closeErr) {
+		return false
+	}
+	return wss.IsCloseError(closeErr, codes...)
+}
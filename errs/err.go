@@ -2,6 +2,8 @@
 package errs
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 )
 
@@ -19,6 +21,12 @@ var (
 	ErrNotFoundOpenAPI = New(CodeNotFoundOpenAPI, "not found openapi version")
 	// ErrPagerIsNil 分页器为空
 	ErrPagerIsNil = New(CodePagerIsNil, "pager is nil")
+	// ErrConnClosed 连接被客户端主动关闭
+	ErrConnClosed = New(CodeConnClosed, "connection closed by client")
+	// ErrShutdownTimeout 关闭 session manager 时等待连接退出超时
+	ErrShutdownTimeout = New(CodeShutdownTimeout, "shutdown timeout")
+	// ErrHeartbeatTimeout 连续多次心跳没有收到 ack，连接已经失效，需要 resume
+	ErrHeartbeatTimeout = New(CodeHeartbeatTimeout, "heartbeat ack timeout")
 )
 
 // sdk 错误码
@@ -35,13 +43,35 @@ const (
 	CodeConnCloseCantIdentify
 	// CodePagerIsNil 分页器为空
 	CodePagerIsNil
+	// CodeConnClosed 连接被客户端主动关闭，不需要重连
+	CodeConnClosed
+	// CodeShutdownTimeout 关闭时等待连接退出超时
+	CodeShutdownTimeout
+	// CodeHeartbeatTimeout 心跳 ack 超时，连接失效，可以 resume
+	CodeHeartbeatTimeout
 )
 
+// CodeUnknown 无法识别的错误
+const CodeUnknown = 9999
+
 // Err sdk err
 type Err struct {
-	code  int
-	text  string
-	trace string // 错误追踪ID，可用于向平台反馈问题
+	code    int
+	text    string
+	trace   string // 错误追踪ID，可用于向平台反馈问题
+	status  int    // http 状态码，非 openapi 返回的错误为 0
+	bizCode int    // 开放平台返回的业务错误码，即返回包中的 code 字段
+	message string // 开放平台返回的错误信息，即返回包中的 message 字段
+	cause   error  // 原始错误
+	// sentinel 错误目录中的哨兵错误，errors.Is 时按照业务错误码或 http 状态码匹配
+	sentinel bool
+}
+
+// apiErrorBody 开放平台错误返回包
+type apiErrorBody struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	TraceID string `json:"trace_id"`
 }
 
 // New 创建一个新错误
@@ -56,14 +86,46 @@ func New(code int, text string, trace ...string) error {
 	return err
 }
 
-// Error 将错误转换为 sdk 的错误类型
+// Wrap 使用错误码包装原始错误，可以通过 errors.As 获取原始错误，如 websocket 的 *CloseError
+func Wrap(code int, cause error) error {
+	return &Err{
+		code:  code,
+		text:  cause.Error(),
+		cause: cause,
+	}
+}
+
+// NewAPIError 根据 openapi 返回的状态码与返回包创建错误，Code 为 http 状态码，Text 为原始返回包
+// 返回包中的 code 与 message 解析到 BizCode 与 Message 中，无法解析时 Message 为原始返回包
+func NewAPIError(status int, body []byte, trace string) error {
+	err := &Err{
+		code:    status,
+		text:    string(body),
+		trace:   trace,
+		status:  status,
+		message: string(body),
+	}
+	parsed := &apiErrorBody{}
+	if jsonErr := json.Unmarshal(body, parsed); jsonErr == nil {
+		err.bizCode = parsed.Code
+		err.message = parsed.Message
+		if err.trace == "" {
+			err.trace = parsed.TraceID
+		}
+	}
+	return err
+}
+
+// Error 将错误转换为 sdk 的错误类型，会查找错误链中的 *Err
 func Error(err error) *Err {
-	if e, ok := err.(*Err); ok {
+	e := &Err{}
+	if errors.As(err, &e) {
 		return e
 	}
 	return &Err{
-		code: 9999,
-		text: err.Error(),
+		code:  CodeUnknown,
+		text:  err.Error(),
+		cause: err,
 	}
 }
 
@@ -72,12 +134,12 @@ func (e Err) Error() string {
 	return fmt.Sprintf("code:%v, text:%v, traceID:%s", e.code, e.text, e.trace)
 }
 
-// Code 获取错误码
+// Code 获取错误码，openapi 返回的错误为 http 状态码
 func (e Err) Code() int {
 	return e.code
 }
 
-// Text 获取错误信息
+// Text 获取错误信息，openapi 返回的错误为原始返回包
 func (e Err) Text() string {
 	return e.text
 }
@@ -86,3 +148,38 @@ func (e Err) Text() string {
 func (e Err) Trace() string {
 	return e.trace
 }
+
+// Status 获取 http 状态码，非 openapi 返回的错误为 0
+func (e Err) Status() int {
+	return e.status
+}
+
+// BizCode 获取开放平台返回的业务错误码，未返回时为 0
+func (e Err) BizCode() int {
+	return e.bizCode
+}
+
+// Message 获取开放平台返回的错误信息
+func (e Err) Message() string {
+	return e.message
+}
+
+// Unwrap 返回原始错误，支持 errors.Is 与 errors.As
+func (e Err) Unwrap() error {
+	return e.cause
+}
+
+// Is 支持 errors.Is 与错误目录中的哨兵错误比较
+// 业务错误码哨兵（如 ErrMessageTooFrequent）匹配相同业务错误码的错误
+// 状态码哨兵（如 ErrTooManyRequests）匹配相同 http 状态码的错误
+// 其他错误只与自身相等
+func (e Err) Is(target error) bool {
+	t, ok := target.(*Err)
+	if !ok || !t.sentinel {
+		return false
+	}
+	if t.bizCode != 0 {
+		return e.bizCode == t.bizCode
+	}
+	return e.status == t.status
+}
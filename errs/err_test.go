@@ -0,0 +1,101 @@
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	wss "github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAPIError(t *testing.T) {
+	t.Run("json body", func(t *testing.T) {
+		body := []byte(`{"code":22009,"message":"push message is too frequently","trace_id":"trace"}`)
+		err := NewAPIError(http.StatusBadRequest, body, "")
+		e := Error(err)
+		assert.Equal(t, http.StatusBadRequest, e.Code())
+		assert.Equal(t, http.StatusBadRequest, e.Status())
+		assert.Equal(t, CodeMessageTooFrequent, e.BizCode())
+		assert.Equal(t, "push message is too frequently", e.Message())
+		assert.Equal(t, string(body), e.Text())
+		assert.Equal(t, "trace", e.Trace())
+	})
+	t.Run("plain body", func(t *testing.T) {
+		e := Error(NewAPIError(http.StatusBadGateway, []byte("bad gateway"), "trace"))
+		assert.Equal(t, 0, e.BizCode())
+		assert.Equal(t, "bad gateway", e.Message())
+		assert.Equal(t, "trace", e.Trace())
+	})
+}
+
+func TestErr_Is(t *testing.T) {
+	tooFrequent := NewAPIError(http.StatusBadRequest, []byte(`{"code":22009,"message":"too frequent"}`), "")
+	wrapped := fmt.Errorf("post message: %w", tooFrequent)
+	assert.True(t, errors.Is(wrapped, ErrMessageTooFrequent))
+	assert.False(t, errors.Is(wrapped, ErrPassiveReplyExpired))
+	assert.False(t, errors.Is(wrapped, ErrTooManyRequests))
+
+	notFound := NewAPIError(http.StatusNotFound, []byte(`{"code":10004,"message":"unknown guild"}`), "")
+	assert.True(t, errors.Is(notFound, ErrNotFound))
+	assert.True(t, errors.Is(notFound, ErrUnknownGuild))
+	assert.False(t, errors.Is(notFound, ErrUnknownChannel))
+
+	// 错误码相同的 sdk 错误不会相等
+	assert.True(t, errors.Is(ErrURLInvalid, ErrURLInvalid))
+	assert.False(t, errors.Is(ErrSessionLimit, ErrURLInvalid))
+	assert.False(t, errors.Is(New(CodeConnCloseCantResume, "closed"), ErrInvalidSession))
+}
+
+func TestWrap(t *testing.T) {
+	closeErr := &wss.CloseError{Code: 4914, Text: "offline"}
+	err := Wrap(CodeConnCloseCantIdentify, closeErr)
+	assert.Equal(t, CodeConnCloseCantIdentify, Error(err).Code())
+	var target *wss.CloseError
+	assert.True(t, errors.As(err, &target))
+	assert.Equal(t, 4914, target.Code)
+
+	unknown := Error(errors.New("unknown"))
+	assert.Equal(t, CodeUnknown, unknown.Code())
+	assert.Equal(t, "unknown", unknown.Text())
+}
+
+func TestClassify(t *testing.T) {
+	apiErr := func(status int, body string) error {
+		return NewAPIError(status, []byte(body), "")
+	}
+	tests := []struct {
+		name       string
+		err        error
+		retryable  bool
+		permission bool
+		rateLimit  bool
+		auth       bool
+	}{
+		{"nil", nil, false, false, false, false},
+		{"429", apiErr(http.StatusTooManyRequests, ""), true, false, true, false},
+		{"too frequent", apiErr(http.StatusBadRequest, `{"code":22009}`), true, false, true, false},
+		{"503", apiErr(http.StatusServiceUnavailable, ""), true, false, false, false},
+		{"403", apiErr(http.StatusForbidden, ""), false, true, false, false},
+		{"not admin", apiErr(http.StatusBadRequest, `{"code":11282}`), false, true, false, false},
+		{"401", apiErr(http.StatusUnauthorized, ""), false, false, false, true},
+		{"wrong token", apiErr(http.StatusBadRequest, `{"code":11241}`), false, false, false, true},
+		{"reconnect", ErrNeedReConnect, true, false, false, false},
+		{"ws session timeout", &wss.CloseError{Code: 4009}, true, false, false, false},
+		{"ws bot offline", Wrap(CodeConnCloseCantIdentify, &wss.CloseError{Code: 4914}), false, false, false, true},
+		{"ws auth failed", Wrap(CodeConnCloseCantResume, &wss.CloseError{Code: 4004}), false, false, false, true},
+		{"ws cant resume", Wrap(CodeConnCloseCantResume, &wss.CloseError{Code: 4900}), false, false, false, false},
+		{"invalid session", ErrInvalidSession, false, false, false, false},
+		{"session limit", ErrSessionLimit, false, false, false, false},
+		{"plain", errors.New("plain"), false, false, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.retryable, IsRetryable(tt.err), "retryable")
+			assert.Equal(t, tt.permission, IsPermission(tt.err), "permission")
+			assert.Equal(t, tt.rateLimit, IsRateLimit(tt.err), "rate limit")
+			assert.Equal(t, tt.auth, IsAuth(tt.err), "auth")
+		})
+	}
+}
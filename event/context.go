@@ -0,0 +1,55 @@
+package event
+
+import (
+	"context"
+	"time"
+
+	"github.com/tencent-connect/botgo/dto"
+)
+
+type contextKey int
+
+const (
+	sessionKey contextKey = iota
+	traceIDKey
+	receivedTimeKey
+)
+
+// WithSession 将事件所属连接的 session 信息放入 context
+func WithSession(ctx context.Context, session dto.Session) context.Context {
+	return context.WithValue(ctx, sessionKey, session)
+}
+
+// SessionFromContext 获取事件所属连接的 session 信息，webhook 等没有连接的场景下不存在
+func SessionFromContext(ctx context.Context) (dto.Session, bool) {
+	session, ok := ctx.Value(sessionKey).(dto.Session)
+	return session, ok
+}
+
+// ShardFromContext 获取事件所属连接的 shard 信息
+func ShardFromContext(ctx context.Context) (dto.ShardConfig, bool) {
+	session, ok := SessionFromContext(ctx)
+	return session.Shards, ok
+}
+
+// WithTraceID 将事件的追踪 ID 放入 context
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, traceIDKey, traceID)
+}
+
+// TraceIDFromContext 获取事件的追踪 ID，websocket 场景下为事件 ID，webhook 场景下为请求的 trace id
+func TraceIDFromContext(ctx context.Context) string {
+	traceID, _ := ctx.Value(traceIDKey).(string)
+	return traceID
+}
+
+// WithReceivedTime 将收到事件的时间放入 context
+func WithReceivedTime(ctx context.Context, t time.Time) context.Context {
+	return context.WithValue(ctx, receivedTimeKey, t)
+}
+
+// ReceivedTimeFromContext 获取收到事件的时间
+func ReceivedTimeFromContext(ctx context.Context) (time.Time, bool) {
+	t, ok := ctx.Value(receivedTimeKey).(time.Time)
+	return t, ok
+}
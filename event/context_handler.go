@@ -0,0 +1,312 @@
+package event
+
+import (
+	"context"
+
+	"github.com/tencent-connect/botgo/dto"
+)
+
+// 以下为携带 context 的 handler，可以通过 SessionFromContext，TraceIDFromContext 等方法获取事件的上下文信息
+// 连接关闭时 context 会被取消，handler 中的耗时操作需要关注 ctx.Done()
+
+// PlainEventContextHandler 透传 context handler
+type PlainEventContextHandler func(ctx context.Context, event *dto.WSPayload, message []byte) error
+
+// GuildEventContextHandler 频道事件 context handler
+type GuildEventContextHandler func(ctx context.Context, event *dto.WSPayload, data *dto.WSGuildData) error
+
+// GuildMemberEventContextHandler 频道成员事件 context handler
+type GuildMemberEventContextHandler func(ctx context.Context, event *dto.WSPayload, data *dto.WSGuildMemberData) error
+
+// ChannelEventContextHandler 子频道事件 context handler
+type ChannelEventContextHandler func(ctx context.Context, event *dto.WSPayload, data *dto.WSChannelData) error
+
+// MessageEventContextHandler 消息事件 context handler
+type MessageEventContextHandler func(ctx context.Context, event *dto.WSPayload, data *dto.WSMessageData) error
+
+// MessageDeleteEventContextHandler 消息事件 context handler
+type MessageDeleteEventContextHandler func(
+	ctx context.Context, event *dto.WSPayload, data *dto.WSMessageDeleteData,
+) error
+
+// PublicMessageDeleteEventContextHandler 消息事件 context handler
+type PublicMessageDeleteEventContextHandler func(
+	ctx context.Context, event *dto.WSPayload, data *dto.WSPublicMessageDeleteData,
+) error
+
+// DirectMessageDeleteEventContextHandler 消息事件 context handler
+type DirectMessageDeleteEventContextHandler func(
+	ctx context.Context, event *dto.WSPayload, data *dto.WSDirectMessageDeleteData,
+) error
+
+// MessageReactionEventContextHandler 表情表态事件 context handler
+type MessageReactionEventContextHandler func(
+	ctx context.Context, event *dto.WSPayload, data *dto.WSMessageReactionData,
+) error
+
+// ATMessageEventContextHandler at 机器人消息事件 context handler
+type ATMessageEventContextHandler func(ctx context.Context, event *dto.WSPayload, data *dto.WSATMessageData) error
+
+// DirectMessageEventContextHandler 私信消息事件 context handler
+type DirectMessageEventContextHandler func(
+	ctx context.Context, event *dto.WSPayload, data *dto.WSDirectMessageData,
+) error
+
+// AudioEventContextHandler 音频机器人事件 context handler
+type AudioEventContextHandler func(ctx context.Context, event *dto.WSPayload, data *dto.WSAudioData) error
+
+// MessageAuditEventContextHandler 消息审核事件 context handler
+type MessageAuditEventContextHandler func(ctx context.Context, event *dto.WSPayload, data *dto.WSMessageAuditData) error
+
+// ThreadEventContextHandler 论坛主题事件 context handler
+type ThreadEventContextHandler func(ctx context.Context, event *dto.WSPayload, data *dto.WSThreadData) error
+
+// PostEventContextHandler 论坛回帖事件 context handler
+type PostEventContextHandler func(ctx context.Context, event *dto.WSPayload, data *dto.WSPostData) error
+
+// ReplyEventContextHandler 论坛帖子回复事件 context handler
+type ReplyEventContextHandler func(ctx context.Context, event *dto.WSPayload, data *dto.WSReplyData) error
+
+// ForumAuditEventContextHandler 论坛帖子审核事件 context handler
+type ForumAuditEventContextHandler func(ctx context.Context, event *dto.WSPayload, data *dto.WSForumAuditData) error
+
+// InteractionEventContextHandler 互动事件 context handler
+type InteractionEventContextHandler func(ctx context.Context, event *dto.WSPayload, data *dto.WSInteractionData) error
+
+// UserQueryEventContextHandler 用户单聊事件 context handler
+type UserQueryEventContextHandler func(ctx context.Context, event *dto.WSPayload, data *dto.WSUserQuery) error
+
+// GroupAtMessageEventContextHandler 群聊at机器人事件 context handler
+type GroupAtMessageEventContextHandler func(ctx context.Context, event *dto.WSPayload, data *dto.WSGroupAtMessage) error
+
+// UserAddBotEventContextHandler 用户添加机器人事件 context handler
+type UserAddBotEventContextHandler func(ctx context.Context, event *dto.WSPayload, data *dto.WSUserAddBot) error
+
+// UserDelBotEventContextHandler 用户删除机器人事件 context handler
+type UserDelBotEventContextHandler func(ctx context.Context, event *dto.WSPayload, data *dto.WSUserDelBot) error
+
+// UserRejectMessageEventContextHandler 用户拒绝主动消息事件 context handler
+type UserRejectMessageEventContextHandler func(
+	ctx context.Context, event *dto.WSPayload, data *dto.WSUserRejectMessage,
+) error
+
+// UserReciveMessageEventContextHandler 用户接受主动消息事件 context handler
+type UserReciveMessageEventContextHandler func(
+	ctx context.Context, event *dto.WSPayload, data *dto.WSUserReciveMessage,
+) error
+
+// AddGroupEventContextHandler 群聊添加机器人事件 context handler
+type AddGroupEventContextHandler func(ctx context.Context, event *dto.WSPayload, data *dto.WSAddGroup) error
+
+// QuitGroupEventContextHandler 群聊删除机器人事件 context handler
+type QuitGroupEventContextHandler func(ctx context.Context, event *dto.WSPayload, data *dto.WSQuitGroup) error
+
+// GroupRejectMessageEventContextHandler 群聊拒绝主动消息事件 context handler
+type GroupRejectMessageEventContextHandler func(
+	ctx context.Context, event *dto.WSPayload, data *dto.WSGroupRejectMessage,
+) error
+
+// GroupReciveMessageEventContextHandler 群聊接受主动消息事件 context handler
+type GroupReciveMessageEventContextHandler func(
+	ctx context.Context, event *dto.WSPayload, data *dto.WSGroupReciveMessage,
+) error
+
+// withContext 将不带 context 的 handler 转换为对应的 context handler，分发器内部统一使用 context handler
+func withContext(handler interface{}) interface{} {
+	switch h := handler.(type) {
+	case PlainEventHandler:
+		return PlainEventContextHandler(
+			func(_ context.Context, event *dto.WSPayload, message []byte) error {
+				return h(event, message)
+			},
+		)
+	case AudioEventHandler:
+		return AudioEventContextHandler(
+			func(_ context.Context, event *dto.WSPayload, data *dto.WSAudioData) error {
+				return h(event, data)
+			},
+		)
+	case InteractionEventHandler:
+		return InteractionEventContextHandler(
+			func(_ context.Context, event *dto.WSPayload, data *dto.WSInteractionData) error {
+				return h(event, data)
+			},
+		)
+	default:
+		return relationWithContext(handler)
+	}
+}
+
+// relationWithContext 转换频道关系链与论坛相关的 handler
+func relationWithContext(handler interface{}) interface{} {
+	switch h := handler.(type) {
+	case GuildEventHandler:
+		return GuildEventContextHandler(
+			func(_ context.Context, event *dto.WSPayload, data *dto.WSGuildData) error {
+				return h(event, data)
+			},
+		)
+	case GuildMemberEventHandler:
+		return GuildMemberEventContextHandler(
+			func(_ context.Context, event *dto.WSPayload, data *dto.WSGuildMemberData) error {
+				return h(event, data)
+			},
+		)
+	case ChannelEventHandler:
+		return ChannelEventContextHandler(
+			func(_ context.Context, event *dto.WSPayload, data *dto.WSChannelData) error {
+				return h(event, data)
+			},
+		)
+	case ThreadEventHandler:
+		return ThreadEventContextHandler(
+			func(_ context.Context, event *dto.WSPayload, data *dto.WSThreadData) error {
+				return h(event, data)
+			},
+		)
+	case PostEventHandler:
+		return PostEventContextHandler(
+			func(_ context.Context, event *dto.WSPayload, data *dto.WSPostData) error {
+				return h(event, data)
+			},
+		)
+	case ReplyEventHandler:
+		return ReplyEventContextHandler(
+			func(_ context.Context, event *dto.WSPayload, data *dto.WSReplyData) error {
+				return h(event, data)
+			},
+		)
+	case ForumAuditEventHandler:
+		return ForumAuditEventContextHandler(
+			func(_ context.Context, event *dto.WSPayload, data *dto.WSForumAuditData) error {
+				return h(event, data)
+			},
+		)
+	default:
+		return messageWithContext(handler)
+	}
+}
+
+// messageWithContext 转换频道消息相关的 handler
+func messageWithContext(handler interface{}) interface{} {
+	switch h := handler.(type) {
+	case MessageEventHandler:
+		return MessageEventContextHandler(
+			func(_ context.Context, event *dto.WSPayload, data *dto.WSMessageData) error {
+				return h(event, data)
+			},
+		)
+	case ATMessageEventHandler:
+		return ATMessageEventContextHandler(
+			func(_ context.Context, event *dto.WSPayload, data *dto.WSATMessageData) error {
+				return h(event, data)
+			},
+		)
+	case DirectMessageEventHandler:
+		return DirectMessageEventContextHandler(
+			func(_ context.Context, event *dto.WSPayload, data *dto.WSDirectMessageData) error {
+				return h(event, data)
+			},
+		)
+	case MessageDeleteEventHandler:
+		return MessageDeleteEventContextHandler(
+			func(_ context.Context, event *dto.WSPayload, data *dto.WSMessageDeleteData) error {
+				return h(event, data)
+			},
+		)
+	case PublicMessageDeleteEventHandler:
+		return PublicMessageDeleteEventContextHandler(
+			func(_ context.Context, event *dto.WSPayload, data *dto.WSPublicMessageDeleteData) error {
+				return h(event, data)
+			},
+		)
+	case DirectMessageDeleteEventHandler:
+		return DirectMessageDeleteEventContextHandler(
+			func(_ context.Context, event *dto.WSPayload, data *dto.WSDirectMessageDeleteData) error {
+				return h(event, data)
+			},
+		)
+	case MessageReactionEventHandler:
+		return MessageReactionEventContextHandler(
+			func(_ context.Context, event *dto.WSPayload, data *dto.WSMessageReactionData) error {
+				return h(event, data)
+			},
+		)
+	case MessageAuditEventHandler:
+		return MessageAuditEventContextHandler(
+			func(_ context.Context, event *dto.WSPayload, data *dto.WSMessageAuditData) error {
+				return h(event, data)
+			},
+		)
+	default:
+		return chatWithContext(handler)
+	}
+}
+
+// chatWithContext 转换单聊与群聊相关的 handler
+func chatWithContext(handler interface{}) interface{} {
+	switch h := handler.(type) {
+	case UserQueryEventHandler:
+		return UserQueryEventContextHandler(
+			func(_ context.Context, event *dto.WSPayload, data *dto.WSUserQuery) error {
+				return h(event, data)
+			},
+		)
+	case GroupAtMessageEventHandler:
+		return GroupAtMessageEventContextHandler(
+			func(_ context.Context, event *dto.WSPayload, data *dto.WSGroupAtMessage) error {
+				return h(event, data)
+			},
+		)
+	case UserAddBotEventHandler:
+		return UserAddBotEventContextHandler(
+			func(_ context.Context, event *dto.WSPayload, data *dto.WSUserAddBot) error {
+				return h(event, data)
+			},
+		)
+	case UserDelBotEventHandler:
+		return UserDelBotEventContextHandler(
+			func(_ context.Context, event *dto.WSPayload, data *dto.WSUserDelBot) error {
+				return h(event, data)
+			},
+		)
+	case UserRejectMessageEventHandler:
+		return UserRejectMessageEventContextHandler(
+			func(_ context.Context, event *dto.WSPayload, data *dto.WSUserRejectMessage) error {
+				return h(event, data)
+			},
+		)
+	case UserReciveMessageEventHandler:
+		return UserReciveMessageEventContextHandler(
+			func(_ context.Context, event *dto.WSPayload, data *dto.WSUserReciveMessage) error {
+				return h(event, data)
+			},
+		)
+	case AddGroupEventHandler:
+		return AddGroupEventContextHandler(
+			func(_ context.Context, event *dto.WSPayload, data *dto.WSAddGroup) error {
+				return h(event, data)
+			},
+		)
+	case QuitGroupEventHandler:
+		return QuitGroupEventContextHandler(
+			func(_ context.Context, event *dto.WSPayload, data *dto.WSQuitGroup) error {
+				return h(event, data)
+			},
+		)
+	case GroupRejectMessageEventHandler:
+		return GroupRejectMessageEventContextHandler(
+			func(_ context.Context, event *dto.WSPayload, data *dto.WSGroupRejectMessage) error {
+				return h(event, data)
+			},
+		)
+	case GroupReciveMessageEventHandler:
+		return GroupReciveMessageEventContextHandler(
+			func(_ context.Context, event *dto.WSPayload, data *dto.WSGroupReciveMessage) error {
+				return h(event, data)
+			},
+		)
+	default:
+		return handler
+	}
+}
@@ -0,0 +1,91 @@
+package event
+
+import (
+	"time"
+
+	"github.com/tencent-connect/botgo/dto"
+)
+
+// DefaultDispatcher 默认的事件分发器，包级别的 RegisterHandlers 与 ParseAndHandle 都基于它
+var DefaultDispatcher = NewDispatcher()
+
+// DefaultHandlers 默认分发器直接赋值的 handler 表
+var DefaultHandlers = &DefaultDispatcher.handlers
+
+// Dispatcher 事件分发器，持有独立的 handler 表
+//
+// 不同的分发器之间互不影响，可以在同一个进程中运行多个使用不同 handler 的机器人
+type Dispatcher struct {
+	handlers    Handlers        // 直接赋值的 handler
+	registry    handlerRegistry // 通过 RegisterHandlers 注册的 handler
+	middlewares []Middleware
+	timeout     time.Duration // 单个事件的处理超时时间，0 表示不限制
+	queueSize   int           // 连接接收事件的队列长度，0 表示使用 websocket 实现的默认值
+	workers     int           // 并发处理事件的 worker 数量，小于等于 1 时串行处理
+	workerQueue int           // 每个 worker 的队列长度
+	metrics     *poolMetrics  // 所有连接的处理池共用的计数
+}
+
+// NewDispatcher 创建一个新的事件分发器
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{metrics: &poolMetrics{}}
+}
+
+// WithTimeout 设置单个事件的处理超时时间，超时后 handler 收到的 ctx 会被取消
+func (d *Dispatcher) WithTimeout(duration time.Duration) *Dispatcher {
+	d.timeout = duration
+	return d
+}
+
+// WithQueueSize 设置每个连接接收事件的队列长度，队列满后不再从连接读取数据
+func (d *Dispatcher) WithQueueSize(size int) *Dispatcher {
+	d.queueSize = size
+	return d
+}
+
+// WithWorkers 设置每个连接并发处理事件的 worker 数量，以及每个 worker 的队列长度，处理池的总容量为 workers*queueSize
+// 相同 key（群 openid，子频道 ID，用户 openid，见 OrderKey）的事件按照收到的顺序串行处理，不同 key 的事件并发处理
+// workers 小于等于 1 时所有事件串行处理，queueSize 小于 1 时使用 DefaultWorkerQueueSize
+func (d *Dispatcher) WithWorkers(workers, queueSize int) *Dispatcher {
+	d.workers = workers
+	d.workerQueue = queueSize
+	return d
+}
+
+// QueueSize 返回连接接收事件的队列长度，未设置时为 0
+func (d *Dispatcher) QueueSize() int {
+	return d.queueSize
+}
+
+// NewPool 根据 WithWorkers 的配置创建事件处理池，未开启并发处理时返回 nil
+func (d *Dispatcher) NewPool() *OrderedPool {
+	if d.workers <= 1 {
+		return nil
+	}
+	return newOrderedPool(d.workers, d.workerQueue, d.metrics)
+}
+
+// PoolStats 返回所有连接的事件处理池的汇总统计，Blocked 持续增长说明 handler 的处理速度跟不上事件的速度
+func (d *Dispatcher) PoolStats() PoolStats {
+	return d.metrics.stats()
+}
+
+// NotifyReady 回调注册的 ReadyHandler
+func (d *Dispatcher) NotifyReady(payload *dto.WSPayload, data *dto.WSReadyData) {
+	for _, h := range d.registry.Ready {
+		h(payload, data)
+	}
+	if h := d.handlers.Ready; h != nil {
+		h(payload, data)
+	}
+}
+
+// NotifyError 回调注册的 ErrorNotifyHandler
+func (d *Dispatcher) NotifyError(err error) {
+	for _, h := range d.registry.ErrorNotify {
+		h(err)
+	}
+	if h := d.handlers.ErrorNotify; h != nil {
+		h(err)
+	}
+}
@@ -0,0 +1,169 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tencent-connect/botgo/dto"
+)
+
+func TestDispatcher(t *testing.T) {
+	payload := &dto.WSPayload{
+		WSPayloadBase: dto.WSPayloadBase{
+			OPCode: dto.WSDispatchEvent,
+			Type:   dto.EventAtMessageCreate,
+		},
+		RawMessage: []byte(`{"op":0,"t":"AT_MESSAGE_CREATE","d":{"id":"1","content":"hello"}}`),
+	}
+
+	ctx := context.Background()
+	var first, second []string
+	d1 := NewDispatcher()
+	d2 := NewDispatcher()
+	d1.RegisterHandlers(ATMessageEventHandler(func(event *dto.WSPayload, data *dto.WSATMessageData) error {
+		first = append(first, data.Content)
+		return nil
+	}))
+	d2.RegisterHandlers(ATMessageEventHandler(func(event *dto.WSPayload, data *dto.WSATMessageData) error {
+		second = append(second, data.ID)
+		return nil
+	}))
+
+	t.Run("dispatchers are isolated", func(t *testing.T) {
+		assert.Nil(t, d1.ParseAndHandle(ctx, payload))
+		assert.Equal(t, []string{"hello"}, first)
+		assert.Empty(t, second)
+
+		assert.Nil(t, d2.ParseAndHandle(ctx, payload))
+		assert.Equal(t, []string{"1"}, second)
+		assert.Len(t, first, 1)
+	})
+	t.Run("default dispatcher untouched", func(t *testing.T) {
+		assert.Nil(t, DefaultHandlers.ATMessage)
+	})
+}
+
+func TestDispatcher_HandlerErrors(t *testing.T) {
+	payload := &dto.WSPayload{
+		WSPayloadBase: dto.WSPayloadBase{
+			OPCode: dto.WSDispatchEvent,
+			Type:   dto.EventAtMessageCreate,
+		},
+		RawMessage: []byte(`{"op":0,"t":"AT_MESSAGE_CREATE","d":{"id":"1","content":"hello"}}`),
+	}
+	ctx := context.Background()
+	errFirst, errLegacy := errors.New("first"), errors.New("legacy")
+	var trace []string
+	d := NewDispatcher()
+	d.RegisterHandlers(
+		ATMessageEventHandler(func(event *dto.WSPayload, data *dto.WSATMessageData) error {
+			trace = append(trace, "first")
+			return errFirst
+		}),
+		ATMessageEventHandler(func(event *dto.WSPayload, data *dto.WSATMessageData) error {
+			trace = append(trace, "second")
+			return nil
+		}),
+	)
+
+	// 某个 handler 返回错误时，后续的 handler 仍然执行
+	assert.Equal(t, errFirst, d.ParseAndHandle(ctx, payload))
+	assert.Equal(t, []string{"first", "second"}, trace)
+
+	// 兼容直接赋值的 handler，在注册的 handler 之后执行，错误合并返回
+	trace = nil
+	d.handlers.ATMessage = func(event *dto.WSPayload, data *dto.WSATMessageData) error {
+		trace = append(trace, "legacy")
+		return errLegacy
+	}
+	err := d.ParseAndHandle(ctx, payload)
+	assert.Equal(t, HandlerErrors{errFirst, errLegacy}, err)
+	assert.Equal(t, "2 handlers failed, first; legacy", err.Error())
+	assert.Equal(t, []string{"first", "second", "legacy"}, trace)
+}
+
+func TestDispatcher_Middleware(t *testing.T) {
+	payload := &dto.WSPayload{
+		WSPayloadBase: dto.WSPayloadBase{
+			OPCode: dto.WSDispatchEvent,
+			Type:   dto.EventAtMessageCreate,
+		},
+		RawMessage: []byte(`{"op":0,"t":"AT_MESSAGE_CREATE","d":{"id":"1","content":"hello"}}`),
+	}
+
+	ctx := context.Background()
+	var trace []string
+	d := NewDispatcher()
+	d.RegisterHandlers(
+		ATMessageEventHandler(func(event *dto.WSPayload, data *dto.WSATMessageData) error {
+			trace = append(trace, "handler1")
+			return nil
+		}),
+		ATMessageEventHandler(func(event *dto.WSPayload, data *dto.WSATMessageData) error {
+			trace = append(trace, "handler2")
+			return nil
+		}),
+	)
+	var blocked bool
+	d.Use(
+		func(ctx context.Context, payload *dto.WSPayload, next HandlerFunc) error {
+			trace = append(trace, "outer")
+			return next(ctx, payload)
+		},
+		func(ctx context.Context, payload *dto.WSPayload, next HandlerFunc) error {
+			trace = append(trace, "inner")
+			if blocked {
+				return nil
+			}
+			return next(ctx, payload)
+		},
+	)
+
+	t.Run("all handlers in order", func(t *testing.T) {
+		assert.Nil(t, d.ParseAndHandle(ctx, payload))
+		assert.Equal(t, []string{"outer", "inner", "handler1", "handler2"}, trace)
+	})
+	t.Run("middleware stops propagation", func(t *testing.T) {
+		trace, blocked = nil, true
+		assert.Nil(t, d.ParseAndHandle(ctx, payload))
+		assert.Equal(t, []string{"outer", "inner"}, trace)
+	})
+	t.Run("recovery", func(t *testing.T) {
+		r := NewDispatcher()
+		r.Use(Recovery())
+		r.RegisterHandlers(ATMessageEventHandler(func(event *dto.WSPayload, data *dto.WSATMessageData) error {
+			panic("boom")
+		}))
+		assert.NotNil(t, r.ParseAndHandle(ctx, payload))
+	})
+}
+
+func TestDispatcher_Context(t *testing.T) {
+	payload := &dto.WSPayload{
+		WSPayloadBase: dto.WSPayloadBase{
+			OPCode: dto.WSDispatchEvent,
+			Type:   dto.EventAtMessageCreate,
+			ID:     "AT_MESSAGE_CREATE:1",
+		},
+		RawMessage: []byte(`{"op":0,"t":"AT_MESSAGE_CREATE","d":{"id":"1","content":"hello"}}`),
+	}
+	session := dto.Session{ID: "session", Shards: dto.ShardConfig{ShardID: 1, ShardCount: 2}}
+	ctx := WithTraceID(WithSession(context.Background(), session), payload.ID)
+
+	d := NewDispatcher().WithTimeout(time.Second)
+	d.RegisterHandlers(ATMessageEventContextHandler(
+		func(ctx context.Context, event *dto.WSPayload, data *dto.WSATMessageData) error {
+			shard, ok := ShardFromContext(ctx)
+			assert.True(t, ok)
+			assert.Equal(t, uint32(1), shard.ShardID)
+			assert.Equal(t, "AT_MESSAGE_CREATE:1", TraceIDFromContext(ctx))
+			_, hasDeadline := ctx.Deadline()
+			assert.True(t, hasDeadline)
+			return nil
+		},
+	))
+	assert.Nil(t, d.ParseAndHandle(ctx, payload))
+}
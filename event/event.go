@@ -1,7 +1,10 @@
 package event
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"strings"
 
 	"github.com/tencent-connect/botgo/dto"
 	"github.com/tidwall/gjson" // 由于回包的 d 类型不确定，gjson 用于从回包json中提取 d 并进行针对性的解析
@@ -65,19 +68,70 @@ var eventParseFuncMap = map[dto.OPCode]map[dto.EventType]eventParseFunc{
 	},
 }
 
-type eventParseFunc func(event *dto.WSPayload, message []byte) error
+type eventParseFunc func(ctx context.Context, d *Dispatcher, event *dto.WSPayload, message []byte) error
 
-// ParseAndHandle 处理回调事件
+// ParseAndHandle 使用默认分发器处理回调事件
 func ParseAndHandle(payload *dto.WSPayload) error {
+	return DefaultDispatcher.ParseAndHandle(context.Background(), payload)
+}
+
+// ParseAndHandle 处理回调事件，事件会先经过注册的中间件，再投递到具体的 handler
+// ctx 会透传给中间件与 context handler，如果分发器设置了超时时间，会在 ctx 上附加截止时间
+func (d *Dispatcher) ParseAndHandle(ctx context.Context, payload *dto.WSPayload) error {
+	if d.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.timeout)
+		defer cancel()
+	}
+	return d.chain(d.handle)(ctx, payload)
+}
+
+// handle 解析事件并投递到注册的 handler
+func (d *Dispatcher) handle(ctx context.Context, payload *dto.WSPayload) error {
 	// 指定类型的 handler
 	if h, ok := eventParseFuncMap[payload.OPCode][payload.Type]; ok {
-		return h(payload, payload.RawMessage)
+		return h(ctx, d, payload, payload.RawMessage)
 	}
 	// 透传handler，如果未注册具体类型的 handler，会统一投递到这个 handler
-	if DefaultHandlers.Plain != nil {
-		return DefaultHandlers.Plain(payload, payload.RawMessage)
+	var errs HandlerErrors
+	for _, h := range d.registry.Plain {
+		errs = errs.add(h(ctx, payload, payload.RawMessage))
+	}
+	if h := d.handlers.Plain; h != nil {
+		errs = errs.add(h(payload, payload.RawMessage))
+	}
+	return errs.err()
+}
+
+// HandlerErrors 同一个事件的多个 handler 返回的错误，按照 handler 执行的顺序排列
+// 某个 handler 返回错误时仍然会执行后续的 handler，只有一个 handler 返回错误时直接返回这个错误
+type HandlerErrors []error
+
+// Error 输出错误信息
+func (e HandlerErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("%d handlers failed, %s", len(e), strings.Join(msgs, "; "))
+}
+
+func (e HandlerErrors) add(err error) HandlerErrors {
+	if err == nil {
+		return e
+	}
+	return append(e, err)
+}
+
+func (e HandlerErrors) err() error {
+	switch len(e) {
+	case 0:
+		return nil
+	case 1:
+		return e[0]
+	default:
+		return e
 	}
-	return nil
 }
 
 // ParseData 解析数据
@@ -86,299 +140,407 @@ func ParseData(message []byte, target interface{}) error {
 	return json.Unmarshal([]byte(data.String()), target)
 }
 
-func guildHandler(payload *dto.WSPayload, message []byte) error {
+func guildHandler(ctx context.Context, d *Dispatcher, payload *dto.WSPayload, message []byte) error {
 	data := &dto.WSGuildData{}
 	if err := ParseData(message, data); err != nil {
 		return err
 	}
-	if DefaultHandlers.Guild != nil {
-		return DefaultHandlers.Guild(payload, data)
+	var errs HandlerErrors
+	for _, h := range d.registry.Guild {
+		errs = errs.add(h(ctx, payload, data))
+	}
+	if h := d.handlers.Guild; h != nil {
+		errs = errs.add(h(payload, data))
 	}
-	return nil
+	return errs.err()
 }
 
-func channelHandler(payload *dto.WSPayload, message []byte) error {
+func channelHandler(ctx context.Context, d *Dispatcher, payload *dto.WSPayload, message []byte) error {
 	data := &dto.WSChannelData{}
 	if err := ParseData(message, data); err != nil {
 		return err
 	}
-	if DefaultHandlers.Channel != nil {
-		return DefaultHandlers.Channel(payload, data)
+	var errs HandlerErrors
+	for _, h := range d.registry.Channel {
+		errs = errs.add(h(ctx, payload, data))
 	}
-	return nil
+	if h := d.handlers.Channel; h != nil {
+		errs = errs.add(h(payload, data))
+	}
+	return errs.err()
 }
 
-func guildMemberHandler(payload *dto.WSPayload, message []byte) error {
+func guildMemberHandler(ctx context.Context, d *Dispatcher, payload *dto.WSPayload, message []byte) error {
 	data := &dto.WSGuildMemberData{}
 	if err := ParseData(message, data); err != nil {
 		return err
 	}
-	if DefaultHandlers.GuildMember != nil {
-		return DefaultHandlers.GuildMember(payload, data)
+	var errs HandlerErrors
+	for _, h := range d.registry.GuildMember {
+		errs = errs.add(h(ctx, payload, data))
+	}
+	if h := d.handlers.GuildMember; h != nil {
+		errs = errs.add(h(payload, data))
 	}
-	return nil
+	return errs.err()
 }
 
-func messageHandler(payload *dto.WSPayload, message []byte) error {
+func messageHandler(ctx context.Context, d *Dispatcher, payload *dto.WSPayload, message []byte) error {
 	data := &dto.WSMessageData{}
 	if err := ParseData(message, data); err != nil {
 		return err
 	}
-	if DefaultHandlers.Message != nil {
-		return DefaultHandlers.Message(payload, data)
+	var errs HandlerErrors
+	for _, h := range d.registry.Message {
+		errs = errs.add(h(ctx, payload, data))
+	}
+	if h := d.handlers.Message; h != nil {
+		errs = errs.add(h(payload, data))
 	}
-	return nil
+	return errs.err()
 }
 
-func messageDeleteHandler(payload *dto.WSPayload, message []byte) error {
+func messageDeleteHandler(ctx context.Context, d *Dispatcher, payload *dto.WSPayload, message []byte) error {
 	data := &dto.WSMessageDeleteData{}
 	if err := ParseData(message, data); err != nil {
 		return err
 	}
-	if DefaultHandlers.MessageDelete != nil {
-		return DefaultHandlers.MessageDelete(payload, data)
+	var errs HandlerErrors
+	for _, h := range d.registry.MessageDelete {
+		errs = errs.add(h(ctx, payload, data))
 	}
-	return nil
+	if h := d.handlers.MessageDelete; h != nil {
+		errs = errs.add(h(payload, data))
+	}
+	return errs.err()
 }
 
-func messageReactionHandler(payload *dto.WSPayload, message []byte) error {
+func messageReactionHandler(ctx context.Context, d *Dispatcher, payload *dto.WSPayload, message []byte) error {
 	data := &dto.WSMessageReactionData{}
 	if err := ParseData(message, data); err != nil {
 		return err
 	}
-	if DefaultHandlers.MessageReaction != nil {
-		return DefaultHandlers.MessageReaction(payload, data)
+	var errs HandlerErrors
+	for _, h := range d.registry.MessageReaction {
+		errs = errs.add(h(ctx, payload, data))
+	}
+	if h := d.handlers.MessageReaction; h != nil {
+		errs = errs.add(h(payload, data))
 	}
-	return nil
+	return errs.err()
 }
 
-func atMessageHandler(payload *dto.WSPayload, message []byte) error {
+func atMessageHandler(ctx context.Context, d *Dispatcher, payload *dto.WSPayload, message []byte) error {
 	data := &dto.WSATMessageData{}
 	if err := ParseData(message, data); err != nil {
 		return err
 	}
-	if DefaultHandlers.ATMessage != nil {
-		return DefaultHandlers.ATMessage(payload, data)
+	var errs HandlerErrors
+	for _, h := range d.registry.ATMessage {
+		errs = errs.add(h(ctx, payload, data))
 	}
-	return nil
+	if h := d.handlers.ATMessage; h != nil {
+		errs = errs.add(h(payload, data))
+	}
+	return errs.err()
 }
 
-func publicMessageDeleteHandler(payload *dto.WSPayload, message []byte) error {
+func publicMessageDeleteHandler(ctx context.Context, d *Dispatcher, payload *dto.WSPayload, message []byte) error {
 	data := &dto.WSPublicMessageDeleteData{}
 	if err := ParseData(message, data); err != nil {
 		return err
 	}
-	if DefaultHandlers.PublicMessageDelete != nil {
-		return DefaultHandlers.PublicMessageDelete(payload, data)
+	var errs HandlerErrors
+	for _, h := range d.registry.PublicMessageDelete {
+		errs = errs.add(h(ctx, payload, data))
+	}
+	if h := d.handlers.PublicMessageDelete; h != nil {
+		errs = errs.add(h(payload, data))
 	}
-	return nil
+	return errs.err()
 }
 
-func directMessageHandler(payload *dto.WSPayload, message []byte) error {
+func directMessageHandler(ctx context.Context, d *Dispatcher, payload *dto.WSPayload, message []byte) error {
 	data := &dto.WSDirectMessageData{}
 	if err := ParseData(message, data); err != nil {
 		return err
 	}
-	if DefaultHandlers.DirectMessage != nil {
-		return DefaultHandlers.DirectMessage(payload, data)
+	var errs HandlerErrors
+	for _, h := range d.registry.DirectMessage {
+		errs = errs.add(h(ctx, payload, data))
+	}
+	if h := d.handlers.DirectMessage; h != nil {
+		errs = errs.add(h(payload, data))
 	}
-	return nil
+	return errs.err()
 }
 
-func directMessageDeleteHandler(payload *dto.WSPayload, message []byte) error {
+func directMessageDeleteHandler(ctx context.Context, d *Dispatcher, payload *dto.WSPayload, message []byte) error {
 	data := &dto.WSDirectMessageDeleteData{}
 	if err := ParseData(message, data); err != nil {
 		return err
 	}
-	if DefaultHandlers.DirectMessageDelete != nil {
-		return DefaultHandlers.DirectMessageDelete(payload, data)
+	var errs HandlerErrors
+	for _, h := range d.registry.DirectMessageDelete {
+		errs = errs.add(h(ctx, payload, data))
 	}
-	return nil
+	if h := d.handlers.DirectMessageDelete; h != nil {
+		errs = errs.add(h(payload, data))
+	}
+	return errs.err()
 }
 
-func audioHandler(payload *dto.WSPayload, message []byte) error {
+func audioHandler(ctx context.Context, d *Dispatcher, payload *dto.WSPayload, message []byte) error {
 	data := &dto.WSAudioData{}
 	if err := ParseData(message, data); err != nil {
 		return err
 	}
-	if DefaultHandlers.Audio != nil {
-		return DefaultHandlers.Audio(payload, data)
+	var errs HandlerErrors
+	for _, h := range d.registry.Audio {
+		errs = errs.add(h(ctx, payload, data))
+	}
+	if h := d.handlers.Audio; h != nil {
+		errs = errs.add(h(payload, data))
 	}
-	return nil
+	return errs.err()
 }
 
-func threadHandler(payload *dto.WSPayload, message []byte) error {
+func threadHandler(ctx context.Context, d *Dispatcher, payload *dto.WSPayload, message []byte) error {
 	data := &dto.WSThreadData{}
 	if err := ParseData(message, data); err != nil {
 		return err
 	}
-	if DefaultHandlers.Thread != nil {
-		return DefaultHandlers.Thread(payload, data)
+	var errs HandlerErrors
+	for _, h := range d.registry.Thread {
+		errs = errs.add(h(ctx, payload, data))
+	}
+	if h := d.handlers.Thread; h != nil {
+		errs = errs.add(h(payload, data))
 	}
-	return nil
+	return errs.err()
 }
 
-func postHandler(payload *dto.WSPayload, message []byte) error {
+func postHandler(ctx context.Context, d *Dispatcher, payload *dto.WSPayload, message []byte) error {
 	data := &dto.WSPostData{}
 	if err := ParseData(message, data); err != nil {
 		return err
 	}
-	if DefaultHandlers.Post != nil {
-		return DefaultHandlers.Post(payload, data)
+	var errs HandlerErrors
+	for _, h := range d.registry.Post {
+		errs = errs.add(h(ctx, payload, data))
 	}
-	return nil
+	if h := d.handlers.Post; h != nil {
+		errs = errs.add(h(payload, data))
+	}
+	return errs.err()
 }
 
-func replyHandler(payload *dto.WSPayload, message []byte) error {
+func replyHandler(ctx context.Context, d *Dispatcher, payload *dto.WSPayload, message []byte) error {
 	data := &dto.WSReplyData{}
 	if err := ParseData(message, data); err != nil {
 		return err
 	}
-	if DefaultHandlers.Reply != nil {
-		return DefaultHandlers.Reply(payload, data)
+	var errs HandlerErrors
+	for _, h := range d.registry.Reply {
+		errs = errs.add(h(ctx, payload, data))
+	}
+	if h := d.handlers.Reply; h != nil {
+		errs = errs.add(h(payload, data))
 	}
-	return nil
+	return errs.err()
 }
 
-func forumAuditHandler(payload *dto.WSPayload, message []byte) error {
+func forumAuditHandler(ctx context.Context, d *Dispatcher, payload *dto.WSPayload, message []byte) error {
 	data := &dto.WSForumAuditData{}
 	if err := ParseData(message, data); err != nil {
 		return err
 	}
-	if DefaultHandlers.ForumAudit != nil {
-		return DefaultHandlers.ForumAudit(payload, data)
+	var errs HandlerErrors
+	for _, h := range d.registry.ForumAudit {
+		errs = errs.add(h(ctx, payload, data))
+	}
+	if h := d.handlers.ForumAudit; h != nil {
+		errs = errs.add(h(payload, data))
 	}
-	return nil
+	return errs.err()
 }
 
-func messageAuditHandler(payload *dto.WSPayload, message []byte) error {
+func messageAuditHandler(ctx context.Context, d *Dispatcher, payload *dto.WSPayload, message []byte) error {
 	data := &dto.WSMessageAuditData{}
 	if err := ParseData(message, data); err != nil {
 		return err
 	}
-	if DefaultHandlers.MessageAudit != nil {
-		return DefaultHandlers.MessageAudit(payload, data)
+	var errs HandlerErrors
+	for _, h := range d.registry.MessageAudit {
+		errs = errs.add(h(ctx, payload, data))
 	}
-	return nil
+	if h := d.handlers.MessageAudit; h != nil {
+		errs = errs.add(h(payload, data))
+	}
+	return errs.err()
 }
 
-func interactionHandler(payload *dto.WSPayload, message []byte) error {
+func interactionHandler(ctx context.Context, d *Dispatcher, payload *dto.WSPayload, message []byte) error {
 	data := &dto.WSInteractionData{}
 	if err := ParseData(message, data); err != nil {
 		return err
 	}
-	if DefaultHandlers.Interaction != nil {
-		return DefaultHandlers.Interaction(payload, data)
+	var errs HandlerErrors
+	for _, h := range d.registry.Interaction {
+		errs = errs.add(h(ctx, payload, data))
+	}
+	if h := d.handlers.Interaction; h != nil {
+		errs = errs.add(h(payload, data))
 	}
-	return nil
+	return errs.err()
 }
 
-func chatFromUserHandler(payload *dto.WSPayload, message []byte) error {
+func chatFromUserHandler(ctx context.Context, d *Dispatcher, payload *dto.WSPayload, message []byte) error {
 	data := &dto.WSUserQuery{}
 	if err := ParseData(message, data); err != nil {
 		return err
 	}
-	if DefaultHandlers.UserQuery != nil {
-		return DefaultHandlers.UserQuery(payload, data)
+	var errs HandlerErrors
+	for _, h := range d.registry.UserQuery {
+		errs = errs.add(h(ctx, payload, data))
 	}
-	return nil
+	if h := d.handlers.UserQuery; h != nil {
+		errs = errs.add(h(payload, data))
+	}
+	return errs.err()
 }
 
-func chatFromGroupHandler(payload *dto.WSPayload, message []byte) error {
+func chatFromGroupHandler(ctx context.Context, d *Dispatcher, payload *dto.WSPayload, message []byte) error {
 	data := &dto.WSGroupAtMessage{}
 	if err := ParseData(message, data); err != nil {
 		return err
 	}
-	if DefaultHandlers.GroupAtMessage != nil {
-		return DefaultHandlers.GroupAtMessage(payload, data)
+	var errs HandlerErrors
+	for _, h := range d.registry.GroupAtMessage {
+		errs = errs.add(h(ctx, payload, data))
+	}
+	if h := d.handlers.GroupAtMessage; h != nil {
+		errs = errs.add(h(payload, data))
 	}
-	return nil
+	return errs.err()
 }
 
-func userAddBotHandler(payload *dto.WSPayload, message []byte) error {
+func userAddBotHandler(ctx context.Context, d *Dispatcher, payload *dto.WSPayload, message []byte) error {
 	data := &dto.WSUserAddBot{}
 	if err := ParseData(message, data); err != nil {
 		return err
 	}
-	if DefaultHandlers.UserAddBot != nil {
-		return DefaultHandlers.UserAddBot(payload, data)
+	var errs HandlerErrors
+	for _, h := range d.registry.UserAddBot {
+		errs = errs.add(h(ctx, payload, data))
+	}
+	if h := d.handlers.UserAddBot; h != nil {
+		errs = errs.add(h(payload, data))
 	}
-	return nil
+	return errs.err()
 }
 
-func userDelBotHandler(payload *dto.WSPayload, message []byte) error {
+func userDelBotHandler(ctx context.Context, d *Dispatcher, payload *dto.WSPayload, message []byte) error {
 	data := &dto.WSUserDelBot{}
 	if err := ParseData(message, data); err != nil {
 		return err
 	}
-	if DefaultHandlers.UserDelBot != nil {
-		return DefaultHandlers.UserDelBot(payload, data)
+	var errs HandlerErrors
+	for _, h := range d.registry.UserDelBot {
+		errs = errs.add(h(ctx, payload, data))
 	}
-	return nil
+	if h := d.handlers.UserDelBot; h != nil {
+		errs = errs.add(h(payload, data))
+	}
+	return errs.err()
 }
 
-func userReciveMessageHandler(payload *dto.WSPayload, message []byte) error {
+func userReciveMessageHandler(ctx context.Context, d *Dispatcher, payload *dto.WSPayload, message []byte) error {
 	data := &dto.WSUserReciveMessage{}
 	if err := ParseData(message, data); err != nil {
 		return err
 	}
-	if DefaultHandlers.UserReciveMessage != nil {
-		return DefaultHandlers.UserReciveMessage(payload, data)
+	var errs HandlerErrors
+	for _, h := range d.registry.UserReciveMessage {
+		errs = errs.add(h(ctx, payload, data))
+	}
+	if h := d.handlers.UserReciveMessage; h != nil {
+		errs = errs.add(h(payload, data))
 	}
-	return nil
+	return errs.err()
 }
 
-func userRejectMessageHandler(payload *dto.WSPayload, message []byte) error {
+func userRejectMessageHandler(ctx context.Context, d *Dispatcher, payload *dto.WSPayload, message []byte) error {
 	data := &dto.WSUserRejectMessage{}
 	if err := ParseData(message, data); err != nil {
 		return err
 	}
-	if DefaultHandlers.UserRejectMessage != nil {
-		return DefaultHandlers.UserRejectMessage(payload, data)
+	var errs HandlerErrors
+	for _, h := range d.registry.UserRejectMessage {
+		errs = errs.add(h(ctx, payload, data))
 	}
-	return nil
+	if h := d.handlers.UserRejectMessage; h != nil {
+		errs = errs.add(h(payload, data))
+	}
+	return errs.err()
 }
 
-func groupReciveMessageHandler(payload *dto.WSPayload, message []byte) error {
+func groupReciveMessageHandler(ctx context.Context, d *Dispatcher, payload *dto.WSPayload, message []byte) error {
 	data := &dto.WSGroupReciveMessage{}
 	if err := ParseData(message, data); err != nil {
 		return err
 	}
-	if DefaultHandlers.GroupReciveMessage != nil {
-		return DefaultHandlers.GroupReciveMessage(payload, data)
+	var errs HandlerErrors
+	for _, h := range d.registry.GroupReciveMessage {
+		errs = errs.add(h(ctx, payload, data))
+	}
+	if h := d.handlers.GroupReciveMessage; h != nil {
+		errs = errs.add(h(payload, data))
 	}
-	return nil
+	return errs.err()
 }
 
-func groupRejectMessageHandler(payload *dto.WSPayload, message []byte) error {
+func groupRejectMessageHandler(ctx context.Context, d *Dispatcher, payload *dto.WSPayload, message []byte) error {
 	data := &dto.WSGroupRejectMessage{}
 	if err := ParseData(message, data); err != nil {
 		return err
 	}
-	if DefaultHandlers.GroupRejectMessage != nil {
-		return DefaultHandlers.GroupRejectMessage(payload, data)
+	var errs HandlerErrors
+	for _, h := range d.registry.GroupRejectMessage {
+		errs = errs.add(h(ctx, payload, data))
+	}
+	if h := d.handlers.GroupRejectMessage; h != nil {
+		errs = errs.add(h(payload, data))
 	}
-	return nil
+	return errs.err()
 }
 
-func groupAddBotHandler(payload *dto.WSPayload, message []byte) error {
+func groupAddBotHandler(ctx context.Context, d *Dispatcher, payload *dto.WSPayload, message []byte) error {
 	data := &dto.WSAddGroup{}
 	if err := ParseData(message, data); err != nil {
 		return err
 	}
-	if DefaultHandlers.AddGroup != nil {
-		return DefaultHandlers.AddGroup(payload, data)
+	var errs HandlerErrors
+	for _, h := range d.registry.AddGroup {
+		errs = errs.add(h(ctx, payload, data))
 	}
-	return nil
+	if h := d.handlers.AddGroup; h != nil {
+		errs = errs.add(h(payload, data))
+	}
+	return errs.err()
 }
 
-func groupDelBotHandler(payload *dto.WSPayload, message []byte) error {
+func groupDelBotHandler(ctx context.Context, d *Dispatcher, payload *dto.WSPayload, message []byte) error {
 	data := &dto.WSQuitGroup{}
 	if err := ParseData(message, data); err != nil {
 		return err
 	}
-	if DefaultHandlers.QuitGroup != nil {
-		return DefaultHandlers.QuitGroup(payload, data)
+	var errs HandlerErrors
+	for _, h := range d.registry.QuitGroup {
+		errs = errs.add(h(ctx, payload, data))
+	}
+	if h := d.handlers.QuitGroup; h != nil {
+		errs = errs.add(h(payload, data))
 	}
-	return nil
+	return errs.err()
 }
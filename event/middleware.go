@@ -0,0 +1,86 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/tencent-connect/botgo/dto"
+	"github.com/tencent-connect/botgo/log"
+)
+
+// HandlerFunc 事件处理函数，中间件通过它将事件交给后续的中间件或 handler
+type HandlerFunc func(ctx context.Context, payload *dto.WSPayload) error
+
+// Middleware 事件中间件，包裹在 ParseAndHandle 外层，可以用于日志，异常恢复，鉴权，监控上报等
+// 调用 next 会将事件交给后续的中间件与 handler 处理，不调用 next 则中断事件的传播
+type Middleware func(ctx context.Context, payload *dto.WSPayload, next HandlerFunc) error
+
+// MetricsReporter 事件处理结果上报函数
+type MetricsReporter func(payload *dto.WSPayload, cost time.Duration, err error)
+
+// Use 为默认分发器注册中间件
+func Use(middlewares ...Middleware) {
+	DefaultDispatcher.Use(middlewares...)
+}
+
+// Use 注册中间件，按照注册顺序执行，先注册的中间件在更外层
+func (d *Dispatcher) Use(middlewares ...Middleware) {
+	d.middlewares = append(d.middlewares, middlewares...)
+}
+
+// chain 将中间件与最终的处理函数组装为调用链
+func (d *Dispatcher) chain(final HandlerFunc) HandlerFunc {
+	h := final
+	for i := len(d.middlewares) - 1; i >= 0; i-- {
+		m, next := d.middlewares[i], h
+		h = func(ctx context.Context, payload *dto.WSPayload) error {
+			return m(ctx, payload, next)
+		}
+	}
+	return h
+}
+
+// PanicBufLen panic 堆栈大小
+var PanicBufLen = 1024
+
+// Recovery 捕获 handler 中的 panic，打印堆栈并转换为错误返回，避免单个事件导致连接断开
+func Recovery() Middleware {
+	return func(ctx context.Context, payload *dto.WSPayload, next HandlerFunc) (err error) {
+		defer func() {
+			if e := recover(); e != nil {
+				buf := make([]byte, PanicBufLen)
+				buf = buf[:runtime.Stack(buf, false)]
+				log.Errorf("[PANIC]event %s, seq %d\n%v\n%s\n", payload.Type, payload.Seq, e, buf)
+				err = fmt.Errorf("panic: %v", e)
+			}
+		}()
+		return next(ctx, payload)
+	}
+}
+
+// Logging 打印每个事件的处理耗时与结果
+func Logging() Middleware {
+	return func(ctx context.Context, payload *dto.WSPayload, next HandlerFunc) error {
+		start := time.Now()
+		err := next(ctx, payload)
+		if err != nil {
+			log.Errorf("[event] handle %s failed, seq: %d, cost: %s, err: %v",
+				payload.Type, payload.Seq, time.Since(start), err)
+			return err
+		}
+		log.Debugf("[event] handle %s success, seq: %d, cost: %s", payload.Type, payload.Seq, time.Since(start))
+		return nil
+	}
+}
+
+// Metrics 将每个事件的处理耗时与结果交给 reporter 上报
+func Metrics(reporter MetricsReporter) Middleware {
+	return func(ctx context.Context, payload *dto.WSPayload, next HandlerFunc) error {
+		start := time.Now()
+		err := next(ctx, payload)
+		reporter(payload, time.Since(start), err)
+		return err
+	}
+}
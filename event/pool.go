@@ -0,0 +1,202 @@
+package event
+
+import (
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tencent-connect/botgo/dto"
+	"github.com/tidwall/gjson"
+)
+
+// DefaultWorkerQueueSize 每个 worker 默认的队列长度
+const DefaultWorkerQueueSize = 100
+
+// orderKeyPaths 计算事件顺序 key 时依次尝试的字段，相同 key 的事件按照收到的顺序处理
+var orderKeyPaths = []string{
+	"d.group_openid",       // 群聊
+	"d.channel_id",         // 子频道与私信
+	"d.author.user_openid", // 单聊
+	"d.openid",             // 单聊的用户事件
+	"d.guild_id",           // 频道
+}
+
+// OrderKey 返回事件的顺序 key，依次使用群 openid，子频道 ID，用户 openid，频道 ID，都不存在时返回事件类型
+func OrderKey(payload *dto.WSPayload) string {
+	message := string(payload.RawMessage)
+	for _, path := range orderKeyPaths {
+		if key := gjson.Get(message, path).String(); key != "" {
+			return path + ":" + key
+		}
+	}
+	return string(payload.Type)
+}
+
+// PoolStats 事件处理池的统计信息，用于观察积压与背压
+type PoolStats struct {
+	Workers     int           // worker 数量
+	Submitted   uint64        // 提交的事件数
+	Completed   uint64        // 处理完成的事件数
+	Pending     int64         // 已提交未处理完成的事件数，包括正在处理的事件
+	Blocked     uint64        // 处理池的容量用尽，提交被阻塞的次数
+	BlockedTime time.Duration // 提交被阻塞的总时长
+}
+
+// poolMetrics 处理池的计数，同一个 Dispatcher 创建的处理池共用一份
+type poolMetrics struct {
+	workers     int64
+	submitted   uint64
+	completed   uint64
+	pending     int64
+	blocked     uint64
+	blockedTime int64
+}
+
+func (m *poolMetrics) stats() PoolStats {
+	return PoolStats{
+		Workers:     int(atomic.LoadInt64(&m.workers)),
+		Submitted:   atomic.LoadUint64(&m.submitted),
+		Completed:   atomic.LoadUint64(&m.completed),
+		Pending:     atomic.LoadInt64(&m.pending),
+		Blocked:     atomic.LoadUint64(&m.blocked),
+		BlockedTime: time.Duration(atomic.LoadInt64(&m.blockedTime)),
+	}
+}
+
+// OrderedPool 按照 key 保序的并发处理池
+// 相同 key 的任务由同一个 worker 按照提交的顺序串行处理，不同 key 的任务并发处理
+// 所有 worker 共用 workers*queueSize 的容量，某个 key 积压时可以使用其他 worker 空闲的容量，不会阻塞其他 key 的提交
+// 整个处理池的容量用尽时 Submit 才会阻塞，从而将背压传递给上游。websocket 连接上游的读协程随之停止读取，
+// 此时无法读取心跳 ack，连接会暂停心跳超时的检测，直到恢复读取，见 websocket/client
+type OrderedPool struct {
+	workers   []*orderedWorker
+	slots     chan struct{} // 处理池的容量，提交任务时占用，任务处理完成后释放
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+	metrics   *poolMetrics
+}
+
+// orderedWorker 一个 worker 的任务队列，队列长度由处理池的容量限制
+type orderedWorker struct {
+	lock   sync.Mutex
+	tasks  []func()
+	closed bool
+	notify chan struct{}
+}
+
+// NewOrderedPool 创建处理池，workers 小于 1 时为 1，queueSize 小于 1 时使用 DefaultWorkerQueueSize
+func NewOrderedPool(workers, queueSize int) *OrderedPool {
+	return newOrderedPool(workers, queueSize, &poolMetrics{})
+}
+
+func newOrderedPool(workers, queueSize int, metrics *poolMetrics) *OrderedPool {
+	if workers < 1 {
+		workers = 1
+	}
+	if queueSize < 1 {
+		queueSize = DefaultWorkerQueueSize
+	}
+	p := &OrderedPool{
+		workers: make([]*orderedWorker, workers),
+		slots:   make(chan struct{}, workers*queueSize),
+		metrics: metrics,
+	}
+	atomic.AddInt64(&metrics.workers, int64(workers))
+	for i := range p.workers {
+		p.workers[i] = &orderedWorker{notify: make(chan struct{}, 1)}
+		p.wg.Add(1)
+		go p.work(p.workers[i])
+	}
+	return p
+}
+
+// Submit 提交任务，处理池的容量用尽时阻塞等待，Close 之后不能再提交
+func (p *OrderedPool) Submit(key string, task func()) {
+	atomic.AddUint64(&p.metrics.submitted, 1)
+	atomic.AddInt64(&p.metrics.pending, 1)
+	select {
+	case p.slots <- struct{}{}:
+	default:
+		start := time.Now()
+		p.slots <- struct{}{}
+		atomic.AddUint64(&p.metrics.blocked, 1)
+		atomic.AddInt64(&p.metrics.blockedTime, int64(time.Since(start)))
+	}
+	p.workers[p.index(key)].push(task)
+}
+
+// Close 停止接收任务，并等待已经提交的任务处理完成
+func (p *OrderedPool) Close() {
+	p.closeOnce.Do(func() {
+		for _, w := range p.workers {
+			w.close()
+		}
+	})
+	p.wg.Wait()
+}
+
+// Stats 返回统计信息，由 Dispatcher 创建的处理池返回的是该 Dispatcher 所有处理池的汇总
+func (p *OrderedPool) Stats() PoolStats {
+	return p.metrics.stats()
+}
+
+func (p *OrderedPool) work(w *orderedWorker) {
+	defer p.wg.Done()
+	defer atomic.AddInt64(&p.metrics.workers, -1)
+	for {
+		task, ok := w.pop()
+		if !ok {
+			return
+		}
+		task()
+		<-p.slots
+		atomic.AddUint64(&p.metrics.completed, 1)
+		atomic.AddInt64(&p.metrics.pending, -1)
+	}
+}
+
+func (p *OrderedPool) index(key string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return int(h.Sum32() % uint32(len(p.workers)))
+}
+
+func (w *orderedWorker) push(task func()) {
+	w.lock.Lock()
+	w.tasks = append(w.tasks, task)
+	w.lock.Unlock()
+	w.wake()
+}
+
+func (w *orderedWorker) close() {
+	w.lock.Lock()
+	w.closed = true
+	w.lock.Unlock()
+	w.wake()
+}
+
+func (w *orderedWorker) wake() {
+	select {
+	case w.notify <- struct{}{}:
+	default:
+	}
+}
+
+// pop 取出下一个任务，队列为空时等待，关闭并且处理完所有任务后返回 false
+func (w *orderedWorker) pop() (func(), bool) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	for len(w.tasks) == 0 {
+		if w.closed {
+			return nil, false
+		}
+		w.lock.Unlock()
+		<-w.notify
+		w.lock.Lock()
+	}
+	task := w.tasks[0]
+	w.tasks[0] = nil
+	w.tasks = w.tasks[1:]
+	return task, true
+}
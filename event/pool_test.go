@@ -0,0 +1,128 @@
+package event
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tencent-connect/botgo/dto"
+	"github.com/tidwall/gjson"
+)
+
+func TestOrderedPool(t *testing.T) {
+	t.Run("keeps order within a key", func(t *testing.T) {
+		pool := NewOrderedPool(4, 2)
+		var lock sync.Mutex
+		got := map[string][]int{}
+		for i := 0; i < 50; i++ {
+			for _, key := range []string{"a", "b", "c"} {
+				key, i := key, i
+				pool.Submit(key, func() {
+					lock.Lock()
+					defer lock.Unlock()
+					got[key] = append(got[key], i)
+				})
+			}
+		}
+		pool.Close()
+		for _, key := range []string{"a", "b", "c"} {
+			assert.Len(t, got[key], 50)
+			for i, v := range got[key] {
+				assert.Equal(t, i, v, key)
+			}
+		}
+		stats := pool.Stats()
+		assert.Equal(t, uint64(150), stats.Submitted)
+		assert.Equal(t, uint64(150), stats.Completed)
+		assert.Equal(t, int64(0), stats.Pending)
+		assert.Equal(t, 0, stats.Workers)
+	})
+	t.Run("slow key does not block others", func(t *testing.T) {
+		pool := NewOrderedPool(2, 1)
+		defer pool.Close()
+		release := make(chan struct{})
+		// 找到与 slow 分配到不同 worker 的 key
+		fast := ""
+		for i := 0; fast == ""; i++ {
+			if key := fmt.Sprint(i); pool.index(key) != pool.index("slow") {
+				fast = key
+			}
+		}
+		started := make(chan struct{})
+		pool.Submit("slow", func() {
+			close(started)
+			<-release
+		})
+		<-started
+		done := make(chan struct{})
+		pool.Submit(fast, func() { close(done) })
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("fast key is blocked by slow key")
+		}
+		// slow worker 的队列已满，继续提交会阻塞，直到 worker 处理完成
+		pool.Submit("slow", func() {})
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			close(release)
+		}()
+		pool.Submit("slow", func() {})
+		assert.Equal(t, uint64(1), pool.Stats().Blocked)
+		assert.True(t, pool.Stats().BlockedTime > 0)
+	})
+	t.Run("busy key uses shared capacity", func(t *testing.T) {
+		pool := NewOrderedPool(2, 2)
+		release := make(chan struct{})
+		var got []int
+		pool.Submit("busy", func() { <-release })
+		// 一个 worker 积压时可以使用整个处理池的容量，不会阻塞提交
+		for i := 0; i < 3; i++ {
+			i := i
+			pool.Submit("busy", func() { got = append(got, i) })
+		}
+		assert.Equal(t, uint64(0), pool.Stats().Blocked)
+		close(release)
+		pool.Close()
+		assert.Equal(t, []int{0, 1, 2}, got)
+	})
+}
+
+func TestDispatcher_NewPool(t *testing.T) {
+	d := NewDispatcher().WithQueueSize(10)
+	assert.Equal(t, 10, d.QueueSize())
+	assert.Nil(t, d.NewPool())
+
+	d.WithWorkers(3, 0)
+	p1, p2 := d.NewPool(), d.NewPool()
+	assert.Equal(t, 6, d.PoolStats().Workers)
+	p1.Submit("a", func() {})
+	p2.Submit("b", func() {})
+	p1.Close()
+	p2.Close()
+	stats := d.PoolStats()
+	assert.Equal(t, 0, stats.Workers)
+	assert.Equal(t, uint64(2), stats.Completed)
+}
+
+func TestOrderKey(t *testing.T) {
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{`{"t":"GROUP_AT_MESSAGE_CREATE","d":{"group_openid":"group","author":{"member_openid":"m"}}}`,
+			"d.group_openid:group"},
+		{`{"t":"AT_MESSAGE_CREATE","d":{"channel_id":"channel","guild_id":"guild"}}`, "d.channel_id:channel"},
+		{`{"t":"C2C_MESSAGE_CREATE","d":{"author":{"user_openid":"user"}}}`, "d.author.user_openid:user"},
+		{`{"t":"FRIEND_ADD","d":{"openid":"user"}}`, "d.openid:user"},
+		{`{"t":"GUILD_MEMBER_ADD","d":{"guild_id":"guild"}}`, "d.guild_id:guild"},
+		{`{"t":"RESUMED","d":""}`, "RESUMED"},
+	}
+	for _, tt := range tests {
+		payload := &dto.WSPayload{RawMessage: []byte(tt.message)}
+		payload.Type = dto.EventType(gjson.Get(tt.message, "t").String())
+		assert.Equal(t, tt.want, OrderKey(payload))
+	}
+}
@@ -4,8 +4,9 @@ import (
 	"github.com/tencent-connect/botgo/dto"
 )
 
-// DefaultHandlers 默认的 handler 结构，管理所有支持的 handler 类型
-var DefaultHandlers struct {
+// Handlers handler 表结构，兼容直接赋值注册 handler 的方式，如 event.DefaultHandlers.ATMessage = handler
+// 直接赋值的 handler 在通过 RegisterHandlers 注册的 handler 之后执行，不会计算 intent，需要同时通过 RegisterHandlers 获取 intent
+type Handlers struct {
 	Ready       ReadyHandler
 	ErrorNotify ErrorNotifyHandler
 	Plain       PlainEventHandler
@@ -44,6 +45,47 @@ var DefaultHandlers struct {
 	GroupReciveMessage GroupReciveMessageEventHandler
 }
 
+// handlerRegistry 通过 RegisterHandlers 注册的 handler
+// 每种类型可以注册多个 handler，按照注册顺序执行，某个 handler 返回错误时仍然执行后续的 handler
+type handlerRegistry struct {
+	Ready       []ReadyHandler
+	ErrorNotify []ErrorNotifyHandler
+	Plain       []PlainEventContextHandler
+
+	Guild       []GuildEventContextHandler
+	GuildMember []GuildMemberEventContextHandler
+	Channel     []ChannelEventContextHandler
+
+	Message             []MessageEventContextHandler
+	MessageReaction     []MessageReactionEventContextHandler
+	ATMessage           []ATMessageEventContextHandler
+	DirectMessage       []DirectMessageEventContextHandler
+	MessageAudit        []MessageAuditEventContextHandler
+	MessageDelete       []MessageDeleteEventContextHandler
+	PublicMessageDelete []PublicMessageDeleteEventContextHandler
+	DirectMessageDelete []DirectMessageDeleteEventContextHandler
+
+	Audio []AudioEventContextHandler
+
+	Thread     []ThreadEventContextHandler
+	Post       []PostEventContextHandler
+	Reply      []ReplyEventContextHandler
+	ForumAudit []ForumAuditEventContextHandler
+
+	Interaction []InteractionEventContextHandler
+
+	UserQuery          []UserQueryEventContextHandler
+	GroupAtMessage     []GroupAtMessageEventContextHandler
+	UserAddBot         []UserAddBotEventContextHandler
+	UserDelBot         []UserDelBotEventContextHandler
+	UserRejectMessage  []UserRejectMessageEventContextHandler
+	UserReciveMessage  []UserReciveMessageEventContextHandler
+	AddGroup           []AddGroupEventContextHandler
+	QuitGroup          []QuitGroupEventContextHandler
+	GroupRejectMessage []GroupRejectMessageEventContextHandler
+	GroupReciveMessage []GroupReciveMessageEventContextHandler
+}
+
 // ReadyHandler 可以处理 ws 的 ready 事件
 type ReadyHandler func(event *dto.WSPayload, data *dto.WSReadyData)
 
@@ -135,53 +177,66 @@ type GroupRejectMessageEventHandler func(event *dto.WSPayload, data *dto.WSGroup
 // GroupReciveMessageEventHandler 群聊接受主动消息事件 handler
 type GroupReciveMessageEventHandler func(event *dto.WSPayload, data *dto.WSGroupReciveMessage) error
 
-// RegisterHandlers 注册事件回调，并返回 intent 用于 websocket 的鉴权
+// RegisterHandlers 注册事件回调到默认分发器，并返回 intent 用于 websocket 的鉴权
 func RegisterHandlers(handlers ...interface{}) dto.Intent {
+	return DefaultDispatcher.RegisterHandlers(handlers...)
+}
+
+// RegisterHandlers 注册事件回调，并返回 intent 用于 websocket 的鉴权
+// 同一类型的 handler 可以多次注册，不会覆盖之前注册的 handler，支持注册不带 context 与带 context 的 handler
+func (d *Dispatcher) RegisterHandlers(handlers ...interface{}) dto.Intent {
+	// 统一转换为 context handler 存储
+	converted := make([]interface{}, 0, len(handlers))
+	for _, h := range handlers {
+		converted = append(converted, withContext(h))
+	}
+	handlers = converted
+
 	var i dto.Intent
 	for _, h := range handlers {
 		switch handle := h.(type) {
 		case ReadyHandler:
-			DefaultHandlers.Ready = handle
+			d.registry.Ready = append(d.registry.Ready, handle)
 		case ErrorNotifyHandler:
-			DefaultHandlers.ErrorNotify = handle
-		case PlainEventHandler:
-			DefaultHandlers.Plain = handle
-		case AudioEventHandler:
-			DefaultHandlers.Audio = handle
+			d.registry.ErrorNotify = append(d.registry.ErrorNotify, handle)
+		case PlainEventContextHandler:
+			d.registry.Plain = append(d.registry.Plain, handle)
+		case AudioEventContextHandler:
+			d.registry.Audio = append(d.registry.Audio, handle)
 			i = i | dto.EventToIntent(
 				dto.EventAudioStart, dto.EventAudioFinish,
 				dto.EventAudioOnMic, dto.EventAudioOffMic,
 			)
-		case InteractionEventHandler:
-			DefaultHandlers.Interaction = handle
+		case InteractionEventContextHandler:
+			d.registry.Interaction = append(d.registry.Interaction, handle)
 			i = i | dto.EventToIntent(dto.EventInteractionCreate)
 		default:
 		}
 	}
-	i = i | registerRelationHandlers(i, handlers...)
-	i = i | registerMessageHandlers(i, handlers...)
-	i = i | registerForumHandlers(i, handlers...)
+	i = i | d.registerRelationHandlers(i, handlers...)
+	i = i | d.registerMessageHandlers(i, handlers...)
+	i = i | d.registerForumHandlers(i, handlers...)
 
 	return i
 }
 
 // registerForumHandlers 注册论坛关系链相关handlers
-func registerForumHandlers(i dto.Intent, handlers ...interface{}) dto.Intent {
+func (d *Dispatcher) registerForumHandlers(i dto.Intent, handlers ...interface{}) dto.Intent {
 	for _, h := range handlers {
 		switch handle := h.(type) {
-		case ThreadEventHandler:
-			DefaultHandlers.Thread = handle
+		case ThreadEventContextHandler:
+			d.registry.Thread = append(d.registry.Thread, handle)
 			i = i | dto.EventToIntent(
 				dto.EventForumThreadCreate, dto.EventForumThreadUpdate, dto.EventForumThreadDelete,
 			)
-		case PostEventHandler:
-			DefaultHandlers.Post = handle
+		case PostEventContextHandler:
+			d.registry.Post = append(d.registry.Post, handle)
 			i = i | dto.EventToIntent(dto.EventForumPostCreate, dto.EventForumPostDelete)
-		case ReplyEventHandler:
-			DefaultHandlers.Reply = handle
+		case ReplyEventContextHandler:
+			d.registry.Reply = append(d.registry.Reply, handle)
 			i = i | dto.EventToIntent(dto.EventForumReplyCreate, dto.EventForumReplyDelete)
-		case ForumAuditEventHandler:
-			DefaultHandlers.ForumAudit = handle
+		case ForumAuditEventContextHandler:
+			d.registry.ForumAudit = append(d.registry.ForumAudit, handle)
 			i = i | dto.EventToIntent(dto.EventForumAuditResult)
 		default:
 		}
@@ -190,17 +245,17 @@ func registerForumHandlers(i dto.Intent, handlers ...interface{}) dto.Intent {
 }
 
 // registerRelationHandlers 注册频道关系链相关handlers
-func registerRelationHandlers(i dto.Intent, handlers ...interface{}) dto.Intent {
+func (d *Dispatcher) registerRelationHandlers(i dto.Intent, handlers ...interface{}) dto.Intent {
 	for _, h := range handlers {
 		switch handle := h.(type) {
-		case GuildEventHandler:
-			DefaultHandlers.Guild = handle
+		case GuildEventContextHandler:
+			d.registry.Guild = append(d.registry.Guild, handle)
 			i = i | dto.EventToIntent(dto.EventGuildCreate, dto.EventGuildDelete, dto.EventGuildUpdate)
-		case GuildMemberEventHandler:
-			DefaultHandlers.GuildMember = handle
+		case GuildMemberEventContextHandler:
+			d.registry.GuildMember = append(d.registry.GuildMember, handle)
 			i = i | dto.EventToIntent(dto.EventGuildMemberAdd, dto.EventGuildMemberRemove, dto.EventGuildMemberUpdate)
-		case ChannelEventHandler:
-			DefaultHandlers.Channel = handle
+		case ChannelEventContextHandler:
+			d.registry.Channel = append(d.registry.Channel, handle)
 			i = i | dto.EventToIntent(dto.EventChannelCreate, dto.EventChannelDelete, dto.EventChannelUpdate)
 		default:
 		}
@@ -209,63 +264,63 @@ func registerRelationHandlers(i dto.Intent, handlers ...interface{}) dto.Intent
 }
 
 // registerMessageHandlers 注册消息相关的 handler
-func registerMessageHandlers(i dto.Intent, handlers ...interface{}) dto.Intent {
+func (d *Dispatcher) registerMessageHandlers(i dto.Intent, handlers ...interface{}) dto.Intent {
 	for _, h := range handlers {
 		switch handle := h.(type) {
-		case MessageEventHandler:
-			DefaultHandlers.Message = handle
+		case MessageEventContextHandler:
+			d.registry.Message = append(d.registry.Message, handle)
 			i = i | dto.EventToIntent(dto.EventMessageCreate)
-		case ATMessageEventHandler:
-			DefaultHandlers.ATMessage = handle
+		case ATMessageEventContextHandler:
+			d.registry.ATMessage = append(d.registry.ATMessage, handle)
 			i = i | dto.EventToIntent(dto.EventAtMessageCreate)
-		case DirectMessageEventHandler:
-			DefaultHandlers.DirectMessage = handle
+		case DirectMessageEventContextHandler:
+			d.registry.DirectMessage = append(d.registry.DirectMessage, handle)
 			i = i | dto.EventToIntent(dto.EventDirectMessageCreate)
-		case MessageDeleteEventHandler:
-			DefaultHandlers.MessageDelete = handle
+		case MessageDeleteEventContextHandler:
+			d.registry.MessageDelete = append(d.registry.MessageDelete, handle)
 			i = i | dto.EventToIntent(dto.EventMessageDelete)
-		case PublicMessageDeleteEventHandler:
-			DefaultHandlers.PublicMessageDelete = handle
+		case PublicMessageDeleteEventContextHandler:
+			d.registry.PublicMessageDelete = append(d.registry.PublicMessageDelete, handle)
 			i = i | dto.EventToIntent(dto.EventPublicMessageDelete)
-		case DirectMessageDeleteEventHandler:
-			DefaultHandlers.DirectMessageDelete = handle
+		case DirectMessageDeleteEventContextHandler:
+			d.registry.DirectMessageDelete = append(d.registry.DirectMessageDelete, handle)
 			i = i | dto.EventToIntent(dto.EventDirectMessageDelete)
-		case MessageReactionEventHandler:
-			DefaultHandlers.MessageReaction = handle
+		case MessageReactionEventContextHandler:
+			d.registry.MessageReaction = append(d.registry.MessageReaction, handle)
 			i = i | dto.EventToIntent(dto.EventMessageReactionAdd, dto.EventMessageReactionRemove)
-		case MessageAuditEventHandler:
-			DefaultHandlers.MessageAudit = handle
+		case MessageAuditEventContextHandler:
+			d.registry.MessageAudit = append(d.registry.MessageAudit, handle)
 			i = i | dto.EventToIntent(dto.EventMessageAuditPass, dto.EventMessageAuditReject)
-		case UserQueryEventHandler:
-			DefaultHandlers.UserQuery = handle
+		case UserQueryEventContextHandler:
+			d.registry.UserQuery = append(d.registry.UserQuery, handle)
 			i = i | dto.EventToIntent(dto.EventUserMessageCreate)
-		case GroupAtMessageEventHandler:
-			DefaultHandlers.GroupAtMessage = handle
+		case GroupAtMessageEventContextHandler:
+			d.registry.GroupAtMessage = append(d.registry.GroupAtMessage, handle)
 			i = i | dto.EventToIntent(dto.EventGroupMessageCreate)
-		case AddGroupEventHandler:
-			DefaultHandlers.AddGroup = handle
+		case AddGroupEventContextHandler:
+			d.registry.AddGroup = append(d.registry.AddGroup, handle)
 			i = i | dto.EventToIntent(dto.EventGroupAddBot)
-		case QuitGroupEventHandler:
-			DefaultHandlers.QuitGroup = handle
+		case QuitGroupEventContextHandler:
+			d.registry.QuitGroup = append(d.registry.QuitGroup, handle)
 			i = i | dto.EventToIntent(dto.EventGroupDelBot)
-		case GroupRejectMessageEventHandler:
-			DefaultHandlers.GroupRejectMessage = handle
+		case GroupRejectMessageEventContextHandler:
+			d.registry.GroupRejectMessage = append(d.registry.GroupRejectMessage, handle)
 			i = i | dto.EventToIntent(dto.EventGroupRejectMsg)
-		case GroupReciveMessageEventHandler:
-			DefaultHandlers.GroupReciveMessage = handle
+		case GroupReciveMessageEventContextHandler:
+			d.registry.GroupReciveMessage = append(d.registry.GroupReciveMessage, handle)
 			i = i | dto.EventToIntent(dto.EventGroupReciveMsg)
-		case UserAddBotEventHandler:
-			DefaultHandlers.UserAddBot = handle
+		case UserAddBotEventContextHandler:
+			d.registry.UserAddBot = append(d.registry.UserAddBot, handle)
 			i = i | dto.EventToIntent(dto.EventUserAddBot)
-		case UserDelBotEventHandler:
-			DefaultHandlers.UserDelBot = handle
+		case UserDelBotEventContextHandler:
+			d.registry.UserDelBot = append(d.registry.UserDelBot, handle)
 			i = i | dto.EventToIntent(dto.EventUserDelBot)
-		case UserReciveMessageEventHandler:
-			DefaultHandlers.UserReciveMessage = handle
+		case UserReciveMessageEventContextHandler:
+			d.registry.UserReciveMessage = append(d.registry.UserReciveMessage, handle)
 			i = i | dto.EventToIntent(dto.EventUserReciveMsg)
-		case UserRejectMessageEventHandler:
-			DefaultHandlers.UserRejectMessage = handle
-			i = i | dto.EventToIntent(dto.EventUserRejectMsg) 
+		case UserRejectMessageEventContextHandler:
+			d.registry.UserRejectMessage = append(d.registry.UserRejectMessage, handle)
+			i = i | dto.EventToIntent(dto.EventUserRejectMsg)
 		default:
 		}
 	}
@@ -35,7 +35,7 @@ func Test_redisSessionManager(t *testing.T) {
 			}
 			intent := event.RegisterHandlers(message)
 			ws.Shards = 2
-			botgo.NewSessionManager().Start(ws, botToken, &intent)
+			botgo.NewSessionManager().Start(ctx, ws, botToken, &intent)
 		},
 	)
 }
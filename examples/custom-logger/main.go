@@ -39,7 +39,7 @@ func main() {
 	}
 	// 根据不同的回调，生成 intents
 	intent := event.RegisterHandlers(ATMessageEventHandler(api))
-	if err = botgo.NewSessionManager().Start(wsInfo, botToken, &intent); err != nil {
+	if err = botgo.NewSessionManager().Start(ctx, wsInfo, botToken, &intent); err != nil {
 		log.Fatalln(err)
 	}
 }
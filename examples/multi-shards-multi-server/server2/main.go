@@ -42,7 +42,7 @@ func main() {
 	intent := event.RegisterHandlers(handler.ATMessageEventHandler(api))
 	// 指定需要启动的分片数为2
 	wsInfo.Shards = 2
-	if err = botgo.NewSessionManager().Start(wsInfo, botToken, &intent); err != nil {
+	if err = botgo.NewSessionManager().Start(ctx, wsInfo, botToken, &intent); err != nil {
 		log.Fatalln(err)
 	}
 }
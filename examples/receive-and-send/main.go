@@ -4,9 +4,12 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
 	"path"
 	"runtime"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/tencent-connect/botgo"
@@ -21,7 +24,9 @@ import (
 var processor Processor
 
 func main() {
-	ctx := context.Background()
+	// 收到退出信号后 ctx 结束，session manager 会关闭连接，等待已收到的事件处理完成后返回
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 	// 加载 appid 和 token
 	botToken := token.New(token.TypeBot)
 	if err := botToken.LoadFromConfig(getConfigPath("config.yaml")); err != nil {
@@ -66,7 +71,7 @@ func main() {
 		ThreadEventHandler(),
 	)
 	// 指定需要启动的分片数为 2 的话可以手动修改 wsInfo
-	if err = botgo.NewSessionManager().Start(wsInfo, botToken, &intent); err != nil {
+	if err = botgo.NewSessionManager().Start(ctx, wsInfo, botToken, &intent); err != nil {
 		log.Fatalln(err)
 	}
 }
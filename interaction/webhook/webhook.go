@@ -1,10 +1,12 @@
 package webhook
 
 import (
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/tencent-connect/botgo/dto"
 	"github.com/tencent-connect/botgo/event"
@@ -45,6 +47,20 @@ var DefaultGetSecretFunc = func() string {
 // 会自动进行签名验证，心跳包回复，以及根据使用 event.RegisterHandlers 注册的 handler 去执行不同的 handler 来处理事件
 // 如果开发者不想在接收事件的地方处理，可以实现 DefaultHandlers.Plain 然后在内部处理相关的异步生产或者转发的逻辑
 func HTTPHandler(w http.ResponseWriter, r *http.Request) {
+	handleHTTP(event.DefaultDispatcher, w, r)
+}
+
+// NewHTTPHandler 创建使用指定事件分发器的回调 handler，处理逻辑与 HTTPHandler 一致
+func NewHTTPHandler(dispatcher *event.Dispatcher) http.HandlerFunc {
+	if dispatcher == nil {
+		dispatcher = event.DefaultDispatcher
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		handleHTTP(dispatcher, w, r)
+	}
+}
+
+func handleHTTP(dispatcher *event.Dispatcher, w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 	body := make([]byte, r.ContentLength)
 	if _, err := r.Body.Read(body); err != nil && err != io.EOF {
@@ -68,7 +84,12 @@ func HTTPHandler(w http.ResponseWriter, r *http.Request) {
 	// 原始数据放入，parse 的时候需要从里面提取 d
 	payload.RawMessage = body
 
-	result := parsePayload(payload, r.Header.Get(openapi.TraceIDKey))
+	payload.ReceivedAt = time.Now()
+
+	// 请求结束时 context 会被取消
+	ctx := event.WithTraceID(r.Context(), r.Header.Get(openapi.TraceIDKey))
+	ctx = event.WithReceivedTime(ctx, payload.ReceivedAt)
+	result := parsePayload(ctx, dispatcher, payload, r.Header.Get(openapi.TraceIDKey))
 	if result != "" {
 		if _, err := w.Write([]byte(result)); err != nil {
 			log.Errorf("write http callback response error: %s, traceID: %s", err, r.Header.Get(openapi.TraceIDKey))
@@ -77,7 +98,7 @@ func HTTPHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func parsePayload(payload *dto.WSPayload, traceID string) string {
+func parsePayload(ctx context.Context, dispatcher *event.Dispatcher, payload *dto.WSPayload, traceID string) string {
 	// 处理心跳包
 	if payload.OPCode == dto.WSHeartbeat {
 		return GenHeartbeatACK(uint32(payload.Data.(float64)))
@@ -86,7 +107,7 @@ func parsePayload(payload *dto.WSPayload, traceID string) string {
 	// 处理事件
 	if payload.OPCode == dto.WSDispatchEvent {
 		// 解析具体事件，并投递给业务注册的 handler
-		if err := event.ParseAndHandle(payload); err != nil {
+		if err := dispatcher.ParseAndHandle(ctx, payload); err != nil {
 			log.Errorf(
 				"parseAndHandle failed, %v, traceID:%s, payload: %v", err,
 				traceID, payload,
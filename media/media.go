@@ -0,0 +1,159 @@
+// Package media 上传单聊与群聊的富媒体，并按照内容缓存上传返回的 file_info，避免重复上传相同的资源。
+//
+//	uploader := media.NewUploader(api)
+//	uploaded, err := uploader.UploadFile(ctx, target, dto.MediaFileTypeImage, "image.png")
+//	msg := &dto.MessageToCreate{Type: dto.MsgTypeMedia, Media: uploaded.Media(), MsgID: msgID}
+package media
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/tencent-connect/botgo/dto"
+	"github.com/tencent-connect/botgo/log"
+	"github.com/tencent-connect/botgo/openapi"
+	"github.com/tencent-connect/botgo/reply"
+	"github.com/tencent-connect/botgo/state"
+)
+
+// 默认配置
+const (
+	// DefaultCacheSize 默认内存缓存的容量
+	DefaultCacheSize = 1000
+	// ExpireMargin file_info 在到期前 ExpireMargin 从缓存中失效，避免发送时已经过期
+	ExpireMargin = time.Minute
+)
+
+// ErrUnsupportedScene 只有单聊与群聊支持上传富媒体
+var ErrUnsupportedScene = errors.New("media: rich media upload is only supported in c2c and group")
+
+// Uploader 富媒体上传，file_info 只能在上传的单聊或者群聊中使用，缓存按照目标与内容区分
+type Uploader struct {
+	api   openapi.OpenAPI
+	cache state.Backend
+}
+
+// Option 上传的配置
+type Option func(u *Uploader)
+
+// WithCache 设置缓存 file_info 的存储后端，默认为容量 DefaultCacheSize 的内存缓存，多个实例共享时可以使用 state.RedisBackend
+func WithCache(backend state.Backend) Option {
+	return func(u *Uploader) {
+		u.cache = backend
+	}
+}
+
+// NewUploader 创建富媒体上传
+func NewUploader(api openapi.OpenAPI, opts ...Option) *Uploader {
+	u := &Uploader{api: api}
+	for _, opt := range opts {
+		opt(u)
+	}
+	if u.cache == nil {
+		u.cache = state.NewMemoryBackend(DefaultCacheSize)
+	}
+	return u
+}
+
+// Upload 以 base64 file_data 上传 data，相同目标的相同内容在 file_info 有效期内直接返回缓存
+func (u *Uploader) Upload(ctx context.Context, target reply.Target, fileType dto.MediaFileType,
+	data []byte) (*dto.MediaReturnParam, error) {
+	sum := sha256.Sum256(data)
+	return u.upload(ctx, target, hex.EncodeToString(sum[:]), &dto.Media{
+		FileType: fileType,
+		FileData: base64.StdEncoding.EncodeToString(data),
+	})
+}
+
+// UploadFile 读取本地文件并上传
+func (u *Uploader) UploadFile(ctx context.Context, target reply.Target, fileType dto.MediaFileType,
+	path string) (*dto.MediaReturnParam, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return u.Upload(ctx, target, fileType, data)
+}
+
+// UploadURL 由开放平台拉取 url 的资源并上传，以 url 作为缓存的 key
+func (u *Uploader) UploadURL(ctx context.Context, target reply.Target, fileType dto.MediaFileType,
+	url string) (*dto.MediaReturnParam, error) {
+	sum := sha256.Sum256([]byte(url))
+	return u.upload(ctx, target, "url_"+hex.EncodeToString(sum[:]), &dto.Media{FileType: fileType, URL: url})
+}
+
+// upload 优先使用缓存的 file_info，没有时上传并缓存
+func (u *Uploader) upload(ctx context.Context, target reply.Target, hash string,
+	media *dto.Media) (*dto.MediaReturnParam, error) {
+	key, err := cacheKey(target, media.FileType, hash)
+	if err != nil {
+		return nil, err
+	}
+	if cached := u.get(ctx, key); cached != nil {
+		return cached, nil
+	}
+	var result *dto.MediaReturnParam
+	if target.Scene == reply.SceneC2C {
+		result, err = u.api.PostRichMediaToUser(ctx, target.UserOpenID, media)
+	} else {
+		result, err = u.api.PostRichMediaToGroup(ctx, target.GroupOpenID, media)
+	}
+	if err != nil {
+		return nil, err
+	}
+	u.set(ctx, key, result)
+	return result, nil
+}
+
+func (u *Uploader) get(ctx context.Context, key string) *dto.MediaReturnParam {
+	data, err := u.cache.Get(ctx, key)
+	if err != nil {
+		log.Warnf("[media] get %s failed, %v", key, err)
+		return nil
+	}
+	if data == nil {
+		return nil
+	}
+	result := &dto.MediaReturnParam{}
+	if err := json.Unmarshal(data, result); err != nil {
+		log.Warnf("[media] unmarshal %s failed, %v", key, err)
+		return nil
+	}
+	return result
+}
+
+// set 缓存 file_info，ttl 为 0 表示长期有效，剩余有效期不足 ExpireMargin 时不缓存
+func (u *Uploader) set(ctx context.Context, key string, result *dto.MediaReturnParam) {
+	ttl := time.Duration(result.TTL)*time.Second - ExpireMargin
+	if result.TTL == 0 {
+		ttl = 0
+	} else if ttl <= 0 {
+		return
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+	if err := u.cache.Set(ctx, key, data, ttl); err != nil {
+		log.Warnf("[media] set %s failed, %v", key, err)
+	}
+}
+
+// cacheKey 缓存的 key，由目标，媒体类型与内容的 hash 组成
+func cacheKey(target reply.Target, fileType dto.MediaFileType, hash string) (string, error) {
+	switch target.Scene {
+	case reply.SceneC2C:
+		return fmt.Sprintf("media:c2c:%s:%d:%s", target.UserOpenID, fileType, hash), nil
+	case reply.SceneGroup:
+		return fmt.Sprintf("media:group:%s:%d:%s", target.GroupOpenID, fileType, hash), nil
+	default:
+		return "", ErrUnsupportedScene
+	}
+}
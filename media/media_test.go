@@ -0,0 +1,109 @@
+package media
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tencent-connect/botgo/dto"
+	"github.com/tencent-connect/botgo/openapi/fake"
+	"github.com/tencent-connect/botgo/reply"
+	"github.com/tencent-connect/botgo/state"
+)
+
+// ttlAPI 上传群聊富媒体时返回指定的有效期
+type ttlAPI struct {
+	*fake.OpenAPI
+	ttl int
+}
+
+func (a *ttlAPI) PostRichMediaToGroup(ctx context.Context,
+	groupOpenID string, media *dto.Media) (*dto.MediaReturnParam, error) {
+	result, err := a.OpenAPI.PostRichMediaToGroup(ctx, groupOpenID, media)
+	if err != nil {
+		return nil, err
+	}
+	result.TTL = a.ttl
+	return result, nil
+}
+
+func TestUploader(t *testing.T) {
+	ctx := context.Background()
+	f := fake.New()
+	api := &ttlAPI{OpenAPI: f}
+	uploader := NewUploader(api)
+	group := reply.Target{Scene: reply.SceneGroup, GroupOpenID: "group"}
+	user := reply.Target{Scene: reply.SceneC2C, UserOpenID: "user"}
+
+	t.Run("file data", func(t *testing.T) {
+		api.ttl = 0
+		first, err := uploader.Upload(ctx, group, dto.MediaFileTypeImage, []byte("hello"))
+		assert.Nil(t, err)
+		second, err := uploader.Upload(ctx, group, dto.MediaFileTypeImage, []byte("hello"))
+		assert.Nil(t, err)
+		assert.Equal(t, first, second)
+		calls := f.Calls("PostRichMediaToGroup")
+		assert.Len(t, calls, 1)
+		media := calls[0].Args[1].(*dto.Media)
+		assert.Equal(t, "aGVsbG8=", media.FileData)
+		assert.Equal(t, &dto.Media{FileInfo: first.Info}, first.Media())
+
+		// 不同的内容，不同的目标需要重新上传
+		_, err = uploader.Upload(ctx, group, dto.MediaFileTypeImage, []byte("world"))
+		assert.Nil(t, err)
+		_, err = uploader.Upload(ctx, user, dto.MediaFileTypeImage, []byte("hello"))
+		assert.Nil(t, err)
+		assert.Len(t, f.Calls("PostRichMediaToGroup"), 2)
+		assert.Len(t, f.Calls("PostRichMediaToUser"), 1)
+	})
+	t.Run("file and url", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "image.png")
+		assert.Nil(t, ioutil.WriteFile(path, []byte("hello"), 0600))
+		_, err := uploader.UploadFile(ctx, user, dto.MediaFileTypeImage, path)
+		assert.Nil(t, err)
+		assert.Len(t, f.Calls("PostRichMediaToUser"), 1)
+		_, err = uploader.UploadFile(ctx, user, dto.MediaFileTypeImage, filepath.Join(t.TempDir(), "missing"))
+		assert.NotNil(t, err)
+
+		for i := 0; i < 2; i++ {
+			_, err = uploader.UploadURL(ctx, user, dto.MediaFileTypeVideo, "https://qq.com/a.mp4")
+			assert.Nil(t, err)
+		}
+		calls := f.Calls("PostRichMediaToUser")
+		assert.Len(t, calls, 2)
+		assert.Equal(t, "https://qq.com/a.mp4", calls[1].Args[1].(*dto.Media).URL)
+	})
+	t.Run("unsupported scene", func(t *testing.T) {
+		_, err := uploader.Upload(ctx, reply.Target{Scene: reply.SceneGuild, ChannelID: "channel"},
+			dto.MediaFileTypeImage, []byte("hello"))
+		assert.Equal(t, ErrUnsupportedScene, err)
+	})
+}
+
+func TestUploader_TTL(t *testing.T) {
+	ctx := context.Background()
+	f := fake.New()
+	api := &ttlAPI{OpenAPI: f, ttl: 30}
+	backend := state.NewMemoryBackend(10)
+	uploader := NewUploader(api, WithCache(backend))
+	group := reply.Target{Scene: reply.SceneGroup, GroupOpenID: "group"}
+
+	// 有效期不足 ExpireMargin，不缓存
+	for i := 0; i < 2; i++ {
+		_, err := uploader.Upload(ctx, group, dto.MediaFileTypeImage, []byte("short"))
+		assert.Nil(t, err)
+	}
+	assert.Len(t, f.Calls("PostRichMediaToGroup"), 2)
+	assert.Equal(t, 0, backend.Len())
+
+	api.ttl = int((time.Hour + ExpireMargin) / time.Second)
+	for i := 0; i < 2; i++ {
+		_, err := uploader.Upload(ctx, group, dto.MediaFileTypeImage, []byte("long"))
+		assert.Nil(t, err)
+	}
+	assert.Len(t, f.Calls("PostRichMediaToGroup"), 3)
+	assert.Equal(t, 1, backend.Len())
+}
@@ -0,0 +1,125 @@
+package fake
+
+import (
+	"context"
+
+	"github.com/tencent-connect/botgo/dto"
+)
+
+// 公告的 key 前缀，子频道公告与频道全局公告分开存储
+const (
+	channelAnnouncesPrefix = "channels/"
+	guildAnnouncesPrefix   = "guilds/"
+)
+
+// Announces 返回子频道公告，不存在时返回 nil，用于断言
+func (f *OpenAPI) Announces(channelID string) *dto.Announces {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return cloneAnnounces(f.announces[channelAnnouncesPrefix+channelID])
+}
+
+// GuildAnnounces 返回频道全局公告，不存在时返回 nil，用于断言
+func (f *OpenAPI) GuildAnnounces(guildID string) *dto.Announces {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return cloneAnnounces(f.announces[guildAnnouncesPrefix+guildID])
+}
+
+// CreateChannelAnnounces 创建子频道公告
+func (f *OpenAPI) CreateChannelAnnounces(_ context.Context,
+	channelID string, announce *dto.ChannelAnnouncesToCreate) (*dto.Announces, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("CreateChannelAnnounces", channelID, announce); err != nil {
+		return nil, err
+	}
+	channel, ok := f.channels[channelID]
+	if !ok {
+		return nil, notFound("channel", channelID)
+	}
+	if _, msg := f.findMessage(channelID, announce.MessageID); msg == nil {
+		return nil, notFound("message", announce.MessageID)
+	}
+	created := &dto.Announces{
+		GuildID:   channel.GuildID,
+		ChannelID: channelID,
+		MessageID: announce.MessageID,
+	}
+	f.announces[channelAnnouncesPrefix+channelID] = created
+	return cloneAnnounces(created), nil
+}
+
+// DeleteChannelAnnounces 删除子频道公告，会校验 messageID 是否匹配
+func (f *OpenAPI) DeleteChannelAnnounces(_ context.Context, channelID, messageID string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("DeleteChannelAnnounces", channelID, messageID); err != nil {
+		return err
+	}
+	return f.deleteAnnounces(channelAnnouncesPrefix+channelID, messageID)
+}
+
+// CleanChannelAnnounces 删除子频道公告，不校验 messageID
+func (f *OpenAPI) CleanChannelAnnounces(_ context.Context, channelID string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("CleanChannelAnnounces", channelID); err != nil {
+		return err
+	}
+	delete(f.announces, channelAnnouncesPrefix+channelID)
+	return nil
+}
+
+// CreateGuildAnnounces 创建频道全局公告
+func (f *OpenAPI) CreateGuildAnnounces(_ context.Context,
+	guildID string, announce *dto.GuildAnnouncesToCreate) (*dto.Announces, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("CreateGuildAnnounces", guildID, announce); err != nil {
+		return nil, err
+	}
+	if _, ok := f.guilds[guildID]; !ok {
+		return nil, notFound("guild", guildID)
+	}
+	created := cloneAnnounces(&dto.Announces{
+		GuildID:           guildID,
+		ChannelID:         announce.ChannelID,
+		MessageID:         announce.MessageID,
+		AnnouncesType:     announce.AnnouncesType,
+		RecommendChannels: announce.RecommendChannels,
+	})
+	f.announces[guildAnnouncesPrefix+guildID] = created
+	return cloneAnnounces(created), nil
+}
+
+// DeleteGuildAnnounces 删除频道全局公告，会校验 messageID 是否匹配
+func (f *OpenAPI) DeleteGuildAnnounces(_ context.Context, guildID, messageID string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("DeleteGuildAnnounces", guildID, messageID); err != nil {
+		return err
+	}
+	return f.deleteAnnounces(guildAnnouncesPrefix+guildID, messageID)
+}
+
+// CleanGuildAnnounces 删除频道全局公告，不校验 messageID
+func (f *OpenAPI) CleanGuildAnnounces(_ context.Context, guildID string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("CleanGuildAnnounces", guildID); err != nil {
+		return err
+	}
+	delete(f.announces, guildAnnouncesPrefix+guildID)
+	return nil
+}
+
+// deleteAnnounces 删除公告，messageID 不匹配时返回错误，调用方需要持有锁
+func (f *OpenAPI) deleteAnnounces(key, messageID string) error {
+	announces, ok := f.announces[key]
+	if !ok || announces.MessageID != messageID {
+		return notFound("announces", messageID)
+	}
+	delete(f.announces, key)
+	return nil
+}
@@ -0,0 +1,77 @@
+package fake
+
+import (
+	"testing"
+
+	"github.com/tencent-connect/botgo/dto"
+)
+
+// PostedMessages 返回发送到子频道的消息
+func (f *OpenAPI) PostedMessages(channelID string) []*dto.MessageToCreate {
+	return f.postedTo(channelID)
+}
+
+// PostedUserMessages 返回发送给单聊用户的消息，包括 srv_send_msg 为 true 的富媒体消息
+func (f *OpenAPI) PostedUserMessages(openID string) []*dto.MessageToCreate {
+	return f.postedTo(userTargetPrefix + openID)
+}
+
+// PostedGroupMessages 返回发送到群聊的消息，包括 srv_send_msg 为 true 的富媒体消息
+func (f *OpenAPI) PostedGroupMessages(groupOpenID string) []*dto.MessageToCreate {
+	return f.postedTo(groupTargetPrefix + groupOpenID)
+}
+
+// PostedDirectMessages 返回发送到私信频道的消息，guildID 为私信频道的 guildID
+func (f *OpenAPI) PostedDirectMessages(guildID string) []*dto.MessageToCreate {
+	return f.postedTo(dmTargetPrefix + guildID)
+}
+
+// AssertCalled 断言接口被调用过
+func (f *OpenAPI) AssertCalled(t testing.TB, method string) {
+	t.Helper()
+	if len(f.Calls(method)) == 0 {
+		t.Errorf("expected %s to be called, but it was not", method)
+	}
+}
+
+// AssertNotCalled 断言接口没有被调用过
+func (f *OpenAPI) AssertNotCalled(t testing.TB, method string) {
+	t.Helper()
+	if calls := f.Calls(method); len(calls) > 0 {
+		t.Errorf("expected %s not to be called, but it was called %d times", method, len(calls))
+	}
+}
+
+// AssertPostedCount 断言发送到子频道的消息数量
+func (f *OpenAPI) AssertPostedCount(t testing.TB, channelID string, count int) {
+	t.Helper()
+	if posted := f.PostedMessages(channelID); len(posted) != count {
+		t.Errorf("expected %d messages posted to channel %s, got %d", count, channelID, len(posted))
+	}
+}
+
+// AssertPostedContent 断言发送到子频道的消息中包含指定内容的消息
+func (f *OpenAPI) AssertPostedContent(t testing.TB, channelID string, content string) {
+	t.Helper()
+	posted := f.PostedMessages(channelID)
+	for _, msg := range posted {
+		if msg.Content == content {
+			return
+		}
+	}
+	contents := make([]string, 0, len(posted))
+	for _, msg := range posted {
+		contents = append(contents, msg.Content)
+	}
+	t.Errorf("expected message %q posted to channel %s, got %q", content, channelID, contents)
+}
+
+func (f *OpenAPI) postedTo(target string) []*dto.MessageToCreate {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	posted := make([]*dto.MessageToCreate, 0, len(f.posted[target]))
+	for _, msg := range f.posted[target] {
+		posted = append(posted, cloneMessageToCreate(msg))
+	}
+	return posted
+}
@@ -0,0 +1,202 @@
+package fake
+
+import (
+	"context"
+	"sort"
+
+	"github.com/tencent-connect/botgo/dto"
+)
+
+// AddChannel 添加子频道，未指定 ID 时自动生成，返回添加的子频道
+func (f *OpenAPI) AddChannel(channel *dto.Channel) *dto.Channel {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	stored := cloneChannel(channel)
+	if stored.ID == "" {
+		stored.ID = f.nextID()
+	}
+	f.channels[stored.ID] = stored
+	return cloneChannel(stored)
+}
+
+// Channel 拉取指定子频道信息
+func (f *OpenAPI) Channel(_ context.Context, channelID string) (*dto.Channel, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("Channel", channelID); err != nil {
+		return nil, err
+	}
+	channel, ok := f.channels[channelID]
+	if !ok {
+		return nil, notFound("channel", channelID)
+	}
+	return cloneChannel(channel), nil
+}
+
+// Channels 拉取子频道列表，按照排序位置与 ID 排序
+func (f *OpenAPI) Channels(_ context.Context, guildID string) ([]*dto.Channel, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("Channels", guildID); err != nil {
+		return nil, err
+	}
+	channels := make([]*dto.Channel, 0)
+	for _, channel := range f.channels {
+		if channel.GuildID == guildID {
+			channels = append(channels, channel)
+		}
+	}
+	sort.Slice(channels, func(i, j int) bool {
+		if channels[i].Position != channels[j].Position {
+			return channels[i].Position < channels[j].Position
+		}
+		return channels[i].ID < channels[j].ID
+	})
+	return cloneChannels(channels), nil
+}
+
+// PostChannel 创建子频道
+func (f *OpenAPI) PostChannel(_ context.Context,
+	guildID string, value *dto.ChannelValueObject) (*dto.Channel, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("PostChannel", guildID, value); err != nil {
+		return nil, err
+	}
+	return f.createChannel(guildID, value), nil
+}
+
+// PatchChannel 修改子频道
+func (f *OpenAPI) PatchChannel(_ context.Context,
+	channelID string, value *dto.ChannelValueObject) (*dto.Channel, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("PatchChannel", channelID, value); err != nil {
+		return nil, err
+	}
+	channel, ok := f.channels[channelID]
+	if !ok {
+		return nil, notFound("channel", channelID)
+	}
+	channel.ChannelValueObject = *value
+	return cloneChannel(channel), nil
+}
+
+// DeleteChannel 删除指定子频道
+func (f *OpenAPI) DeleteChannel(_ context.Context, channelID string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("DeleteChannel", channelID); err != nil {
+		return err
+	}
+	if _, ok := f.channels[channelID]; !ok {
+		return notFound("channel", channelID)
+	}
+	delete(f.channels, channelID)
+	delete(f.messages, channelID)
+	delete(f.pins, channelID)
+	return nil
+}
+
+// CreatePrivateChannel 创建私密子频道
+func (f *OpenAPI) CreatePrivateChannel(_ context.Context,
+	guildID string, value *dto.ChannelValueObject, userIds []string) (*dto.Channel, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("CreatePrivateChannel", guildID, value, userIds); err != nil {
+		return nil, err
+	}
+	private := *value
+	private.PrivateType = dto.ChannelPrivateTypeAdminAndMember
+	private.PrivateUserIDs = userIds
+	return f.createChannel(guildID, &private), nil
+}
+
+// ListVoiceChannelMembers 拉取语音子频道在线成员，fake 实现返回空列表
+func (f *OpenAPI) ListVoiceChannelMembers(_ context.Context, channelID string) ([]*dto.Member, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("ListVoiceChannelMembers", channelID); err != nil {
+		return nil, err
+	}
+	if _, ok := f.channels[channelID]; !ok {
+		return nil, notFound("channel", channelID)
+	}
+	return []*dto.Member{}, nil
+}
+
+// ChannelPermissions 获取指定子频道的权限
+func (f *OpenAPI) ChannelPermissions(_ context.Context, channelID, userID string) (*dto.ChannelPermissions, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("ChannelPermissions", channelID, userID); err != nil {
+		return nil, err
+	}
+	if p, ok := f.permissions[channelID+"/"+userID]; ok {
+		permissions := *p
+		return &permissions, nil
+	}
+	return &dto.ChannelPermissions{ChannelID: channelID, UserID: userID}, nil
+}
+
+// PutChannelPermissions 修改指定子频道的权限
+func (f *OpenAPI) PutChannelPermissions(_ context.Context,
+	channelID, userID string, p *dto.UpdateChannelPermissions) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("PutChannelPermissions", channelID, userID, p); err != nil {
+		return err
+	}
+	key := channelID + "/" + userID
+	existed, ok := f.permissions[key]
+	if !ok {
+		existed = &dto.ChannelPermissions{ChannelID: channelID, UserID: userID}
+		f.permissions[key] = existed
+	}
+	existed.Permissions = p.Add
+	return nil
+}
+
+// ChannelRolesPermissions 获取指定子频道身份组的权限
+func (f *OpenAPI) ChannelRolesPermissions(_ context.Context,
+	channelID, roleID string) (*dto.ChannelRolesPermissions, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("ChannelRolesPermissions", channelID, roleID); err != nil {
+		return nil, err
+	}
+	if p, ok := f.rolePerms[channelID+"/"+roleID]; ok {
+		permissions := *p
+		return &permissions, nil
+	}
+	return &dto.ChannelRolesPermissions{ChannelID: channelID, RoleID: roleID}, nil
+}
+
+// PutChannelRolesPermissions 修改指定子频道身份组的权限
+func (f *OpenAPI) PutChannelRolesPermissions(_ context.Context,
+	channelID, roleID string, p *dto.UpdateChannelPermissions) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("PutChannelRolesPermissions", channelID, roleID, p); err != nil {
+		return err
+	}
+	key := channelID + "/" + roleID
+	existed, ok := f.rolePerms[key]
+	if !ok {
+		existed = &dto.ChannelRolesPermissions{ChannelID: channelID, RoleID: roleID}
+		f.rolePerms[key] = existed
+	}
+	existed.Permissions = p.Add
+	return nil
+}
+
+// createChannel 创建子频道，返回子频道的副本，调用方需要持有锁
+func (f *OpenAPI) createChannel(guildID string, value *dto.ChannelValueObject) *dto.Channel {
+	channel := cloneChannel(&dto.Channel{
+		ID:                 f.nextID(),
+		GuildID:            guildID,
+		ChannelValueObject: *value,
+	})
+	f.channels[channel.ID] = channel
+	return cloneChannel(channel)
+}
@@ -0,0 +1,153 @@
+package fake
+
+import (
+	"github.com/tencent-connect/botgo/dto"
+)
+
+// fake 保存调用方传入的对象时保存副本，返回给调用方的也是副本，
+// 避免调用方在不持有锁的情况下修改内部状态。副本会复制结构体本身、直接引用的结构体与切片，
+// 更深层的 embed、ark 等内容仍然共享，调用方不应该修改
+
+func cloneUser(u *dto.User) *dto.User {
+	if u == nil {
+		return nil
+	}
+	user := *u
+	return &user
+}
+
+func cloneUsers(users []*dto.User) []*dto.User {
+	result := make([]*dto.User, 0, len(users))
+	for _, u := range users {
+		result = append(result, cloneUser(u))
+	}
+	return result
+}
+
+func cloneGuild(g *dto.Guild) *dto.Guild {
+	guild := *g
+	if g.Channels != nil {
+		guild.Channels = cloneChannels(g.Channels)
+	}
+	return &guild
+}
+
+func cloneMember(m *dto.Member) *dto.Member {
+	if m == nil {
+		return nil
+	}
+	member := *m
+	member.User = cloneUser(m.User)
+	if m.Roles != nil {
+		member.Roles = append([]string{}, m.Roles...)
+	}
+	return &member
+}
+
+func cloneMembers(members []*dto.Member) []*dto.Member {
+	result := make([]*dto.Member, 0, len(members))
+	for _, m := range members {
+		result = append(result, cloneMember(m))
+	}
+	return result
+}
+
+func cloneChannel(c *dto.Channel) *dto.Channel {
+	channel := *c
+	if c.PrivateUserIDs != nil {
+		channel.PrivateUserIDs = append([]string{}, c.PrivateUserIDs...)
+	}
+	return &channel
+}
+
+func cloneChannels(channels []*dto.Channel) []*dto.Channel {
+	result := make([]*dto.Channel, 0, len(channels))
+	for _, c := range channels {
+		result = append(result, cloneChannel(c))
+	}
+	return result
+}
+
+func cloneMessage(m *dto.Message) *dto.Message {
+	msg := *m
+	msg.Author = cloneUser(m.Author)
+	msg.Member = cloneMember(m.Member)
+	if m.Attachments != nil {
+		msg.Attachments = make([]*dto.MessageAttachment, 0, len(m.Attachments))
+		for _, a := range m.Attachments {
+			attachment := *a
+			msg.Attachments = append(msg.Attachments, &attachment)
+		}
+	}
+	if m.Embeds != nil {
+		msg.Embeds = make([]*dto.Embed, 0, len(m.Embeds))
+		for _, e := range m.Embeds {
+			embed := *e
+			msg.Embeds = append(msg.Embeds, &embed)
+		}
+	}
+	if m.Mentions != nil {
+		msg.Mentions = cloneUsers(m.Mentions)
+	}
+	if m.MessageReference != nil {
+		ref := *m.MessageReference
+		msg.MessageReference = &ref
+	}
+	return &msg
+}
+
+func cloneMessages(messages []*dto.Message) []*dto.Message {
+	result := make([]*dto.Message, 0, len(messages))
+	for _, m := range messages {
+		result = append(result, cloneMessage(m))
+	}
+	return result
+}
+
+func cloneMessageToCreate(m *dto.MessageToCreate) *dto.MessageToCreate {
+	msg := *m
+	if m.MessageReference != nil {
+		ref := *m.MessageReference
+		msg.MessageReference = &ref
+	}
+	if m.Media != nil {
+		media := *m.Media
+		msg.Media = &media
+	}
+	return &msg
+}
+
+func cloneThread(t *dto.Thread) *dto.Thread {
+	thread := *t
+	return &thread
+}
+
+func cloneRole(r *dto.Role) *dto.Role {
+	role := *r
+	return &role
+}
+
+func cloneSchedule(s *dto.Schedule) *dto.Schedule {
+	schedule := *s
+	schedule.Creator = cloneMember(s.Creator)
+	return &schedule
+}
+
+func cloneAnnounces(a *dto.Announces) *dto.Announces {
+	if a == nil {
+		return nil
+	}
+	announces := *a
+	if a.RecommendChannels != nil {
+		announces.RecommendChannels = append([]dto.RecommendChannel{}, a.RecommendChannels...)
+	}
+	return &announces
+}
+
+func cloneMessageSetting(s *dto.MessageSetting) *dto.MessageSetting {
+	setting := *s
+	if s.ChannelIDs != nil {
+		setting.ChannelIDs = append([]string{}, s.ChannelIDs...)
+	}
+	return &setting
+}
@@ -0,0 +1,66 @@
+package fake
+
+import (
+	"context"
+
+	"github.com/tencent-connect/botgo/dto"
+	"github.com/tencent-connect/botgo/openapi"
+)
+
+// CreateDirectMessage 创建私信频道，同一个用户多次创建返回同一个私信频道
+func (f *OpenAPI) CreateDirectMessage(_ context.Context, dm *dto.DirectMessageToCreate) (*dto.DirectMessage, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("CreateDirectMessage", dm); err != nil {
+		return nil, err
+	}
+	if existed, ok := f.dms[dm.RecipientID]; ok {
+		dm := *existed
+		return &dm, nil
+	}
+	created := &dto.DirectMessage{
+		GuildID:    f.nextID(),
+		ChannelID:  f.nextID(),
+		CreateTime: string(now()),
+	}
+	f.dms[dm.RecipientID] = created
+	copied := *created
+	return &copied, nil
+}
+
+// PostDirectMessage 在私信频道内发消息
+func (f *OpenAPI) PostDirectMessage(_ context.Context,
+	dm *dto.DirectMessage, msg *dto.MessageToCreate) (*dto.Message, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("PostDirectMessage", dm, msg); err != nil {
+		return nil, err
+	}
+	message := f.post(dmTargetPrefix+dm.GuildID, dm.GuildID, msg)
+	message.DirectMessage = true
+	return cloneMessage(message), nil
+}
+
+// RetractDMMessage 撤回私信频道消息
+func (f *OpenAPI) RetractDMMessage(_ context.Context,
+	guildID, msgID string, options ...openapi.RetractMessageOption) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("RetractDMMessage", guildID, msgID, options); err != nil {
+		return err
+	}
+	return f.deleteMessage(dmTargetPrefix+guildID, msgID)
+}
+
+// PostDMSettingGuide 发送私信设置引导
+func (f *OpenAPI) PostDMSettingGuide(_ context.Context,
+	dm *dto.DirectMessage, jumpGuildID string) (*dto.Message, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("PostDMSettingGuide", dm, jumpGuildID); err != nil {
+		return nil, err
+	}
+	message := f.post(dmTargetPrefix+dm.GuildID, dm.GuildID, &dto.MessageToCreate{})
+	message.DirectMessage = true
+	return cloneMessage(message), nil
+}
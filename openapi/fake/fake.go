@@ -0,0 +1,216 @@
+// Package fake 基于内存的 openapi 实现，用于在单元测试中替代真实的开放平台。
+//
+// 使用方式：
+//
+//	api := fake.New()
+//	api.AddGuild(&dto.Guild{ID: "guild"})
+//	api.AddChannel(&dto.Channel{ID: "channel", GuildID: "guild"})
+//	botgo.SetOpenAPIClient(fake.APIVersion, api)
+//	_ = botgo.SelectOpenAPIVersion(fake.APIVersion)
+//
+//	// 执行机器人逻辑后断言
+//	api.AssertPostedContent(t, "channel", "hello")
+package fake
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/tencent-connect/botgo/dto"
+	"github.com/tencent-connect/botgo/errs"
+	"github.com/tencent-connect/botgo/openapi"
+	"github.com/tencent-connect/botgo/token"
+)
+
+// APIVersion fake 实现注册时使用的版本号，避免覆盖真实的实现
+const APIVersion openapi.APIVersion = 1000
+
+// Call 一次接口调用的记录
+type Call struct {
+	Method string        // 接口名，如 PostMessage
+	Args   []interface{} // 调用参数，不包含 ctx
+}
+
+// OpenAPI 基于内存的 openapi 实现，所有接口都是并发安全的
+type OpenAPI struct {
+	lock  sync.Mutex
+	token *token.Token
+	*store
+}
+
+// store 内存中的数据，以及调用记录
+type store struct {
+	seq         uint64
+	me          *dto.User
+	ws          *dto.WebsocketAP
+	guilds      map[string]*dto.Guild
+	guildOrder  []string
+	channels    map[string]*dto.Channel
+	members     map[string]map[string]*dto.Member // guildID -> userID -> member
+	roles       map[string][]*dto.Role            // guildID -> roles
+	messages    map[string][]*dto.Message         // channelID -> messages，私信的 key 为私信频道的 guildID
+	pins        map[string][]string               // channelID -> messageIDs
+	reactions   map[string][]*dto.User            // reactionKey -> users
+	schedules   map[string][]*dto.Schedule        // channelID -> schedules
+	threads     map[string][]*dto.Thread          // channelID -> threads
+	announces   map[string]*dto.Announces         // channelID 或 guildID -> 公告
+	permissions map[string]*dto.ChannelPermissions
+	rolePerms   map[string]*dto.ChannelRolesPermissions
+	dms         map[string]*dto.DirectMessage // recipientID -> 私信频道
+	settings    map[string]*dto.MessageSetting
+	sessions    map[string]*dto.HTTPSession
+
+	posted map[string][]*dto.MessageToCreate // target -> 发送的消息
+	calls  []Call
+	errors map[string][]error // method -> 注入的错误，最后一个错误会一直生效
+}
+
+// New 创建一个空的 fake openapi
+func New() *OpenAPI {
+	return &OpenAPI{store: newStore()}
+}
+
+func newStore() *store {
+	return &store{
+		me: &dto.User{ID: "bot", Username: "bot", Bot: true},
+		ws: &dto.WebsocketAP{
+			URL:    "ws://127.0.0.1/websocket",
+			Shards: 1,
+			SessionStartLimit: dto.SessionStartLimit{
+				Total: 1000, Remaining: 1000, MaxConcurrency: 1,
+			},
+		},
+		guilds:      map[string]*dto.Guild{},
+		channels:    map[string]*dto.Channel{},
+		members:     map[string]map[string]*dto.Member{},
+		roles:       map[string][]*dto.Role{},
+		messages:    map[string][]*dto.Message{},
+		pins:        map[string][]string{},
+		reactions:   map[string][]*dto.User{},
+		schedules:   map[string][]*dto.Schedule{},
+		threads:     map[string][]*dto.Thread{},
+		announces:   map[string]*dto.Announces{},
+		permissions: map[string]*dto.ChannelPermissions{},
+		rolePerms:   map[string]*dto.ChannelRolesPermissions{},
+		dms:         map[string]*dto.DirectMessage{},
+		settings:    map[string]*dto.MessageSetting{},
+		sessions:    map[string]*dto.HTTPSession{},
+		posted:      map[string][]*dto.MessageToCreate{},
+		errors:      map[string][]error{},
+	}
+}
+
+// Version 返回 fake 实现的版本号
+func (f *OpenAPI) Version() openapi.APIVersion {
+	return APIVersion
+}
+
+// Setup 返回当前实例，保证业务代码通过 botgo.NewOpenAPI 创建的实例与测试代码共享数据
+func (f *OpenAPI) Setup(token *token.Token, _ bool, _ ...openapi.Option) openapi.OpenAPI {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.token = token
+	return f
+}
+
+// WithTimeout fake 实现不会超时
+func (f *OpenAPI) WithTimeout(time.Duration) openapi.OpenAPI {
+	return f
+}
+
+// Transport 记录透传请求，返回空的响应
+func (f *OpenAPI) Transport(_ context.Context, method, url string, body interface{}) ([]byte, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("Transport", method, url, body); err != nil {
+		return nil, err
+	}
+	return []byte("{}"), nil
+}
+
+// TraceID fake 实现没有 trace id
+func (f *OpenAPI) TraceID() string {
+	return ""
+}
+
+// InjectError 注入错误，method 为接口名，如 PostMessage
+// 多次注入的错误按顺序返回，最后一个错误会在之后的调用中一直返回
+// 直到调用 ResetErrors
+func (f *OpenAPI) InjectError(method string, errors ...error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.errors[method] = append(f.errors[method], errors...)
+}
+
+// ResetErrors 清除所有注入的错误
+func (f *OpenAPI) ResetErrors() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.errors = map[string][]error{}
+}
+
+// Calls 返回指定接口的调用记录，method 为空时返回所有调用记录
+func (f *OpenAPI) Calls(method string) []Call {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	calls := make([]Call, 0, len(f.calls))
+	for _, c := range f.calls {
+		if method == "" || c.Method == method {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// Reset 清除所有数据、调用记录与注入的错误
+func (f *OpenAPI) Reset() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.store = newStore()
+}
+
+// call 记录调用，并返回注入的错误，调用方需要持有锁
+func (f *OpenAPI) call(method string, args ...interface{}) error {
+	f.calls = append(f.calls, Call{Method: method, Args: args})
+	injected := f.errors[method]
+	if len(injected) == 0 {
+		return nil
+	}
+	err := injected[0]
+	if len(injected) > 1 {
+		f.errors[method] = injected[1:]
+	}
+	return err
+}
+
+// nextID 生成自增的 id，调用方需要持有锁
+func (f *OpenAPI) nextID() string {
+	f.seq++
+	return strconv.FormatUint(f.seq, 10)
+}
+
+// notFoundBizCodes 资源不存在时返回的业务错误码
+var notFoundBizCodes = map[string]int{
+	"guild":   errs.CodeUnknownGuild,
+	"channel": errs.CodeUnknownChannel,
+	"member":  errs.CodeUnknownAccount,
+}
+
+// notFound 返回与开放平台格式一致的 404 错误
+func notFound(kind, id string) error {
+	body, _ := json.Marshal(map[string]interface{}{
+		"code":    notFoundBizCodes[kind],
+		"message": kind + " " + id + " not found",
+	})
+	return errs.NewAPIError(http.StatusNotFound, body, "")
+}
+
+func now() dto.Timestamp {
+	return dto.Timestamp(time.Now().Format(time.RFC3339))
+}
+
+var _ openapi.OpenAPI = (*OpenAPI)(nil)
@@ -0,0 +1,165 @@
+package fake_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tencent-connect/botgo"
+	"github.com/tencent-connect/botgo/dto"
+	"github.com/tencent-connect/botgo/openapi"
+	"github.com/tencent-connect/botgo/openapi/fake"
+	"github.com/tencent-connect/botgo/token"
+)
+
+func TestFakeOpenAPI(t *testing.T) {
+	f := fake.New()
+	f.AddGuild(&dto.Guild{ID: "guild"})
+	f.AddChannel(&dto.Channel{ID: "channel", GuildID: "guild"})
+	for _, id := range []string{"u1", "u2", "u3"} {
+		f.AddMember("guild", &dto.Member{User: &dto.User{ID: id}})
+	}
+	botgo.SetOpenAPIClient(fake.APIVersion, f)
+	assert.Nil(t, botgo.SelectOpenAPIVersion(fake.APIVersion))
+	defer func() {
+		_ = botgo.SelectOpenAPIVersion(openapi.APIv1)
+	}()
+	api := botgo.NewOpenAPI(token.BotToken(1, "token"))
+	ctx := context.Background()
+
+	t.Run("post message", func(t *testing.T) {
+		msg, err := api.PostMessage(ctx, "channel", &dto.MessageToCreate{Content: "hello"})
+		assert.Nil(t, err)
+		assert.Equal(t, "guild", msg.GuildID)
+		f.AssertPostedContent(t, "channel", "hello")
+		f.AssertPostedCount(t, "channel", 1)
+
+		got, err := api.Message(ctx, "channel", msg.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, "hello", got.Content)
+
+		assert.Nil(t, api.RetractMessage(ctx, "channel", msg.ID))
+		_, err = api.Message(ctx, "channel", msg.ID)
+		assert.NotNil(t, err)
+	})
+	t.Run("channel not found", func(t *testing.T) {
+		_, err := api.PostMessage(ctx, "unknown", &dto.MessageToCreate{Content: "hello"})
+		assert.NotNil(t, err)
+	})
+	t.Run("inject error", func(t *testing.T) {
+		injected := errors.New("injected")
+		f.InjectError("Guild", injected, nil)
+		_, err := api.Guild(ctx, "guild")
+		assert.Equal(t, injected, err)
+		_, err = api.Guild(ctx, "guild")
+		assert.Nil(t, err)
+		f.ResetErrors()
+		f.AssertCalled(t, "Guild")
+		f.AssertNotCalled(t, "DeleteChannel")
+	})
+	t.Run("members pager", func(t *testing.T) {
+		members, err := api.GuildMembers(ctx, "guild", &dto.GuildMembersPager{After: "u1", Limit: "10"})
+		assert.Nil(t, err)
+		assert.Len(t, members, 2)
+		assert.Equal(t, "u2", members[0].User.ID)
+	})
+	t.Run("roles", func(t *testing.T) {
+		result, err := api.PostRole(ctx, "guild", &dto.Role{Name: "admin"})
+		assert.Nil(t, err)
+		assert.Nil(t, api.MemberAddRole(ctx, "guild", result.RoleID, "u1", nil))
+		members, next, err := api.GuildRoleMembers(ctx, "guild", string(result.RoleID),
+			&dto.GuildRoleMembersPager{Limit: "10"})
+		assert.Nil(t, err)
+		assert.Empty(t, next)
+		assert.Len(t, members, 1)
+	})
+	t.Run("pins and reactions", func(t *testing.T) {
+		msg, _ := api.PostMessage(ctx, "channel", &dto.MessageToCreate{Content: "pin me"})
+		pins, err := api.AddPins(ctx, "channel", msg.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{msg.ID}, pins.MessageIDs)
+
+		emoji := dto.Emoji{ID: "4", Type: 1}
+		f.AddReaction("channel", msg.ID, emoji, &dto.User{ID: "u1"})
+		assert.Nil(t, api.CreateMessageReaction(ctx, "channel", msg.ID, emoji))
+		users, err := api.GetMessageReactionUsers(ctx, "channel", msg.ID, emoji,
+			&dto.MessageReactionPager{Limit: "1"})
+		assert.Nil(t, err)
+		assert.False(t, users.IsEnd)
+		users, _ = api.GetMessageReactionUsers(ctx, "channel", msg.ID, emoji,
+			&dto.MessageReactionPager{Cookie: users.Cookie, Limit: "1"})
+		assert.True(t, users.IsEnd)
+		assert.Equal(t, "bot", users.Users[0].ID)
+	})
+	t.Run("forum threads", func(t *testing.T) {
+		thread, err := dto.NewRichTextThread("周报", &dto.RichText{Paragraphs: []*dto.Paragraph{{
+			Elems: []*dto.Elem{{Type: dto.ElemTypeText, Text: &dto.TextElem{Text: "本周动态"}}},
+		}}})
+		assert.Nil(t, err)
+		published, err := api.PublishThread(ctx, "channel", thread)
+		assert.Nil(t, err)
+		assert.NotEmpty(t, published.TaskID)
+
+		threads, err := api.ListThreads(ctx, "channel")
+		assert.Nil(t, err)
+		assert.Len(t, threads.Threads, 1)
+		threadID := threads.Threads[0].ThreadInfo.ThreadID
+		got, err := api.GetThread(ctx, "channel", threadID)
+		assert.Nil(t, err)
+		assert.Equal(t, "guild", got.GuildID)
+		content, err := dto.ParseRichText(got.ThreadInfo.Content)
+		assert.Nil(t, err)
+		assert.Equal(t, "本周动态", content.Paragraphs[0].Elems[0].Text.Text)
+
+		assert.Nil(t, api.DeleteThread(ctx, "channel", threadID))
+		_, err = api.GetThread(ctx, "channel", threadID)
+		assert.NotNil(t, err)
+		_, err = api.PublishThread(ctx, "unknown", thread)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestFakeOpenAPI_Copies(t *testing.T) {
+	f := fake.New()
+	ctx := context.Background()
+	channel := &dto.Channel{ID: "channel", GuildID: "guild"}
+	f.AddChannel(channel)
+	member := &dto.Member{User: &dto.User{ID: "u1"}, Roles: []string{"r1"}}
+	f.AddMember("guild", member)
+	thread := f.AddThread(&dto.Thread{ChannelID: "channel", ThreadInfo: dto.ThreadInfo{Title: "title"}})
+
+	channel.Name = "changed"
+	member.User.ID = "changed"
+	member.Roles[0] = "changed"
+	got, err := f.Channel(ctx, "channel")
+	assert.Nil(t, err)
+	assert.Empty(t, got.Name)
+	got.Name = "changed"
+	got, _ = f.Channel(ctx, "channel")
+	assert.Empty(t, got.Name)
+
+	m, err := f.GuildMember(ctx, "guild", "u1")
+	assert.Nil(t, err)
+	assert.Equal(t, "u1", m.User.ID)
+	assert.Equal(t, []string{"r1"}, m.Roles)
+	m.Roles[0] = "changed"
+	m, _ = f.GuildMember(ctx, "guild", "u1")
+	assert.Equal(t, []string{"r1"}, m.Roles)
+
+	thread.ThreadInfo.Title = "changed"
+	got2, err := f.GetThread(ctx, "channel", thread.ThreadInfo.ThreadID)
+	assert.Nil(t, err)
+	assert.Equal(t, "title", got2.ThreadInfo.Title)
+
+	msg := &dto.MessageToCreate{Content: "hello"}
+	posted, err := f.PostMessage(ctx, "channel", msg)
+	assert.Nil(t, err)
+	msg.Content = "changed"
+	posted.Content = "changed"
+	posted.Author.ID = "changed"
+	f.AssertPostedContent(t, "channel", "hello")
+	stored, _ := f.Message(ctx, "channel", posted.ID)
+	assert.Equal(t, "hello", stored.Content)
+	assert.Equal(t, "bot", stored.Author.ID)
+}
@@ -0,0 +1,101 @@
+package fake
+
+import (
+	"context"
+	"time"
+
+	"github.com/tencent-connect/botgo/dto"
+)
+
+// AddThread 添加论坛子频道中已存在的主题，未指定 ThreadID 时自动生成，返回添加的主题
+func (f *OpenAPI) AddThread(thread *dto.Thread) *dto.Thread {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	stored := cloneThread(thread)
+	if stored.ThreadInfo.ThreadID == "" {
+		stored.ThreadInfo.ThreadID = f.nextID()
+	}
+	f.threads[stored.ChannelID] = append(f.threads[stored.ChannelID], stored)
+	return cloneThread(stored)
+}
+
+// ListThreads 获取子频道下的主题列表
+func (f *OpenAPI) ListThreads(_ context.Context, channelID string) (*dto.ThreadsList, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("ListThreads", channelID); err != nil {
+		return nil, err
+	}
+	threads := make([]*dto.Thread, 0, len(f.threads[channelID]))
+	for _, thread := range f.threads[channelID] {
+		threads = append(threads, cloneThread(thread))
+	}
+	return &dto.ThreadsList{Threads: threads, IsFinish: 1}, nil
+}
+
+// GetThread 获取主题详情
+func (f *OpenAPI) GetThread(_ context.Context, channelID, threadID string) (*dto.Thread, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("GetThread", channelID, threadID); err != nil {
+		return nil, err
+	}
+	_, thread := f.findThread(channelID, threadID)
+	if thread == nil {
+		return nil, notFound("thread", threadID)
+	}
+	return cloneThread(thread), nil
+}
+
+// PublishThread 发表主题，fake 实现中不需要审核，直接出现在主题列表中
+func (f *OpenAPI) PublishThread(_ context.Context,
+	channelID string, thread *dto.ThreadToCreate) (*dto.ThreadPublished, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("PublishThread", channelID, thread); err != nil {
+		return nil, err
+	}
+	channel, ok := f.channels[channelID]
+	if !ok {
+		return nil, notFound("channel", channelID)
+	}
+	now := time.Now()
+	f.threads[channelID] = append(f.threads[channelID], &dto.Thread{
+		GuildID:   channel.GuildID,
+		ChannelID: channelID,
+		AuthorID:  f.me.ID,
+		ThreadInfo: dto.ThreadInfo{
+			ThreadID: f.nextID(),
+			Title:    thread.Title,
+			Content:  thread.Content,
+			DateTime: now.Format(time.RFC3339),
+		},
+	})
+	return &dto.ThreadPublished{TaskID: f.nextID(), CreateTime: now.Format(time.RFC3339)}, nil
+}
+
+// DeleteThread 删除主题
+func (f *OpenAPI) DeleteThread(_ context.Context, channelID, threadID string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("DeleteThread", channelID, threadID); err != nil {
+		return err
+	}
+	index, _ := f.findThread(channelID, threadID)
+	if index < 0 {
+		return notFound("thread", threadID)
+	}
+	threads := f.threads[channelID]
+	f.threads[channelID] = append(threads[:index:index], threads[index+1:]...)
+	return nil
+}
+
+// findThread 查找主题，返回下标，调用方需要持有锁
+func (f *OpenAPI) findThread(channelID, threadID string) (int, *dto.Thread) {
+	for i, thread := range f.threads[channelID] {
+		if thread.ThreadInfo.ThreadID == threadID {
+			return i, thread
+		}
+	}
+	return -1, nil
+}
@@ -0,0 +1,200 @@
+package fake
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"github.com/tencent-connect/botgo/dto"
+	"github.com/tencent-connect/botgo/errs"
+)
+
+// AddGuild 添加机器人加入的频道
+func (f *OpenAPI) AddGuild(guild *dto.Guild) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if _, ok := f.guilds[guild.ID]; !ok {
+		f.guildOrder = append(f.guildOrder, guild.ID)
+	}
+	f.guilds[guild.ID] = cloneGuild(guild)
+}
+
+// AddMember 添加频道成员，成员的 GuildID 为空时使用 guildID
+func (f *OpenAPI) AddMember(guildID string, member *dto.Member) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	stored := cloneMember(member)
+	if stored.GuildID == "" {
+		stored.GuildID = guildID
+	}
+	if f.members[guildID] == nil {
+		f.members[guildID] = map[string]*dto.Member{}
+	}
+	f.members[guildID][member.User.ID] = stored
+}
+
+// Guild 拉取频道信息
+func (f *OpenAPI) Guild(_ context.Context, guildID string) (*dto.Guild, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("Guild", guildID); err != nil {
+		return nil, err
+	}
+	guild, ok := f.guilds[guildID]
+	if !ok {
+		return nil, notFound("guild", guildID)
+	}
+	return cloneGuild(guild), nil
+}
+
+// GuildMember 拉取频道指定成员
+func (f *OpenAPI) GuildMember(_ context.Context, guildID, userID string) (*dto.Member, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("GuildMember", guildID, userID); err != nil {
+		return nil, err
+	}
+	member, ok := f.members[guildID][userID]
+	if !ok {
+		return nil, notFound("member", userID)
+	}
+	return cloneMember(member), nil
+}
+
+// GuildMembers 分页拉取频道成员，按照用户 ID 排序
+func (f *OpenAPI) GuildMembers(_ context.Context,
+	guildID string, pager *dto.GuildMembersPager) ([]*dto.Member, error) {
+	if pager == nil {
+		return nil, errs.ErrPagerIsNil
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("GuildMembers", guildID, pager); err != nil {
+		return nil, err
+	}
+	members := f.sortedMembers(guildID, "")
+	start := sort.Search(len(members), func(i int) bool {
+		return pager.After == "" || pager.After == "0" || members[i].User.ID > pager.After
+	})
+	members = members[start:]
+	return cloneMembers(members[:limitOf(pager.Limit, 1, len(members))]), nil
+}
+
+// GuildRoleMembers 分页拉取频道身份组成员，返回下一页的 start_index，没有更多数据时为空
+func (f *OpenAPI) GuildRoleMembers(_ context.Context, guildID string, roleID string,
+	pager *dto.GuildRoleMembersPager) ([]*dto.Member, string, error) {
+	if pager == nil {
+		return nil, "", errs.ErrPagerIsNil
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("GuildRoleMembers", guildID, roleID, pager); err != nil {
+		return nil, "", err
+	}
+	members := f.sortedMembers(guildID, roleID)
+	start, _ := strconv.Atoi(pager.StartIndex)
+	if start > len(members) {
+		start = len(members)
+	}
+	members = members[start:]
+	limit := limitOf(pager.Limit, 1, len(members))
+	next := ""
+	if limit < len(members) {
+		next = strconv.Itoa(start + limit)
+	}
+	return cloneMembers(members[:limit]), next, nil
+}
+
+// DeleteGuildMember 删除频道成员
+func (f *OpenAPI) DeleteGuildMember(_ context.Context,
+	guildID, userID string, opts ...dto.MemberDeleteOption) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("DeleteGuildMember", guildID, userID, opts); err != nil {
+		return err
+	}
+	if _, ok := f.members[guildID][userID]; !ok {
+		return notFound("member", userID)
+	}
+	delete(f.members[guildID], userID)
+	return nil
+}
+
+// GuildMute 频道禁言
+func (f *OpenAPI) GuildMute(_ context.Context, guildID string, mute *dto.UpdateGuildMute) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("GuildMute", guildID, mute); err != nil {
+		return err
+	}
+	if _, ok := f.guilds[guildID]; !ok {
+		return notFound("guild", guildID)
+	}
+	return nil
+}
+
+// MemberMute 频道指定单个成员禁言
+func (f *OpenAPI) MemberMute(_ context.Context, guildID, userID string, mute *dto.UpdateGuildMute) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("MemberMute", guildID, userID, mute); err != nil {
+		return err
+	}
+	if _, ok := f.members[guildID][userID]; !ok {
+		return notFound("member", userID)
+	}
+	return nil
+}
+
+// MultiMemberMute 频道指定批量成员禁言，返回存在的成员
+func (f *OpenAPI) MultiMemberMute(_ context.Context, guildID string,
+	mute *dto.UpdateGuildMute) (*dto.UpdateGuildMuteResponse, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("MultiMemberMute", guildID, mute); err != nil {
+		return nil, err
+	}
+	resp := &dto.UpdateGuildMuteResponse{}
+	for _, userID := range mute.UserIDs {
+		if _, ok := f.members[guildID][userID]; ok {
+			resp.UserIDs = append(resp.UserIDs, userID)
+		}
+	}
+	return resp, nil
+}
+
+// sortedMembers 返回按照用户 ID 排序的成员，roleID 不为空时只返回拥有该身份组的成员
+// 调用方需要持有锁
+func (f *OpenAPI) sortedMembers(guildID, roleID string) []*dto.Member {
+	members := make([]*dto.Member, 0, len(f.members[guildID]))
+	for _, member := range f.members[guildID] {
+		if roleID == "" || hasRole(member, roleID) {
+			members = append(members, member)
+		}
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].User.ID < members[j].User.ID
+	})
+	return members
+}
+
+func hasRole(member *dto.Member, roleID string) bool {
+	for _, id := range member.Roles {
+		if id == roleID {
+			return true
+		}
+	}
+	return false
+}
+
+// limitOf 解析分页大小，未指定时使用 defaultLimit，不超过 max
+func limitOf(limit string, defaultLimit, max int) int {
+	n, err := strconv.Atoi(limit)
+	if err != nil || n <= 0 {
+		n = defaultLimit
+	}
+	if n > max {
+		n = max
+	}
+	return n
+}
@@ -0,0 +1,286 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/tencent-connect/botgo/dto"
+	"github.com/tencent-connect/botgo/errs"
+	"github.com/tencent-connect/botgo/openapi"
+)
+
+// 记录发送消息的目标前缀，子频道消息直接使用 channelID
+const (
+	userTargetPrefix  = "users/"
+	groupTargetPrefix = "groups/"
+	dmTargetPrefix    = "dms/"
+)
+
+// AddMessage 添加一条已存在的消息，未指定 ID 时自动生成，返回添加的消息
+func (f *OpenAPI) AddMessage(msg *dto.Message) *dto.Message {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	stored := cloneMessage(msg)
+	if stored.ID == "" {
+		stored.ID = f.nextID()
+	}
+	f.messages[stored.ChannelID] = append(f.messages[stored.ChannelID], stored)
+	return cloneMessage(stored)
+}
+
+// Message 拉取单条消息
+func (f *OpenAPI) Message(_ context.Context, channelID string, messageID string) (*dto.Message, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("Message", channelID, messageID); err != nil {
+		return nil, err
+	}
+	_, msg := f.findMessage(channelID, messageID)
+	if msg == nil {
+		return nil, notFound("message", messageID)
+	}
+	return cloneMessage(msg), nil
+}
+
+// Messages 拉取消息列表，按照 pager 的类型返回指定消息前后的消息
+func (f *OpenAPI) Messages(_ context.Context, channelID string, pager *dto.MessagesPager) ([]*dto.Message, error) {
+	if pager == nil {
+		return nil, errs.ErrPagerIsNil
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("Messages", channelID, pager); err != nil {
+		return nil, err
+	}
+	messages := f.messages[channelID]
+	limit := 20
+	if n, err := strconv.Atoi(pager.Limit); err == nil && n > 0 {
+		limit = n
+	}
+	index := -1
+	if pager.ID != "" {
+		if index, _ = f.findMessage(channelID, pager.ID); index < 0 {
+			return nil, notFound("message", pager.ID)
+		}
+	}
+	start, end := messageRange(len(messages), index, pager.Type, limit)
+	return cloneMessages(messages[start:end]), nil
+}
+
+// messageRange 计算分页拉取消息的范围，index 为 pager 中指定消息的下标，未指定时为 -1
+func messageRange(total, index int, pagerType dto.MessagePagerType, limit int) (int, int) {
+	start, end := 0, total
+	if index >= 0 {
+		switch pagerType {
+		case dto.MPTBefore:
+			end = index
+		case dto.MPTAfter:
+			start = index + 1
+		case dto.MPTAround:
+			start, end = index-limit/2, index+limit/2+1
+		}
+	}
+	if start < 0 {
+		start = 0
+	}
+	if end > total {
+		end = total
+	}
+	if end-start > limit {
+		if pagerType == dto.MPTAfter {
+			end = start + limit
+		} else {
+			start = end - limit
+		}
+	}
+	return start, end
+}
+
+// PostMessage 发消息
+func (f *OpenAPI) PostMessage(_ context.Context, channelID string, msg *dto.MessageToCreate) (*dto.Message, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("PostMessage", channelID, msg); err != nil {
+		return nil, err
+	}
+	channel, ok := f.channels[channelID]
+	if !ok {
+		return nil, notFound("channel", channelID)
+	}
+	return cloneMessage(f.post(channelID, channel.GuildID, msg)), nil
+}
+
+// PatchMessage 编辑消息
+func (f *OpenAPI) PatchMessage(_ context.Context,
+	channelID string, messageID string, msg *dto.MessageToCreate) (*dto.Message, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("PatchMessage", channelID, messageID, msg); err != nil {
+		return nil, err
+	}
+	_, message := f.findMessage(channelID, messageID)
+	if message == nil {
+		return nil, notFound("message", messageID)
+	}
+	message.Content = msg.Content
+	message.Ark = msg.Ark
+	message.EditedTimestamp = now()
+	if msg.Embed != nil {
+		message.Embeds = []*dto.Embed{msg.Embed}
+	}
+	return cloneMessage(message), nil
+}
+
+// RetractMessage 撤回消息
+func (f *OpenAPI) RetractMessage(_ context.Context,
+	channelID, msgID string, options ...openapi.RetractMessageOption) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("RetractMessage", channelID, msgID, options); err != nil {
+		return err
+	}
+	return f.deleteMessage(channelID, msgID)
+}
+
+// PostSettingGuide 发送设置引导
+func (f *OpenAPI) PostSettingGuide(_ context.Context, channelID string, atUserIDs []string) (*dto.Message, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("PostSettingGuide", channelID, atUserIDs); err != nil {
+		return nil, err
+	}
+	channel, ok := f.channels[channelID]
+	if !ok {
+		return nil, notFound("channel", channelID)
+	}
+	var content string
+	for _, userID := range atUserIDs {
+		content += fmt.Sprintf("<@%s>", userID)
+	}
+	return cloneMessage(f.post(channelID, channel.GuildID, &dto.MessageToCreate{Content: content})), nil
+}
+
+// PostUserMessage 发送单聊消息
+func (f *OpenAPI) PostUserMessage(_ context.Context, openID string, msg *dto.MessageToCreate) (*dto.Message, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("PostUserMessage", openID, msg); err != nil {
+		return nil, err
+	}
+	return cloneMessage(f.post(userTargetPrefix+openID, "", msg)), nil
+}
+
+// PostRichMediaToUser 上传单聊富媒体
+func (f *OpenAPI) PostRichMediaToUser(_ context.Context,
+	openID string, media *dto.Media) (*dto.MediaReturnParam, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("PostRichMediaToUser", openID, media); err != nil {
+		return nil, err
+	}
+	return f.upload(userTargetPrefix+openID, media), nil
+}
+
+// RetractUserMessage 撤回单聊消息
+func (f *OpenAPI) RetractUserMessage(_ context.Context,
+	openID, msgID string, options ...openapi.RetractMessageOption) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("RetractUserMessage", openID, msgID, options); err != nil {
+		return err
+	}
+	return f.deleteMessage(userTargetPrefix+openID, msgID)
+}
+
+// PostGroupMessage 发送群聊消息
+func (f *OpenAPI) PostGroupMessage(_ context.Context,
+	groupOpenID string, msg *dto.MessageToCreate) (*dto.Message, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("PostGroupMessage", groupOpenID, msg); err != nil {
+		return nil, err
+	}
+	message := f.post(groupTargetPrefix+groupOpenID, "", msg)
+	message.GroupOpenID = groupOpenID
+	return cloneMessage(message), nil
+}
+
+// PostRichMediaToGroup 上传群聊富媒体
+func (f *OpenAPI) PostRichMediaToGroup(_ context.Context,
+	groupOpenID string, media *dto.Media) (*dto.MediaReturnParam, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("PostRichMediaToGroup", groupOpenID, media); err != nil {
+		return nil, err
+	}
+	return f.upload(groupTargetPrefix+groupOpenID, media), nil
+}
+
+// RetractGroupMessage 撤回群聊消息
+func (f *OpenAPI) RetractGroupMessage(_ context.Context,
+	groupOpenID, msgID string, options ...openapi.RetractMessageOption) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("RetractGroupMessage", groupOpenID, msgID, options); err != nil {
+		return err
+	}
+	return f.deleteMessage(groupTargetPrefix+groupOpenID, msgID)
+}
+
+// post 保存发送的消息，返回保存的消息，调用方需要持有锁
+func (f *OpenAPI) post(target, guildID string, msg *dto.MessageToCreate) *dto.Message {
+	f.posted[target] = append(f.posted[target], cloneMessageToCreate(msg))
+	message := &dto.Message{
+		ID:           f.nextID(),
+		ChannelID:    target,
+		GuildID:      guildID,
+		Content:      msg.Content,
+		Timestamp:    now(),
+		Author:       cloneUser(f.me),
+		Ark:          msg.Ark,
+		SeqInChannel: strconv.Itoa(len(f.messages[target]) + 1),
+	}
+	if msg.Embed != nil {
+		message.Embeds = []*dto.Embed{msg.Embed}
+	}
+	if msg.Image != "" {
+		message.Attachments = []*dto.MessageAttachment{{URL: msg.Image}}
+	}
+	if msg.MessageReference != nil {
+		ref := *msg.MessageReference
+		message.MessageReference = &ref
+	}
+	f.messages[target] = append(f.messages[target], message)
+	return message
+}
+
+// upload 生成富媒体的 file_info，调用方需要持有锁
+func (f *OpenAPI) upload(target string, media *dto.Media) *dto.MediaReturnParam {
+	id := f.nextID()
+	if media.SendMessage {
+		f.post(target, "", &dto.MessageToCreate{Type: dto.MsgTypeMedia, Media: media})
+	}
+	return &dto.MediaReturnParam{UUID: id, Info: id}
+}
+
+// findMessage 查找消息，返回下标，调用方需要持有锁
+func (f *OpenAPI) findMessage(channelID, messageID string) (int, *dto.Message) {
+	for i, msg := range f.messages[channelID] {
+		if msg.ID == messageID {
+			return i, msg
+		}
+	}
+	return -1, nil
+}
+
+// deleteMessage 删除消息，调用方需要持有锁
+func (f *OpenAPI) deleteMessage(channelID, messageID string) error {
+	index, _ := f.findMessage(channelID, messageID)
+	if index < 0 {
+		return notFound("message", messageID)
+	}
+	messages := f.messages[channelID]
+	f.messages[channelID] = append(messages[:index:index], messages[index+1:]...)
+	return nil
+}
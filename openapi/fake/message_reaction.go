@@ -0,0 +1,97 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/tencent-connect/botgo/dto"
+)
+
+// AddReaction 添加其他用户对消息的表情表态
+func (f *OpenAPI) AddReaction(channelID, messageID string, emoji dto.Emoji, user *dto.User) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	key := reactionKey(channelID, messageID, emoji)
+	f.reactions[key] = append(f.reactions[key], cloneUser(user))
+}
+
+// CreateMessageReaction 对消息发表表情表态
+func (f *OpenAPI) CreateMessageReaction(_ context.Context, channelID, messageID string, emoji dto.Emoji) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("CreateMessageReaction", channelID, messageID, emoji); err != nil {
+		return err
+	}
+	if _, msg := f.findMessage(channelID, messageID); msg == nil {
+		return notFound("message", messageID)
+	}
+	key := reactionKey(channelID, messageID, emoji)
+	if f.findReaction(key, f.me.ID) < 0 {
+		f.reactions[key] = append(f.reactions[key], cloneUser(f.me))
+	}
+	return nil
+}
+
+// DeleteOwnMessageReaction 删除自己的消息表情表态
+func (f *OpenAPI) DeleteOwnMessageReaction(_ context.Context, channelID, messageID string, emoji dto.Emoji) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("DeleteOwnMessageReaction", channelID, messageID, emoji); err != nil {
+		return err
+	}
+	key := reactionKey(channelID, messageID, emoji)
+	index := f.findReaction(key, f.me.ID)
+	if index < 0 {
+		return notFound("reaction", emoji.ID)
+	}
+	users := f.reactions[key]
+	f.reactions[key] = append(users[:index:index], users[index+1:]...)
+	return nil
+}
+
+// GetMessageReactionUsers 获取消息表情表态用户列表，cookie 为下一页的起始下标
+func (f *OpenAPI) GetMessageReactionUsers(_ context.Context, channelID, messageID string, emoji dto.Emoji,
+	pager *dto.MessageReactionPager) (*dto.MessageReactionUsers, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("GetMessageReactionUsers", channelID, messageID, emoji, pager); err != nil {
+		return nil, err
+	}
+	users := f.reactions[reactionKey(channelID, messageID, emoji)]
+	start := 0
+	limit := 20
+	if pager != nil {
+		start, _ = strconv.Atoi(pager.Cookie)
+		limit = limitOf(pager.Limit, limit, len(users))
+	}
+	if start > len(users) {
+		start = len(users)
+	}
+	end := start + limit
+	if end > len(users) {
+		end = len(users)
+	}
+	result := &dto.MessageReactionUsers{
+		Users: cloneUsers(users[start:end]),
+		IsEnd: end >= len(users),
+	}
+	if !result.IsEnd {
+		result.Cookie = strconv.Itoa(end)
+	}
+	return result, nil
+}
+
+// findReaction 查找用户的表态，返回下标，调用方需要持有锁
+func (f *OpenAPI) findReaction(key, userID string) int {
+	for i, user := range f.reactions[key] {
+		if user.ID == userID {
+			return i
+		}
+	}
+	return -1
+}
+
+func reactionKey(channelID, messageID string, emoji dto.Emoji) string {
+	return fmt.Sprintf("%s/%s/%d/%s", channelID, messageID, emoji.Type, emoji.ID)
+}
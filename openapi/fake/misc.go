@@ -0,0 +1,152 @@
+package fake
+
+import (
+	"context"
+
+	"github.com/tencent-connect/botgo/dto"
+)
+
+// SetMessageSetting 设置频道的消息频率设置
+func (f *OpenAPI) SetMessageSetting(guildID string, setting *dto.MessageSetting) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.settings[guildID] = cloneMessageSetting(setting)
+}
+
+// PostAudio 执行音频播放，暂停等操作
+func (f *OpenAPI) PostAudio(_ context.Context, channelID string, value *dto.AudioControl) (*dto.AudioControl, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("PostAudio", channelID, value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// PutMic 机器人上麦
+func (f *OpenAPI) PutMic(_ context.Context, channelID string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.call("PutMic", channelID)
+}
+
+// DeleteMic 机器人下麦
+func (f *OpenAPI) DeleteMic(_ context.Context, channelID string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.call("DeleteMic", channelID)
+}
+
+// GetAPIPermissions 获取频道可用权限列表，fake 实现返回空列表
+func (f *OpenAPI) GetAPIPermissions(_ context.Context, guildID string) (*dto.APIPermissions, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("GetAPIPermissions", guildID); err != nil {
+		return nil, err
+	}
+	return &dto.APIPermissions{APIList: []*dto.APIPermission{}}, nil
+}
+
+// RequireAPIPermissions 创建频道 API 接口权限授权链接
+func (f *OpenAPI) RequireAPIPermissions(_ context.Context,
+	guildID string, demand *dto.APIPermissionDemandToCreate) (*dto.APIPermissionDemand, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("RequireAPIPermissions", guildID, demand); err != nil {
+		return nil, err
+	}
+	return &dto.APIPermissionDemand{
+		GuildID:     guildID,
+		ChannelID:   demand.ChannelID,
+		APIIdentify: demand.APIIdentify,
+		Desc:        demand.Desc,
+	}, nil
+}
+
+// PutInteraction 更新互动信息
+func (f *OpenAPI) PutInteraction(_ context.Context, interactionID string, body string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.call("PutInteraction", interactionID, body)
+}
+
+// CreateSession 创建 http 事件网关 session
+func (f *OpenAPI) CreateSession(_ context.Context, identity dto.HTTPIdentity) (*dto.HTTPReady, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("CreateSession", identity); err != nil {
+		return nil, err
+	}
+	ready := &dto.HTTPReady{
+		Version:   1,
+		SessionID: f.nextID(),
+		Shard:     identity.Shards,
+	}
+	ready.Bot.ID = f.me.ID
+	ready.Bot.Username = f.me.Username
+	f.sessions[ready.SessionID] = &dto.HTTPSession{
+		SessionID:   ready.SessionID,
+		CallbackURL: identity.Callback,
+		Intents:     int64(identity.Intents),
+		State:       "active",
+		Shards:      [2]int64{int64(identity.Shards[0]), int64(identity.Shards[1])},
+	}
+	return ready, nil
+}
+
+// CheckSessions 检查 http 事件网关 session
+func (f *OpenAPI) CheckSessions(_ context.Context) ([]*dto.HTTPSession, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("CheckSessions"); err != nil {
+		return nil, err
+	}
+	return f.sessionList(), nil
+}
+
+// SessionList 拉取 http 事件网关 session 列表
+func (f *OpenAPI) SessionList(_ context.Context) ([]*dto.HTTPSession, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("SessionList"); err != nil {
+		return nil, err
+	}
+	return f.sessionList(), nil
+}
+
+// RemoveSession 删除 http 事件网关 session
+func (f *OpenAPI) RemoveSession(_ context.Context, sessionID string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("RemoveSession", sessionID); err != nil {
+		return err
+	}
+	if _, ok := f.sessions[sessionID]; !ok {
+		return notFound("session", sessionID)
+	}
+	delete(f.sessions, sessionID)
+	return nil
+}
+
+// GetMessageSetting 获取频道消息频率设置
+func (f *OpenAPI) GetMessageSetting(_ context.Context, guildID string) (*dto.MessageSetting, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("GetMessageSetting", guildID); err != nil {
+		return nil, err
+	}
+	if setting, ok := f.settings[guildID]; ok {
+		return cloneMessageSetting(setting), nil
+	}
+	return &dto.MessageSetting{}, nil
+}
+
+// sessionList 返回所有 session，调用方需要持有锁
+func (f *OpenAPI) sessionList() []*dto.HTTPSession {
+	sessions := make([]*dto.HTTPSession, 0, len(f.sessions))
+	for _, session := range f.sessions {
+		copied := *session
+		sessions = append(sessions, &copied)
+	}
+	return sessions
+}
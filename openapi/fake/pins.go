@@ -0,0 +1,65 @@
+package fake
+
+import (
+	"context"
+
+	"github.com/tencent-connect/botgo/dto"
+)
+
+// AddPins 添加精华消息
+func (f *OpenAPI) AddPins(_ context.Context, channelID string, messageID string) (*dto.PinsMessage, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("AddPins", channelID, messageID); err != nil {
+		return nil, err
+	}
+	if _, msg := f.findMessage(channelID, messageID); msg == nil {
+		return nil, notFound("message", messageID)
+	}
+	f.pins[channelID] = append(removeString(f.pins[channelID], messageID), messageID)
+	return f.pinsMessage(channelID), nil
+}
+
+// DeletePins 删除精华消息
+func (f *OpenAPI) DeletePins(_ context.Context, channelID, messageID string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("DeletePins", channelID, messageID); err != nil {
+		return err
+	}
+	f.pins[channelID] = removeString(f.pins[channelID], messageID)
+	return nil
+}
+
+// CleanPins 清除全部精华消息
+func (f *OpenAPI) CleanPins(_ context.Context, channelID string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("CleanPins", channelID); err != nil {
+		return err
+	}
+	delete(f.pins, channelID)
+	return nil
+}
+
+// GetPins 获取精华消息
+func (f *OpenAPI) GetPins(_ context.Context, channelID string) (*dto.PinsMessage, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("GetPins", channelID); err != nil {
+		return nil, err
+	}
+	return f.pinsMessage(channelID), nil
+}
+
+// pinsMessage 生成精华消息对象，调用方需要持有锁
+func (f *OpenAPI) pinsMessage(channelID string) *dto.PinsMessage {
+	pins := &dto.PinsMessage{
+		ChannelID:  channelID,
+		MessageIDs: append([]string{}, f.pins[channelID]...),
+	}
+	if channel, ok := f.channels[channelID]; ok {
+		pins.GuildID = channel.GuildID
+	}
+	return pins
+}
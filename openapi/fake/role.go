@@ -0,0 +1,143 @@
+package fake
+
+import (
+	"context"
+
+	"github.com/tencent-connect/botgo/dto"
+)
+
+// AddRole 添加频道身份组，未指定 ID 时自动生成，返回添加的身份组
+func (f *OpenAPI) AddRole(guildID string, role *dto.Role) *dto.Role {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	stored := cloneRole(role)
+	if stored.ID == "" {
+		stored.ID = dto.RoleID(f.nextID())
+	}
+	f.roles[guildID] = append(f.roles[guildID], stored)
+	return cloneRole(stored)
+}
+
+// Roles 拉取频道身份组列表
+func (f *OpenAPI) Roles(_ context.Context, guildID string) (*dto.GuildRoles, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("Roles", guildID); err != nil {
+		return nil, err
+	}
+	roles := make([]*dto.Role, 0, len(f.roles[guildID]))
+	for _, role := range f.roles[guildID] {
+		roles = append(roles, cloneRole(role))
+	}
+	return &dto.GuildRoles{GuildID: guildID, Roles: roles}, nil
+}
+
+// PostRole 创建频道身份组
+func (f *OpenAPI) PostRole(_ context.Context, guildID string, role *dto.Role) (*dto.UpdateResult, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("PostRole", guildID, role); err != nil {
+		return nil, err
+	}
+	created := cloneRole(role)
+	created.ID = dto.RoleID(f.nextID())
+	if created.Color == 0 {
+		created.Color = dto.DefaultColor
+	}
+	f.roles[guildID] = append(f.roles[guildID], created)
+	return &dto.UpdateResult{RoleID: created.ID, GuildID: guildID, Role: cloneRole(created)}, nil
+}
+
+// PatchRole 修改频道身份组
+func (f *OpenAPI) PatchRole(_ context.Context,
+	guildID string, roleID dto.RoleID, role *dto.Role) (*dto.UpdateResult, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("PatchRole", guildID, roleID, role); err != nil {
+		return nil, err
+	}
+	_, existed := f.findRole(guildID, roleID)
+	if existed == nil {
+		return nil, notFound("role", string(roleID))
+	}
+	existed.Name = role.Name
+	existed.Color = role.Color
+	existed.Hoist = role.Hoist
+	if existed.Color == 0 {
+		existed.Color = dto.DefaultColor
+	}
+	return &dto.UpdateResult{RoleID: roleID, GuildID: guildID, Role: cloneRole(existed)}, nil
+}
+
+// DeleteRole 删除频道身份组
+func (f *OpenAPI) DeleteRole(_ context.Context, guildID string, roleID dto.RoleID) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("DeleteRole", guildID, roleID); err != nil {
+		return err
+	}
+	index, _ := f.findRole(guildID, roleID)
+	if index < 0 {
+		return notFound("role", string(roleID))
+	}
+	roles := f.roles[guildID]
+	f.roles[guildID] = append(roles[:index:index], roles[index+1:]...)
+	for _, member := range f.members[guildID] {
+		member.Roles = removeString(member.Roles, string(roleID))
+	}
+	return nil
+}
+
+// MemberAddRole 添加成员身份组
+func (f *OpenAPI) MemberAddRole(_ context.Context,
+	guildID string, roleID dto.RoleID, userID string, value *dto.MemberAddRoleBody) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("MemberAddRole", guildID, roleID, userID, value); err != nil {
+		return err
+	}
+	member, ok := f.members[guildID][userID]
+	if !ok {
+		return notFound("member", userID)
+	}
+	if !hasRole(member, string(roleID)) {
+		member.Roles = append(member.Roles, string(roleID))
+	}
+	return nil
+}
+
+// MemberDeleteRole 删除成员身份组
+func (f *OpenAPI) MemberDeleteRole(_ context.Context,
+	guildID string, roleID dto.RoleID, userID string, value *dto.MemberAddRoleBody) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("MemberDeleteRole", guildID, roleID, userID, value); err != nil {
+		return err
+	}
+	member, ok := f.members[guildID][userID]
+	if !ok {
+		return notFound("member", userID)
+	}
+	member.Roles = removeString(member.Roles, string(roleID))
+	return nil
+}
+
+// findRole 查找身份组，返回下标，调用方需要持有锁
+func (f *OpenAPI) findRole(guildID string, roleID dto.RoleID) (int, *dto.Role) {
+	for i, role := range f.roles[guildID] {
+		if role.ID == roleID {
+			return i, role
+		}
+	}
+	return -1, nil
+}
+
+func removeString(values []string, target string) []string {
+	result := values[:0]
+	for _, v := range values {
+		if v != target {
+			result = append(result, v)
+		}
+	}
+	return result
+}
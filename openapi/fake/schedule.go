@@ -0,0 +1,107 @@
+package fake
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/tencent-connect/botgo/dto"
+)
+
+// ListSchedules 查询子频道下 since 开始当天的日程列表，since 为 0 时返回当天的日程列表
+func (f *OpenAPI) ListSchedules(_ context.Context, channelID string, since uint64) ([]*dto.Schedule, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("ListSchedules", channelID, since); err != nil {
+		return nil, err
+	}
+	day := time.Now()
+	if since != 0 {
+		day = time.Unix(0, int64(since)*int64(time.Millisecond))
+	}
+	begin := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	end := begin.AddDate(0, 0, 1)
+	schedules := make([]*dto.Schedule, 0)
+	for _, schedule := range f.schedules[channelID] {
+		start, err := strconv.ParseInt(schedule.StartTimestamp, 10, 64)
+		if err != nil {
+			continue
+		}
+		if t := time.Unix(0, start*int64(time.Millisecond)); !t.Before(begin) && t.Before(end) {
+			schedules = append(schedules, cloneSchedule(schedule))
+		}
+	}
+	return schedules, nil
+}
+
+// GetSchedule 获取单个日程信息
+func (f *OpenAPI) GetSchedule(_ context.Context, channelID, scheduleID string) (*dto.Schedule, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("GetSchedule", channelID, scheduleID); err != nil {
+		return nil, err
+	}
+	_, schedule := f.findSchedule(channelID, scheduleID)
+	if schedule == nil {
+		return nil, notFound("schedule", scheduleID)
+	}
+	return cloneSchedule(schedule), nil
+}
+
+// CreateSchedule 创建日程
+func (f *OpenAPI) CreateSchedule(_ context.Context,
+	channelID string, schedule *dto.Schedule) (*dto.Schedule, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("CreateSchedule", channelID, schedule); err != nil {
+		return nil, err
+	}
+	created := cloneSchedule(schedule)
+	created.ID = f.nextID()
+	f.schedules[channelID] = append(f.schedules[channelID], created)
+	return cloneSchedule(created), nil
+}
+
+// ModifySchedule 修改日程
+func (f *OpenAPI) ModifySchedule(_ context.Context,
+	channelID, scheduleID string, schedule *dto.Schedule) (*dto.Schedule, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("ModifySchedule", channelID, scheduleID, schedule); err != nil {
+		return nil, err
+	}
+	index, _ := f.findSchedule(channelID, scheduleID)
+	if index < 0 {
+		return nil, notFound("schedule", scheduleID)
+	}
+	modified := cloneSchedule(schedule)
+	modified.ID = scheduleID
+	f.schedules[channelID][index] = modified
+	return cloneSchedule(modified), nil
+}
+
+// DeleteSchedule 删除日程
+func (f *OpenAPI) DeleteSchedule(_ context.Context, channelID, scheduleID string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("DeleteSchedule", channelID, scheduleID); err != nil {
+		return err
+	}
+	index, _ := f.findSchedule(channelID, scheduleID)
+	if index < 0 {
+		return notFound("schedule", scheduleID)
+	}
+	schedules := f.schedules[channelID]
+	f.schedules[channelID] = append(schedules[:index:index], schedules[index+1:]...)
+	return nil
+}
+
+// findSchedule 查找日程，返回下标，调用方需要持有锁
+func (f *OpenAPI) findSchedule(channelID, scheduleID string) (int, *dto.Schedule) {
+	for i, schedule := range f.schedules[channelID] {
+		if schedule.ID == scheduleID {
+			return i, schedule
+		}
+	}
+	return -1, nil
+}
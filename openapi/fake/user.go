@@ -0,0 +1,84 @@
+package fake
+
+import (
+	"context"
+
+	"github.com/tencent-connect/botgo/dto"
+	"github.com/tencent-connect/botgo/errs"
+)
+
+// SetMe 设置机器人自身的用户信息，也会作为发送消息的 author
+func (f *OpenAPI) SetMe(user *dto.User) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.me = cloneUser(user)
+}
+
+// SetWebsocketAP 设置 WS 接口返回的接入点信息，可以指向本地的网关模拟服务
+func (f *OpenAPI) SetWebsocketAP(ap *dto.WebsocketAP) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	copied := *ap
+	f.ws = &copied
+}
+
+// WS 获取带分片 WSS 接入点
+func (f *OpenAPI) WS(_ context.Context, params map[string]string, body string) (*dto.WebsocketAP, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("WS", params, body); err != nil {
+		return nil, err
+	}
+	ap := *f.ws
+	return &ap, nil
+}
+
+// Me 拉取当前用户的信息
+func (f *OpenAPI) Me(_ context.Context) (*dto.User, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("Me"); err != nil {
+		return nil, err
+	}
+	return cloneUser(f.me), nil
+}
+
+// MeGuilds 拉取当前用户加入的频道列表，按照添加顺序分页
+func (f *OpenAPI) MeGuilds(_ context.Context, pager *dto.GuildPager) ([]*dto.Guild, error) {
+	if pager == nil {
+		return nil, errs.ErrPagerIsNil
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call("MeGuilds", pager); err != nil {
+		return nil, err
+	}
+	ids := f.guildOrder
+	if pager.After != "" {
+		ids = ids[indexOf(ids, pager.After)+1:]
+	} else if pager.Before != "" {
+		if i := indexOf(ids, pager.Before); i >= 0 {
+			ids = ids[:i]
+		}
+	}
+	limit := limitOf(pager.Limit, 100, len(ids))
+	if pager.After == "" && pager.Before != "" {
+		ids = ids[len(ids)-limit:]
+	} else {
+		ids = ids[:limit]
+	}
+	guilds := make([]*dto.Guild, 0, len(ids))
+	for _, id := range ids {
+		guilds = append(guilds, cloneGuild(f.guilds[id]))
+	}
+	return guilds, nil
+}
+
+func indexOf(values []string, target string) int {
+	for i, v := range values {
+		if v == target {
+			return i
+		}
+	}
+	return -1
+}
@@ -37,12 +37,14 @@ type OpenAPI interface {
 	WebhookAPI
 	InteractionAPI
 	MessageSettingAPI
+	ForumAPI
 }
 
 // Base 基础能力接口
 type Base interface {
 	Version() APIVersion
-	Setup(token *token.Token, inSandbox bool) OpenAPI
+	// Setup 创建一个新的实例，opts 用于指定限流、重试等可选配置
+	Setup(token *token.Token, inSandbox bool, opts ...Option) OpenAPI
 	// WithTimeout 设置请求接口超时时间
 	WithTimeout(duration time.Duration) OpenAPI
 	// Transport 透传请求，如果 sdk 没有及时跟进新的接口的变更，可以使用该方法进行透传，openapi 实现时可以按需选择是否实现该接口
@@ -77,8 +79,12 @@ type MessageAPI interface {
 
 	PostUserMessage(ctx context.Context, openID string, msg *dto.MessageToCreate) (*dto.Message, error)
 	PostRichMediaToUser(ctx context.Context, openID string, media *dto.Media) (*dto.MediaReturnParam, error)
+	// RetractUserMessage 撤回机器人发送的单聊消息
+	RetractUserMessage(ctx context.Context, openID, msgID string, options ...RetractMessageOption) error
 	PostGroupMessage(ctx context.Context, groupOpenID string, msg *dto.MessageToCreate) (*dto.Message, error)
 	PostRichMediaToGroup(ctx context.Context, groupOpenID string, media *dto.Media) (*dto.MediaReturnParam, error)
+	// RetractGroupMessage 撤回机器人发送的群聊消息
+	RetractGroupMessage(ctx context.Context, groupOpenID, msgID string, options ...RetractMessageOption) error
 }
 
 // GuildAPI guild 相关接口
@@ -256,3 +262,15 @@ type WebhookAPI interface {
 type MessageSettingAPI interface {
 	GetMessageSetting(ctx context.Context, guildID string) (*dto.MessageSetting, error)
 }
+
+// ForumAPI 论坛接口
+type ForumAPI interface {
+	// ListThreads 获取子频道下的主题列表
+	ListThreads(ctx context.Context, channelID string) (*dto.ThreadsList, error)
+	// GetThread 获取主题详情
+	GetThread(ctx context.Context, channelID, threadID string) (*dto.Thread, error)
+	// PublishThread 发表主题，主题需要审核，审核通过后才会出现在主题列表中
+	PublishThread(ctx context.Context, channelID string, thread *dto.ThreadToCreate) (*dto.ThreadPublished, error)
+	// DeleteThread 删除主题
+	DeleteThread(ctx context.Context, channelID, threadID string) error
+}
@@ -0,0 +1,104 @@
+package openapi
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter 按路由限流的限流器，路由由请求方法与 uri 模板组成，如 `POST /channels/{channel_id}/messages`
+type Limiter interface {
+	// Wait 等待直到 route 允许发起请求，ctx 结束时返回 ctx 的错误
+	Wait(ctx context.Context, route string) error
+	// Backoff 服务端返回了限流提示，在 retryAfter 时间内不再放行 route 的请求
+	Backoff(route string, retryAfter time.Duration)
+}
+
+// RouteLimiter 基于令牌桶的路由限流器，每个路由独立计算
+type RouteLimiter struct {
+	rate    float64 // 每秒生成的令牌数，为 0 时不限速，只处理服务端的限流提示
+	burst   float64 // 令牌桶容量
+	lock    sync.Mutex
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	tokens       float64
+	last         time.Time
+	blockedUntil time.Time
+}
+
+// NewRouteLimiter 创建路由限流器，rate 为每个路由每秒允许的请求数，burst 为允许的突发请求数
+// rate 为 0 时不主动限速，只根据 Backoff 的提示暂停对应路由的请求
+func NewRouteLimiter(rate float64, burst int) *RouteLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RouteLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: map[string]*bucket{},
+	}
+}
+
+// Wait 等待直到 route 允许发起请求
+func (l *RouteLimiter) Wait(ctx context.Context, route string) error {
+	for {
+		wait := l.reserve(route, time.Now())
+		if wait <= 0 {
+			return nil
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Backoff 在 retryAfter 时间内暂停 route 的请求
+func (l *RouteLimiter) Backoff(route string, retryAfter time.Duration) {
+	if retryAfter <= 0 {
+		return
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	b := l.bucket(route, time.Now())
+	if until := time.Now().Add(retryAfter); until.After(b.blockedUntil) {
+		b.blockedUntil = until
+	}
+}
+
+// reserve 尝试获取一个令牌，获取成功返回 0，否则返回需要等待的时间
+func (l *RouteLimiter) reserve(route string, now time.Time) time.Duration {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	b := l.bucket(route, now)
+	if now.Before(b.blockedUntil) {
+		return b.blockedUntil.Sub(now)
+	}
+	if l.rate <= 0 {
+		return 0
+	}
+	b.tokens += now.Sub(b.last).Seconds() * l.rate
+	if b.tokens > l.burst {
+		b.tokens = l.burst
+	}
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+}
+
+func (l *RouteLimiter) bucket(route string, now time.Time) *bucket {
+	b, ok := l.buckets[route]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[route] = b
+	}
+	return b
+}
@@ -0,0 +1,59 @@
+package openapi
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouteLimiter(t *testing.T) {
+	t.Run("rate limit per route", func(t *testing.T) {
+		limiter := NewRouteLimiter(20, 1)
+		ctx := context.Background()
+		start := time.Now()
+		assert.Nil(t, limiter.Wait(ctx, "POST /channels/{channel_id}/messages"))
+		assert.Nil(t, limiter.Wait(ctx, "GET /users/@me"))
+		assert.Less(t, int64(time.Since(start)), int64(20*time.Millisecond))
+		assert.Nil(t, limiter.Wait(ctx, "POST /channels/{channel_id}/messages"))
+		assert.GreaterOrEqual(t, int64(time.Since(start)), int64(40*time.Millisecond))
+	})
+	t.Run("backoff", func(t *testing.T) {
+		limiter := NewRouteLimiter(0, 0)
+		limiter.Backoff("GET /users/@me", time.Hour)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		assert.Equal(t, context.DeadlineExceeded, limiter.Wait(ctx, "GET /users/@me"))
+		assert.Nil(t, limiter.Wait(ctx, "GET /guilds/{guild_id}"))
+	})
+}
+
+func TestRetryPolicy(t *testing.T) {
+	policy := &RetryPolicy{MaxAttempts: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+	assert.Equal(t, 100*time.Millisecond, policy.Backoff(1))
+	assert.Equal(t, 200*time.Millisecond, policy.Backoff(2))
+	assert.Equal(t, 300*time.Millisecond, policy.Backoff(3))
+	policy.Jitter = 0.5
+	for i := 0; i < 10; i++ {
+		d := policy.Backoff(1)
+		assert.True(t, d >= 50*time.Millisecond && d <= 100*time.Millisecond, d)
+	}
+	assert.True(t, policy.ShouldRetryStatus(http.StatusTooManyRequests))
+	assert.False(t, policy.ShouldRetryStatus(http.StatusBadRequest))
+
+	assert.True(t, IsIdempotent(http.MethodGet))
+	assert.False(t, IsIdempotent(http.MethodPost))
+	assert.False(t, IsRetryAllowed(context.Background()))
+	assert.True(t, IsRetryAllowed(AllowRetry(context.Background())))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	header := http.Header{}
+	assert.Equal(t, time.Duration(0), ParseRetryAfter(header))
+	header.Set("Retry-After", "2")
+	assert.Equal(t, 2*time.Second, ParseRetryAfter(header))
+	header.Set("Retry-After", time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	assert.Greater(t, int64(ParseRetryAfter(header)), int64(50*time.Second))
+}
@@ -0,0 +1,95 @@
+package openapi
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+// Options 创建 openapi 实例时的可选配置，由各个版本的实现按需读取
+type Options struct {
+	// Limiter 按路由限流，为 nil 时不限流
+	Limiter Limiter
+	// RetryPolicy 请求失败时的重试策略，为 nil 时不重试
+	RetryPolicy *RetryPolicy
+	// BaseURL 接口地址，如 `http://127.0.0.1:8080`，指定后不再区分正式与沙箱环境，为空时使用默认地址
+	BaseURL string
+	// Transport 自定义 http transport，指定后 Proxy、TLSConfig、LocalAddr 不再生效
+	Transport http.RoundTripper
+	// Proxy 代理，为 nil 时使用环境变量中的代理配置
+	Proxy func(*http.Request) (*url.URL, error)
+	// TLSConfig 自定义 tls 配置
+	TLSConfig *tls.Config
+	// LocalAddr 发起请求时绑定的本地地址
+	LocalAddr net.Addr
+}
+
+// Option 创建 openapi 实例时的可选配置
+type Option func(o *Options)
+
+// DefaultOptions 返回默认配置，默认只根据服务端返回的 Retry-After 进行限流，不重试
+// 需要重试时通过 WithRetryPolicy 开启，如 WithRetryPolicy(DefaultRetryPolicy())
+func DefaultOptions() *Options {
+	return &Options{
+		Limiter: NewRouteLimiter(0, 0),
+	}
+}
+
+// ApplyOptions 在默认配置上应用可选配置
+func ApplyOptions(opts ...Option) *Options {
+	o := DefaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithLimiter 指定限流器，传入 nil 表示不限流
+func WithLimiter(limiter Limiter) Option {
+	return func(o *Options) {
+		o.Limiter = limiter
+	}
+}
+
+// WithRetryPolicy 指定重试策略，传入 nil 表示不重试
+func WithRetryPolicy(policy *RetryPolicy) Option {
+	return func(o *Options) {
+		o.RetryPolicy = policy
+	}
+}
+
+// WithBaseURL 指定接口地址，可以用于请求本地的测试服务
+func WithBaseURL(baseURL string) Option {
+	return func(o *Options) {
+		o.BaseURL = baseURL
+	}
+}
+
+// WithTransport 指定自定义的 http transport
+func WithTransport(transport http.RoundTripper) Option {
+	return func(o *Options) {
+		o.Transport = transport
+	}
+}
+
+// WithProxy 指定代理，如 http.ProxyURL(proxyURL)
+func WithProxy(proxy func(*http.Request) (*url.URL, error)) Option {
+	return func(o *Options) {
+		o.Proxy = proxy
+	}
+}
+
+// WithTLSConfig 指定 tls 配置
+func WithTLSConfig(config *tls.Config) Option {
+	return func(o *Options) {
+		o.TLSConfig = config
+	}
+}
+
+// WithLocalAddr 指定发起请求时绑定的本地地址
+func WithLocalAddr(addr net.Addr) Option {
+	return func(o *Options) {
+		o.LocalAddr = addr
+	}
+}
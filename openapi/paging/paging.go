@@ -0,0 +1,140 @@
+// Package paging 自动翻页遍历 openapi 的分页接口。
+//
+// 每个分页接口对应一个遍历函数，遍历函数按页拉取数据，对每一条数据调用传入的回调，直到遍历完整个列表，
+// 回调返回错误，或者达到最大遍历数量。回调中返回 ErrStop 可以提前结束遍历。
+//
+// 各个分页接口的游标语义不同（after，start_index，cookie，before），paging 统一处理翻页，
+// 对相邻两页重叠的数据去重，并支持设置分页大小与最大遍历数量：
+//
+//	err := paging.GuildMembers(ctx, api, guildID, func(member *dto.Member) error {
+//		fmt.Println(member.User.ID)
+//		return nil
+//	}, paging.WithPageSize(400), paging.WithMaxItems(1000))
+package paging
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrStop 在回调中返回 ErrStop 提前结束遍历，遍历函数返回 nil
+	ErrStop = errors.New("paging: stop")
+	// ErrNoCursor 接口返回了完整的一页，但是无法从中得到下一页的游标
+	ErrNoCursor = errors.New("paging: no cursor in a full page")
+)
+
+// Option 遍历的配置
+type Option func(o *options)
+
+type options struct {
+	pageSize int
+	maxItems int
+	start    string
+}
+
+// WithPageSize 设置每页拉取的数量，超过接口上限时使用接口上限，不设置时使用各接口的默认值
+func WithPageSize(size int) Option {
+	return func(o *options) {
+		o.pageSize = size
+	}
+}
+
+// WithMaxItems 设置最多遍历的数量，0 表示不限制
+func WithMaxItems(n int) Option {
+	return func(o *options) {
+		o.maxItems = n
+	}
+}
+
+// WithStart 设置遍历的起始游标，不设置时从头开始，游标的含义见各个遍历函数的说明
+func WithStart(cursor string) Option {
+	return func(o *options) {
+		o.start = cursor
+	}
+}
+
+// page 一页数据
+type page struct {
+	items []interface{}
+	keys  []string // 与 items 一一对应，用于与上一页的数据去重，为空字符串时不去重
+	next  string   // 下一页的游标
+	more  bool     // 是否还有下一页
+}
+
+// endpoint 一个分页接口
+type endpoint struct {
+	defaultSize int // 默认的分页大小
+	maxSize     int // 接口允许的最大分页大小
+	// fetch 使用游标 cursor 拉取一页数据，cursor 为空时拉取第一页，limit 为分页大小
+	fetch func(ctx context.Context, cursor string, limit int) (*page, error)
+}
+
+// walker 一次遍历的状态
+type walker struct {
+	options
+	fn    func(item interface{}) error
+	count int
+	last  map[string]bool // 上一页数据的 key
+}
+
+// walk 从 WithStart 设置的游标开始翻页，对每一条数据回调 fn，直到没有下一页，或者 fn 返回错误，或者达到 maxItems
+func walk(ctx context.Context, e endpoint, opts []Option, fn func(interface{}) error) error {
+	w := &walker{fn: fn}
+	for _, opt := range opts {
+		opt(&w.options)
+	}
+	if w.pageSize <= 0 {
+		w.pageSize = e.defaultSize
+	}
+	if w.pageSize > e.maxSize {
+		w.pageSize = e.maxSize
+	}
+	cursor := w.start
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		p, err := e.fetch(ctx, cursor, w.pageSize)
+		if err != nil {
+			return err
+		}
+		fresh, done, err := w.emit(p)
+		if errors.Is(err, ErrStop) {
+			return nil
+		}
+		if err != nil || done {
+			return err
+		}
+		// 整页都是重复数据或者游标没有前进时结束，避免接口异常时死循环
+		if !p.more || fresh == 0 || p.next == cursor {
+			return nil
+		}
+		cursor = p.next
+	}
+}
+
+// emit 回调一页中与上一页不重复的数据，返回回调的数量，以及是否已经达到 maxItems
+func (w *walker) emit(p *page) (int, bool, error) {
+	seen := make(map[string]bool, len(p.keys))
+	fresh := 0
+	for i, item := range p.items {
+		key := p.keys[i]
+		if key != "" {
+			if w.last[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		fresh++
+		if err := w.fn(item); err != nil {
+			return fresh, true, err
+		}
+		w.count++
+		if w.maxItems > 0 && w.count >= w.maxItems {
+			return fresh, true, nil
+		}
+	}
+	w.last = seen
+	return fresh, false, nil
+}
@@ -0,0 +1,195 @@
+package paging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tencent-connect/botgo/dto"
+	"github.com/tencent-connect/botgo/openapi/fake"
+)
+
+// overlapAPI 翻页时返回 after 指定的成员，模拟接口返回重叠的数据
+type overlapAPI struct {
+	*fake.OpenAPI
+}
+
+func (o overlapAPI) GuildMembers(ctx context.Context, guildID string,
+	pager *dto.GuildMembersPager) ([]*dto.Member, error) {
+	members, err := o.OpenAPI.GuildMembers(ctx, guildID, pager)
+	if err != nil || pager.After == "0" || len(members) == 0 {
+		return members, err
+	}
+	boundary, err := o.OpenAPI.GuildMember(ctx, guildID, pager.After)
+	if err != nil {
+		return nil, err
+	}
+	return append([]*dto.Member{boundary}, members...), nil
+}
+
+// nilUserAPI 将每页中满足 drop 的成员的用户信息置空
+type nilUserAPI struct {
+	*fake.OpenAPI
+	drop func(i, n int) bool
+}
+
+func (a nilUserAPI) GuildMembers(ctx context.Context, guildID string,
+	pager *dto.GuildMembersPager) ([]*dto.Member, error) {
+	members, err := a.OpenAPI.GuildMembers(ctx, guildID, pager)
+	for i, member := range members {
+		if a.drop(i, len(members)) {
+			member.User = nil
+		}
+	}
+	return members, err
+}
+
+func newFake() *fake.OpenAPI {
+	f := fake.New()
+	f.AddGuild(&dto.Guild{ID: "guild"})
+	for i := 0; i < 25; i++ {
+		member := &dto.Member{User: &dto.User{ID: fmt.Sprintf("u%02d", i)}}
+		if i%2 == 0 {
+			member.Roles = []string{"role"}
+		}
+		f.AddMember("guild", member)
+	}
+	return f
+}
+
+func TestGuildMembers(t *testing.T) {
+	ctx := context.Background()
+	f := newFake()
+	var ids []string
+	collect := func(member *dto.Member) error {
+		ids = append(ids, member.User.ID)
+		return nil
+	}
+
+	t.Run("all", func(t *testing.T) {
+		ids = nil
+		assert.Nil(t, GuildMembers(ctx, f, "guild", collect, WithPageSize(10)))
+		assert.Len(t, ids, 25)
+		assert.Equal(t, "u24", ids[24])
+		// 10 + 10 + 5 + 空页
+		assert.Len(t, f.Calls("GuildMembers"), 4)
+	})
+	t.Run("overlap", func(t *testing.T) {
+		ids = nil
+		assert.Nil(t, GuildMembers(ctx, overlapAPI{f}, "guild", collect, WithPageSize(10)))
+		assert.Len(t, ids, 25)
+		for i, id := range ids {
+			assert.Equal(t, fmt.Sprintf("u%02d", i), id)
+		}
+	})
+	t.Run("max items and start", func(t *testing.T) {
+		ids = nil
+		assert.Nil(t, GuildMembers(ctx, f, "guild", collect, WithStart("u19"), WithMaxItems(3)))
+		assert.Equal(t, []string{"u20", "u21", "u22"}, ids)
+	})
+	t.Run("stop", func(t *testing.T) {
+		ids = nil
+		err := GuildMembers(ctx, f, "guild", func(member *dto.Member) error {
+			ids = append(ids, member.User.ID)
+			if len(ids) == 2 {
+				return ErrStop
+			}
+			return nil
+		})
+		assert.Nil(t, err)
+		assert.Len(t, ids, 2)
+	})
+	t.Run("last member without user", func(t *testing.T) {
+		seen := map[string]bool{}
+		api := nilUserAPI{f, func(i, n int) bool { return i == n-1 }}
+		assert.Nil(t, GuildMembers(ctx, api, "guild", func(member *dto.Member) error {
+			if member.User != nil {
+				seen[member.User.ID] = true
+			}
+			return nil
+		}, WithPageSize(10)))
+		// 每页最后一个成员没有用户信息，以倒数第二个成员翻页，最后一个成员会在下一页再次返回
+		assert.Len(t, seen, 24)
+		assert.True(t, seen["u23"])
+	})
+	t.Run("full page without user", func(t *testing.T) {
+		api := nilUserAPI{f, func(int, int) bool { return true }}
+		err := GuildMembers(ctx, api, "guild", func(*dto.Member) error { return nil }, WithPageSize(10))
+		assert.Equal(t, ErrNoCursor, err)
+	})
+	t.Run("error", func(t *testing.T) {
+		injected := errors.New("injected")
+		f.InjectError("GuildMembers", nil, injected)
+		defer f.ResetErrors()
+		ids = nil
+		assert.Equal(t, injected, GuildMembers(ctx, f, "guild", collect, WithPageSize(10)))
+		assert.Len(t, ids, 10)
+	})
+}
+
+func TestGuildRoleMembers(t *testing.T) {
+	f := newFake()
+	var ids []string
+	err := GuildRoleMembers(context.Background(), f, "guild", "role", func(member *dto.Member) error {
+		ids = append(ids, member.User.ID)
+		return nil
+	}, WithPageSize(5))
+	assert.Nil(t, err)
+	assert.Len(t, ids, 13)
+	assert.Equal(t, "u24", ids[12])
+	assert.Len(t, f.Calls("GuildRoleMembers"), 3)
+}
+
+func TestMeGuilds(t *testing.T) {
+	f := fake.New()
+	for i := 0; i < 7; i++ {
+		f.AddGuild(&dto.Guild{ID: fmt.Sprint("g", i)})
+	}
+	var ids []string
+	err := MeGuilds(context.Background(), f, func(guild *dto.Guild) error {
+		ids = append(ids, guild.ID)
+		return nil
+	}, WithPageSize(3))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"g0", "g1", "g2", "g3", "g4", "g5", "g6"}, ids)
+	assert.Len(t, f.Calls("MeGuilds"), 3)
+}
+
+func TestMessages(t *testing.T) {
+	f := fake.New()
+	for i := 0; i < 45; i++ {
+		f.AddMessage(&dto.Message{ID: fmt.Sprintf("m%02d", i), ChannelID: "channel"})
+	}
+	var ids []string
+	collect := func(message *dto.Message) error {
+		ids = append(ids, message.ID)
+		return nil
+	}
+	assert.Nil(t, Messages(context.Background(), f, "channel", collect, WithPageSize(100)))
+	assert.Len(t, ids, 45)
+	assert.Equal(t, "m44", ids[0])
+	assert.Equal(t, "m00", ids[44])
+	assert.Len(t, f.Calls("Messages"), 3)
+
+	ids = nil
+	assert.Nil(t, Messages(context.Background(), f, "channel", collect, WithStart("m03")))
+	assert.Equal(t, []string{"m02", "m01", "m00"}, ids)
+}
+
+func TestMessageReactionUsers(t *testing.T) {
+	f := fake.New()
+	emoji := dto.Emoji{ID: "4", Type: 1}
+	for i := 0; i < 12; i++ {
+		f.AddReaction("channel", "message", emoji, &dto.User{ID: fmt.Sprint("u", i)})
+	}
+	var ids []string
+	err := MessageReactionUsers(context.Background(), f, "channel", "message", emoji, func(user *dto.User) error {
+		ids = append(ids, user.ID)
+		return nil
+	}, WithPageSize(5))
+	assert.Nil(t, err)
+	assert.Len(t, ids, 12)
+	assert.Len(t, f.Calls("GetMessageReactionUsers"), 3)
+}
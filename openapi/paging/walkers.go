@@ -0,0 +1,160 @@
+package paging
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/tencent-connect/botgo/dto"
+	"github.com/tencent-connect/botgo/openapi"
+)
+
+// GuildMembers 遍历频道的成员，对每个成员回调 fn，WithStart 为起始的 after（用户 ID），默认分页大小 400，最大 1000
+//
+// 以本页最后一个带有用户信息的成员的 ID 作为下一页的 after，整页都没有用户信息时无法翻页，返回 ErrNoCursor。
+// 翻页时可能返回上一页已经返回过的成员，会按照用户 ID 去重，返回空页时结束
+func GuildMembers(ctx context.Context, api openapi.GuildAPI, guildID string,
+	fn func(member *dto.Member) error, opts ...Option) error {
+	e := endpoint{defaultSize: 400, maxSize: 1000}
+	e.fetch = func(ctx context.Context, cursor string, limit int) (*page, error) {
+		if cursor == "" {
+			cursor = "0"
+		}
+		members, err := api.GuildMembers(ctx, guildID, &dto.GuildMembersPager{
+			After: cursor,
+			Limit: strconv.Itoa(limit),
+		})
+		if err != nil {
+			return nil, err
+		}
+		p := memberPage(members)
+		for i := len(p.keys) - 1; i >= 0 && p.next == ""; i-- {
+			p.next = p.keys[i]
+		}
+		if p.next == "" && len(members) >= limit {
+			return nil, ErrNoCursor
+		}
+		p.more = p.next != ""
+		return p, nil
+	}
+	return walk(ctx, e, opts, func(item interface{}) error {
+		return fn(item.(*dto.Member))
+	})
+}
+
+// GuildRoleMembers 遍历频道身份组的成员，对每个成员回调 fn，WithStart 为起始的 start_index，默认分页大小 100，最大 400
+//
+// 接口返回下一页的 start_index，为空时结束
+func GuildRoleMembers(ctx context.Context, api openapi.GuildAPI, guildID, roleID string,
+	fn func(member *dto.Member) error, opts ...Option) error {
+	e := endpoint{defaultSize: 100, maxSize: 400}
+	e.fetch = func(ctx context.Context, cursor string, limit int) (*page, error) {
+		members, next, err := api.GuildRoleMembers(ctx, guildID, roleID, &dto.GuildRoleMembersPager{
+			StartIndex: cursor,
+			Limit:      strconv.Itoa(limit),
+		})
+		if err != nil {
+			return nil, err
+		}
+		p := memberPage(members)
+		p.next, p.more = next, next != "" && len(members) > 0
+		return p, nil
+	}
+	return walk(ctx, e, opts, func(item interface{}) error {
+		return fn(item.(*dto.Member))
+	})
+}
+
+// memberPage 构造成员列表的一页，以用户 ID 去重
+func memberPage(members []*dto.Member) *page {
+	p := &page{}
+	for _, member := range members {
+		key := ""
+		if member.User != nil {
+			key = member.User.ID
+		}
+		p.items = append(p.items, member)
+		p.keys = append(p.keys, key)
+	}
+	return p
+}
+
+// MeGuilds 遍历机器人加入的频道，对每个频道回调 fn，WithStart 为起始的 after（频道 ID），默认分页大小与最大值均为 100
+//
+// 以本页最后一个频道的 ID 作为下一页的 after，返回的数量小于分页大小时结束
+func MeGuilds(ctx context.Context, api openapi.UserAPI, fn func(guild *dto.Guild) error, opts ...Option) error {
+	e := endpoint{defaultSize: 100, maxSize: 100}
+	e.fetch = func(ctx context.Context, cursor string, limit int) (*page, error) {
+		guilds, err := api.MeGuilds(ctx, &dto.GuildPager{After: cursor, Limit: strconv.Itoa(limit)})
+		if err != nil {
+			return nil, err
+		}
+		p := &page{more: len(guilds) >= limit}
+		for _, guild := range guilds {
+			p.items = append(p.items, guild)
+			p.keys = append(p.keys, guild.ID)
+		}
+		if len(guilds) > 0 {
+			p.next = guilds[len(guilds)-1].ID
+		}
+		return p, nil
+	}
+	return walk(ctx, e, opts, func(item interface{}) error {
+		return fn(item.(*dto.Guild))
+	})
+}
+
+// Messages 从新到旧遍历子频道的消息，对每条消息回调 fn，WithStart 为起始的消息 ID（不包含该消息），默认分页大小与最大值均为 20
+//
+// 接口返回的一页消息按照时间升序排列，以本页最早的消息作为下一页的 before，返回的数量小于分页大小时结束
+func Messages(ctx context.Context, api openapi.MessageAPI, channelID string,
+	fn func(message *dto.Message) error, opts ...Option) error {
+	e := endpoint{defaultSize: 20, maxSize: 20}
+	e.fetch = func(ctx context.Context, cursor string, limit int) (*page, error) {
+		pager := &dto.MessagesPager{Limit: strconv.Itoa(limit)}
+		if cursor != "" {
+			pager.Type, pager.ID = dto.MPTBefore, cursor
+		}
+		messages, err := api.Messages(ctx, channelID, pager)
+		if err != nil {
+			return nil, err
+		}
+		p := &page{more: len(messages) >= limit}
+		for i := len(messages) - 1; i >= 0; i-- {
+			p.items = append(p.items, messages[i])
+			p.keys = append(p.keys, messages[i].ID)
+		}
+		if len(messages) > 0 {
+			p.next = messages[0].ID
+		}
+		return p, nil
+	}
+	return walk(ctx, e, opts, func(item interface{}) error {
+		return fn(item.(*dto.Message))
+	})
+}
+
+// MessageReactionUsers 遍历对消息发表了指定表情表态的用户，对每个用户回调 fn，WithStart 为起始的 cookie，默认分页大小 50，最大 1000
+//
+// 接口返回下一页的 cookie，is_end 为 true 时结束
+func MessageReactionUsers(ctx context.Context, api openapi.MessageReactionAPI, channelID, messageID string,
+	emoji dto.Emoji, fn func(user *dto.User) error, opts ...Option) error {
+	e := endpoint{defaultSize: 50, maxSize: 1000}
+	e.fetch = func(ctx context.Context, cursor string, limit int) (*page, error) {
+		users, err := api.GetMessageReactionUsers(ctx, channelID, messageID, emoji, &dto.MessageReactionPager{
+			Cookie: cursor,
+			Limit:  strconv.Itoa(limit),
+		})
+		if err != nil {
+			return nil, err
+		}
+		p := &page{next: users.Cookie, more: !users.IsEnd && users.Cookie != ""}
+		for _, user := range users.Users {
+			p.items = append(p.items, user)
+			p.keys = append(p.keys, user.ID)
+		}
+		return p, nil
+	}
+	return walk(ctx, e, opts, func(item interface{}) error {
+		return fn(item.(*dto.User))
+	})
+}
@@ -0,0 +1,114 @@
+package openapi
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy 请求重试策略，使用带抖动的指数退避
+// 只有幂等的请求（GET/PUT/DELETE 等），或者通过 AllowRetry 主动开启重试的请求才会重试
+type RetryPolicy struct {
+	// MaxAttempts 最多请求次数，包含首次请求，小于等于 1 时不重试
+	MaxAttempts int
+	// BaseDelay 第一次重试前的等待时间，之后每次翻倍
+	BaseDelay time.Duration
+	// MaxDelay 指数退避的最大等待时间，服务端通过 Retry-After 指定的等待时间不受此限制
+	MaxDelay time.Duration
+	// Jitter 抖动比例，取值 [0, 1]，实际等待时间在 [delay*(1-Jitter), delay] 之间随机
+	Jitter float64
+	// RetryStatus 需要重试的 http 状态码，为空时使用 429、502、503、504
+	RetryStatus []int
+}
+
+// DefaultRetryPolicy 默认重试策略，最多请求 3 次
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   200 * time.Millisecond,
+		MaxDelay:    5 * time.Second,
+		Jitter:      0.2,
+	}
+}
+
+var defaultRetryStatus = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// Backoff 计算第 attempt 次请求失败后，重试之前需要等待的时间，attempt 从 1 开始
+func (p *RetryPolicy) Backoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	delay := float64(p.BaseDelay) * math.Exp2(float64(attempt-1))
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+	if p.Jitter > 0 {
+		jitter := math.Min(p.Jitter, 1)
+		delay -= delay * jitter * rand.Float64()
+	}
+	return time.Duration(delay)
+}
+
+// ShouldRetryStatus 状态码是否需要重试
+func (p *RetryPolicy) ShouldRetryStatus(code int) bool {
+	statuses := p.RetryStatus
+	if len(statuses) == 0 {
+		statuses = defaultRetryStatus
+	}
+	for _, status := range statuses {
+		if status == code {
+			return true
+		}
+	}
+	return false
+}
+
+// IsIdempotent 请求方法是否幂等，幂等的请求失败后可以直接重试
+func IsIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+type retryCtxKey struct{}
+
+// AllowRetry 为非幂等的请求（如发消息）开启重试，重试可能导致重复发送，请确认业务可以接受
+func AllowRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryCtxKey{}, true)
+}
+
+// IsRetryAllowed ctx 是否通过 AllowRetry 开启了重试
+func IsRetryAllowed(ctx context.Context) bool {
+	allowed, _ := ctx.Value(retryCtxKey{}).(bool)
+	return allowed
+}
+
+// ParseRetryAfter 解析服务端返回的 Retry-After 头，支持秒数与 http 日期两种格式，没有或无法解析时返回 0
+func ParseRetryAfter(header http.Header) time.Duration {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		return time.Duration(seconds * float64(time.Second))
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
@@ -0,0 +1,55 @@
+package v1
+
+import (
+	"context"
+
+	"github.com/tencent-connect/botgo/dto"
+)
+
+// ListThreads 获取子频道下的主题列表
+func (o *openAPI) ListThreads(ctx context.Context, channelID string) (*dto.ThreadsList, error) {
+	rsp, err := o.request(ctx).
+		SetResult(dto.ThreadsList{}).
+		SetPathParam("channel_id", channelID).
+		Get(o.getURL(threadsURI))
+	if err != nil {
+		return nil, err
+	}
+	return rsp.Result().(*dto.ThreadsList), nil
+}
+
+// GetThread 获取主题详情
+func (o *openAPI) GetThread(ctx context.Context, channelID, threadID string) (*dto.Thread, error) {
+	rsp, err := o.request(ctx).
+		SetResult(dto.ThreadWrapper{}).
+		SetPathParam("channel_id", channelID).
+		SetPathParam("thread_id", threadID).
+		Get(o.getURL(threadURI))
+	if err != nil {
+		return nil, err
+	}
+	return rsp.Result().(*dto.ThreadWrapper).Thread, nil
+}
+
+// PublishThread 发表主题
+func (o *openAPI) PublishThread(ctx context.Context,
+	channelID string, thread *dto.ThreadToCreate) (*dto.ThreadPublished, error) {
+	rsp, err := o.request(ctx).
+		SetResult(dto.ThreadPublished{}).
+		SetPathParam("channel_id", channelID).
+		SetBody(thread).
+		Put(o.getURL(threadsURI))
+	if err != nil {
+		return nil, err
+	}
+	return rsp.Result().(*dto.ThreadPublished), nil
+}
+
+// DeleteThread 删除主题
+func (o *openAPI) DeleteThread(ctx context.Context, channelID, threadID string) error {
+	_, err := o.request(ctx).
+		SetPathParam("channel_id", channelID).
+		SetPathParam("thread_id", threadID).
+		Delete(o.getURL(threadURI))
+	return err
+}
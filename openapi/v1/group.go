@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/tencent-connect/botgo/dto"
+	"github.com/tencent-connect/botgo/openapi"
 )
 
 // PostGroupMessage 发动消息到群
@@ -23,7 +24,7 @@ func (o *openAPI) PostGroupMessage(ctx context.Context, groupOpenID string, msg
 // PostRichMediaToGroup 发送富文本消息到群
 func (o *openAPI) PostRichMediaToGroup(ctx context.Context, groupOpenID string, media *dto.Media) (*dto.MediaReturnParam, error) {
 	resp, err := o.request(ctx).
-		SetResult(dto.Message{}).
+		SetResult(dto.MediaReturnParam{}).
 		SetPathParam("group_openid", groupOpenID).
 		SetBody(media).
 		Post(o.getURL(groupsFileURI))
@@ -33,3 +34,18 @@ func (o *openAPI) PostRichMediaToGroup(ctx context.Context, groupOpenID string,
 
 	return resp.Result().(*dto.MediaReturnParam), nil
 }
+
+// RetractGroupMessage 撤回机器人发送的群聊消息
+func (o *openAPI) RetractGroupMessage(ctx context.Context,
+	groupOpenID, msgID string, options ...openapi.RetractMessageOption) error {
+	request := o.request(ctx).
+		SetPathParam("group_openid", groupOpenID).
+		SetPathParam("message_id", msgID)
+	for _, option := range options {
+		if option == openapi.RetractMessageOptionHidetip {
+			request = request.SetQueryParam("hidetip", "true")
+		}
+	}
+	_, err := request.Delete(o.getURL(groupsMessageIDURI))
+	return err
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/go-resty/resty/v2" // resty 是一个优秀的 rest api 客户端，可以极大的减少开发基于 rest 标准接口求请求的封装工作量
@@ -25,10 +26,13 @@ type openAPI struct {
 	timeout time.Duration
 
 	sandbox     bool   // 请求沙箱环境
+	baseURL     string // 自定义接口地址，为空时使用默认地址
 	debug       bool   // debug 模式，调试sdk时候使用
 	lastTraceID string // lastTraceID id
 
-	restyClient *resty.Client // resty client 复用
+	limiter     openapi.Limiter      // 按路由限流
+	retryPolicy *openapi.RetryPolicy // 重试策略
+	restyClient *resty.Client        // resty client 复用
 }
 
 // Setup 注册
@@ -47,13 +51,17 @@ func (o *openAPI) TraceID() string {
 }
 
 // Setup 生成一个实例
-func (o *openAPI) Setup(token *token.Token, inSandbox bool) openapi.OpenAPI {
+func (o *openAPI) Setup(token *token.Token, inSandbox bool, opts ...openapi.Option) openapi.OpenAPI {
+	options := openapi.ApplyOptions(opts...)
 	api := &openAPI{
-		token:   token,
-		timeout: 3 * time.Second,
-		sandbox: inSandbox,
+		token:       token,
+		timeout:     3 * time.Second,
+		sandbox:     inSandbox,
+		baseURL:     strings.TrimRight(options.BaseURL, "/"),
+		limiter:     options.Limiter,
+		retryPolicy: options.RetryPolicy,
 	}
-	api.setupClient() // 初始化可复用的 client
+	api.setupClient(newTransport(options)) // 初始化可复用的 client
 	return api
 }
 
@@ -66,19 +74,35 @@ func (o *openAPI) WithTimeout(duration time.Duration) openapi.OpenAPI {
 // Transport 透传请求
 func (o *openAPI) Transport(ctx context.Context, method, url string, body interface{}) ([]byte, error) {
 	resp, err := o.request(ctx).SetBody(body).Execute(method, url)
+	if resp == nil {
+		// 限流等待失败等请求没有发出的情况下，resty 不返回 response
+		return nil, err
+	}
 	return resp.Body(), err
 }
 
 // 初始化 client
-func (o *openAPI) setupClient() {
+func (o *openAPI) setupClient(transport http.RoundTripper) {
 	o.restyClient = resty.New().
-		SetTransport(createTransport(nil, MaxIdleConns)). // 自定义 transport
+		SetTransport(transport). // 自定义 transport
 		SetLogger(log.DefaultLogger).
 		SetDebug(o.debug).
 		SetTimeout(o.timeout).
-		SetAuthToken(o.token.GetString()).
 		SetAuthScheme(string(o.token.Type)).
 		SetHeader("User-Agent", version.String()).
+		OnBeforeRequest(
+			func(client *resty.Client, request *resty.Request) error {
+				// 每次请求都重新获取 token，避免使用会自动刷新的 token 时发出已经过期的 token
+				// 获取失败时直接返回错误，不发出没有 token 的请求
+				authToken, err := o.token.AuthString()
+				if err != nil {
+					return err
+				}
+				request.SetAuthToken(authToken)
+				// 按路由限流，路由使用未替换参数的 uri 模板
+				return o.waitLimiter(request)
+			},
+		).
 		SetPreRequestHook(
 			func(client *resty.Client, request *http.Request) error {
 				// 执行请求前过滤器
@@ -96,13 +120,15 @@ func (o *openAPI) setupClient() {
 				}
 				traceID := resp.Header().Get(openapi.TraceIDKey)
 				o.lastTraceID = traceID
+				o.backoffLimiter(resp)
 				// 非成功含义的状态码，需要返回 error 供调用方识别
 				if !openapi.IsSuccessStatus(resp.StatusCode()) {
-					return errs.New(resp.StatusCode(), string(resp.Body()), traceID)
+					return errs.NewAPIError(resp.StatusCode(), resp.Body(), traceID)
 				}
 				return nil
 			},
 		)
+	o.setupRetry()
 }
 
 // request 每个请求，都需要创建一个 request
@@ -125,6 +151,21 @@ func respInfo(resp *resty.Response) string {
 	)
 }
 
+// newTransport 根据配置创建 transport，指定了自定义 transport 时直接使用
+func newTransport(options *openapi.Options) http.RoundTripper {
+	if options.Transport != nil {
+		return options.Transport
+	}
+	transport := createTransport(options.LocalAddr, MaxIdleConns)
+	if options.Proxy != nil {
+		transport.Proxy = options.Proxy
+	}
+	if options.TLSConfig != nil {
+		transport.TLSClientConfig = options.TLSConfig
+	}
+	return transport
+}
+
 func createTransport(localAddr net.Addr, idleConns int) *http.Transport {
 	dialer := &net.Dialer{
 		Timeout:   60 * time.Second,
@@ -0,0 +1,148 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tencent-connect/botgo/dto"
+	"github.com/tencent-connect/botgo/openapi"
+	"github.com/tencent-connect/botgo/token"
+)
+
+type countTransport struct {
+	calls int
+}
+
+func (c *countTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.calls++
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+type errSource struct{}
+
+func (errSource) AccessToken() (string, error) {
+	return "", errors.New("refresh failed")
+}
+
+func TestSetupOptions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/users/@me", r.URL.Path)
+		assert.Equal(t, "Bot 1.token", r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1024","username":"bot"}`))
+	}))
+	defer server.Close()
+
+	t.Run("base url", func(t *testing.T) {
+		api := (&openAPI{}).Setup(token.BotToken(1, "token"), false, openapi.WithBaseURL(server.URL+"/"))
+		me, err := api.Me(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, "1024", me.ID)
+	})
+	t.Run("custom transport", func(t *testing.T) {
+		transport := &countTransport{}
+		api := (&openAPI{}).Setup(token.BotToken(1, "token"), false,
+			openapi.WithBaseURL(server.URL), openapi.WithTransport(transport))
+		_, err := api.Me(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, 1, transport.calls)
+	})
+	t.Run("token source error", func(t *testing.T) {
+		transport := &countTransport{}
+		api := (&openAPI{}).Setup(token.QQBotToken(1, errSource{}), false,
+			openapi.WithBaseURL(server.URL), openapi.WithTransport(transport))
+		_, err := api.Me(context.Background())
+		assert.EqualError(t, err, "refresh failed")
+		assert.Equal(t, 0, transport.calls)
+	})
+	t.Run("default url", func(t *testing.T) {
+		api := &openAPI{sandbox: true}
+		assert.Equal(t, "https://sandbox.api.sgroup.qq.com/users/@me", api.getURL(userMeURI))
+	})
+}
+
+func TestPostRichMedia(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]interface{}{}
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, float64(dto.MediaFileTypeImage), body["file_type"])
+		assert.Equal(t, "aGVsbG8=", body["file_data"])
+		assert.NotContains(t, body, "url")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"file_uuid":"uuid","file_info":"info","ttl":3600}`))
+	}))
+	defer server.Close()
+
+	api := (&openAPI{}).Setup(token.BotToken(1, "token"), false, openapi.WithBaseURL(server.URL))
+	media := &dto.Media{FileType: dto.MediaFileTypeImage, FileData: "aGVsbG8="}
+	for _, upload := range []func() (*dto.MediaReturnParam, error){
+		func() (*dto.MediaReturnParam, error) {
+			return api.PostRichMediaToUser(context.Background(), "user", media)
+		},
+		func() (*dto.MediaReturnParam, error) {
+			return api.PostRichMediaToGroup(context.Background(), "group", media)
+		},
+	} {
+		result, err := upload()
+		assert.Nil(t, err)
+		assert.Equal(t, &dto.MediaReturnParam{UUID: "uuid", Info: "info", TTL: 3600}, result)
+	}
+}
+
+func TestRetractV2Message(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		paths = append(paths, r.URL.RequestURI())
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	api := (&openAPI{}).Setup(token.BotToken(1, "token"), false, openapi.WithBaseURL(server.URL))
+	ctx := context.Background()
+	assert.Nil(t, api.RetractUserMessage(ctx, "user", "msg"))
+	assert.Nil(t, api.RetractGroupMessage(ctx, "group", "msg", openapi.RetractMessageOptionHidetip))
+	assert.Equal(t, []string{"/v2/users/user/messages/msg", "/v2/groups/group/messages/msg?hidetip=true"}, paths)
+}
+
+func TestForum(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method + " " + r.URL.Path {
+		case "GET /channels/channel/threads":
+			_, _ = w.Write([]byte(`{"threads":[{"thread_info":{"thread_id":"t1"}}],"is_finish":1}`))
+		case "GET /channels/channel/threads/t1":
+			_, _ = w.Write([]byte(`{"thread":{"channel_id":"channel","thread_info":{"thread_id":"t1","title":"周报"}}}`))
+		case "PUT /channels/channel/threads":
+			thread := &dto.ThreadToCreate{}
+			assert.Nil(t, json.NewDecoder(r.Body).Decode(thread))
+			assert.Equal(t, dto.ThreadFormatMarkdown, thread.Format)
+			_, _ = w.Write([]byte(`{"task_id":"task","create_time":"1650000000"}`))
+		case "DELETE /channels/channel/threads/t1":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	api := (&openAPI{}).Setup(token.BotToken(1, "token"), false, openapi.WithBaseURL(server.URL))
+	ctx := context.Background()
+	threads, err := api.ListThreads(ctx, "channel")
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(1), threads.IsFinish)
+	assert.Equal(t, "t1", threads.Threads[0].ThreadInfo.ThreadID)
+	thread, err := api.GetThread(ctx, "channel", "t1")
+	assert.Nil(t, err)
+	assert.Equal(t, "周报", thread.ThreadInfo.Title)
+	published, err := api.PublishThread(ctx, "channel",
+		&dto.ThreadToCreate{Title: "周报", Content: "# 本周动态", Format: dto.ThreadFormatMarkdown})
+	assert.Nil(t, err)
+	assert.Equal(t, "task", published.TaskID)
+	assert.Nil(t, api.DeleteThread(ctx, "channel", "t1"))
+}
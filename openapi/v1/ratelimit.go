@@ -0,0 +1,146 @@
+package v1
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/tencent-connect/botgo/openapi"
+)
+
+type routeCtxKey struct{}
+
+// waitLimiter 请求前按路由限流，需要在 url 参数替换之前调用，重试时 resty 会还原 uri 模板，所以每次请求都会进入限流
+func (o *openAPI) waitLimiter(request *resty.Request) error {
+	if o.limiter == nil {
+		return nil
+	}
+	ctx := request.Context()
+	route, ok := ctx.Value(routeCtxKey{}).(string)
+	if !ok {
+		route = routeOf(request.Method, request.URL)
+		request.SetContext(context.WithValue(ctx, routeCtxKey{}, route))
+	}
+	return o.limiter.Wait(request.Context(), route)
+}
+
+// backoffLimiter 服务端返回 429 时，根据 Retry-After 暂停对应路由的请求
+func (o *openAPI) backoffLimiter(resp *resty.Response) {
+	if o.limiter == nil || resp.StatusCode() != http.StatusTooManyRequests {
+		return
+	}
+	route, ok := resp.Request.Context().Value(routeCtxKey{}).(string)
+	if !ok {
+		return
+	}
+	o.limiter.Backoff(route, openapi.ParseRetryAfter(resp.Header()))
+}
+
+// setupRetry 根据重试策略设置 resty 的重试
+func (o *openAPI) setupRetry() {
+	if o.retryPolicy == nil || o.retryPolicy.MaxAttempts <= 1 {
+		return
+	}
+	o.restyClient.
+		SetRetryCount(o.retryPolicy.MaxAttempts - 1).
+		SetRetryWaitTime(0).
+		// 等待时间由 retryAfter 计算，不使用 resty 的上限，避免截断服务端要求的等待时间
+		SetRetryMaxWaitTime(time.Duration(math.MaxInt64)).
+		SetRetryAfter(o.retryAfter).
+		AddRetryCondition(o.shouldRetry)
+}
+
+// shouldRetry 只有幂等的请求，或者通过 openapi.AllowRetry 开启了重试的请求，才会在网络错误或者指定状态码时重试
+func (o *openAPI) shouldRetry(resp *resty.Response, err error) bool {
+	if resp == nil || resp.Request == nil {
+		return false
+	}
+	if !openapi.IsIdempotent(resp.Request.Method) && !openapi.IsRetryAllowed(resp.Request.Context()) {
+		return false
+	}
+	if resp.RawResponse == nil {
+		return err != nil
+	}
+	return o.retryPolicy.ShouldRetryStatus(resp.StatusCode())
+}
+
+// retryAfter 优先使用服务端返回的 Retry-After，没有时使用重试策略的指数退避
+func (o *openAPI) retryAfter(_ *resty.Client, resp *resty.Response) (time.Duration, error) {
+	if resp.RawResponse != nil {
+		if d := openapi.ParseRetryAfter(resp.Header()); d > 0 {
+			return d, nil
+		}
+	}
+	return o.retryPolicy.Backoff(resp.Request.Attempt), nil
+}
+
+// routeTemplates 所有接口的 uri 模板，用于将 Transport 透传的 url 还原为模板，避免每个 url 都创建一个限流桶
+var routeTemplates = []uri{
+	guildURI, guildMembersURI, guildMemberURI, guildRoleMemberURI, guildMuteURI, guildMembersMuteURI,
+	channelsURI, channelURI, channelPermissionsURI, channelRolesPermissionsURI, messagesURI, messageURI,
+	userMeURI, userMeGuildsURI, userMeDMURI, gatewayURI, gatewayBotURI, audioControlURI, micURI,
+	rolesURI, roleURI, memberRoleURI, dmsURI, dmsMessageURI,
+	channelAnnouncesURI, channelAnnounceURI, guildAnnouncesURI, guildAnnounceURI,
+	schedulesURI, scheduleURI, threadsURI, threadURI, apiPermissionURI, apiPermissionDemandURI,
+	pinsURI, pinURI, messageReactionURI, interactionsURI, httpSessionsURI, httpSessionURI,
+	messageSettingURI, voiceChannelMembersURI, settingGuideURI, dmSettingGuideURI,
+	usersURI, usersMessageURI, usersMessageIDURI, usersFileURI,
+	groupsURI, groupsMessageURI, groupsMessageIDURI, groupsFileURI,
+}
+
+// routeOf 生成限流使用的路由，由请求方法与去掉域名的 uri 模板组成，如 `POST /channels/{channel_id}/messages`
+// 已经替换了参数的 url 会还原为对应的模板，无法识别的 url 按照第一级路径归为一个路由
+func routeOf(method, rawURL string) string {
+	if i := strings.Index(rawURL, "://"); i >= 0 {
+		rawURL = rawURL[i+len("://"):]
+		if j := strings.IndexByte(rawURL, '/'); j >= 0 {
+			rawURL = rawURL[j:]
+		} else {
+			rawURL = "/"
+		}
+	}
+	if i := strings.IndexByte(rawURL, '?'); i >= 0 {
+		rawURL = rawURL[:i]
+	}
+	if !strings.Contains(rawURL, "{") {
+		rawURL = templateOf(rawURL)
+	}
+	return method + " " + rawURL
+}
+
+// templateOf 返回与 path 匹配的 uri 模板，字面量相同的段越多越优先
+func templateOf(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	best, bestScore := "", -1
+	for _, template := range routeTemplates {
+		score := matchTemplate(strings.Split(strings.Trim(string(template), "/"), "/"), segments)
+		if score > bestScore {
+			best, bestScore = string(template), score
+		}
+	}
+	if best != "" {
+		return best
+	}
+	return "/" + segments[0] + "/*"
+}
+
+// matchTemplate 逐段匹配模板，参数段匹配任意值，返回匹配的字面量段数，不匹配时返回 -1
+func matchTemplate(template, segments []string) int {
+	if len(template) != len(segments) {
+		return -1
+	}
+	score := 0
+	for i, segment := range template {
+		switch {
+		case strings.HasPrefix(segment, "{") && segments[i] != "":
+		case segment == segments[i]:
+			score++
+		default:
+			return -1
+		}
+	}
+	return score
+}
@@ -0,0 +1,83 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tencent-connect/botgo/openapi"
+	"github.com/tencent-connect/botgo/token"
+)
+
+func TestRetry(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1)%2 == 1 {
+			w.Header().Set("Retry-After", "0.01")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	policy := &openapi.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}
+	api := (&openAPI{}).Setup(token.BotToken(1, "token"), false, openapi.WithRetryPolicy(policy))
+	ctx := context.Background()
+
+	t.Run("idempotent request retried", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		_, err := api.Transport(ctx, http.MethodGet, server.URL+"/users/@me", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	})
+	t.Run("non idempotent request not retried", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		_, err := api.Transport(ctx, http.MethodPost, server.URL+"/channels/1/messages", nil)
+		assert.NotNil(t, err)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+	t.Run("opted in request retried", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		_, err := api.Transport(openapi.AllowRetry(ctx), http.MethodPost, server.URL+"/channels/1/messages", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	})
+}
+
+func TestTransport_Defaults(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Retry-After", "10")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	api := (&openAPI{}).Setup(token.BotToken(1, "token"), false)
+	_, err := api.Transport(context.Background(), http.MethodGet, server.URL+"/users/@me", nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "retries are opt-in")
+
+	// 路由被 Retry-After 暂停时等待超时，请求不会发出
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	body, err := api.Transport(ctx, http.MethodGet, server.URL+"/users/@me", nil)
+	assert.NotNil(t, err)
+	assert.Nil(t, body)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestRouteOf(t *testing.T) {
+	assert.Equal(t, "POST /channels/{channel_id}/messages",
+		routeOf(http.MethodPost, "https://api.sgroup.qq.com/channels/{channel_id}/messages"))
+	assert.Equal(t, "GET /users/@me/guilds", routeOf(http.MethodGet, "/users/@me/guilds?limit=10"))
+	assert.Equal(t, "POST /channels/{channel_id}/messages", routeOf(http.MethodPost, "/channels/123/messages"))
+	assert.Equal(t, "DELETE /v2/groups/{group_openid}/messages/{message_id}",
+		routeOf(http.MethodDelete, "https://api.sgroup.qq.com/v2/groups/g1/messages/m1"))
+	assert.Equal(t, "GET /unknown/*", routeOf(http.MethodGet, "/unknown/1/2"))
+}
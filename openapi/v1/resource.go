@@ -56,6 +56,9 @@ const (
 	schedulesURI uri = "/channels/{channel_id}/schedules"
 	scheduleURI  uri = "/channels/{channel_id}/schedules/{schedule_id}"
 
+	threadsURI uri = "/channels/{channel_id}/threads"
+	threadURI  uri = "/channels/{channel_id}/threads/{thread_id}"
+
 	apiPermissionURI       uri = "/guilds/{guild_id}/api_permission"
 	apiPermissionDemandURI uri = "/guilds/{guild_id}/api_permission/demand"
 
@@ -78,17 +81,22 @@ const (
 
 	// v2 目前提供的接口的 uri
 
-	usersURI        uri = "/v2/users/{openid}"
-	usersMessageURI uri = "/v2/users/{openid}/messages" // 单聊发送消息
-	usersFileURI    uri = "/v2/users/{openid}/files"    // 单聊富文本消息
+	usersURI          uri = "/v2/users/{openid}"
+	usersMessageURI   uri = "/v2/users/{openid}/messages"              // 单聊发送消息
+	usersMessageIDURI uri = "/v2/users/{openid}/messages/{message_id}" // 单聊撤回消息
+	usersFileURI      uri = "/v2/users/{openid}/files"                 // 单聊富文本消息
 
-	groupsURI        uri = "/v2/groups/{group_openid}"
-	groupsMessageURI uri = "/v2/groups/{group_openid}/messages" // 群聊发送消息
-	groupsFileURI    uri = "/v2/groups/{group_openid}/files"    // 群聊富文本消息
+	groupsURI          uri = "/v2/groups/{group_openid}"
+	groupsMessageURI   uri = "/v2/groups/{group_openid}/messages"              // 群聊发送消息
+	groupsMessageIDURI uri = "/v2/groups/{group_openid}/messages/{message_id}" // 群聊撤回消息
+	groupsFileURI      uri = "/v2/groups/{group_openid}/files"                 // 群聊富文本消息
 )
 
-// getURL 获取接口地址，会处理沙箱环境判断
+// getURL 获取接口地址，会处理沙箱环境判断，指定了 baseURL 时使用 baseURL
 func (o *openAPI) getURL(endpoint uri) string {
+	if o.baseURL != "" {
+		return o.baseURL + string(endpoint)
+	}
 	d := domain
 	if o.sandbox {
 		d = sandBoxDomain
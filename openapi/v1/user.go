@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/tencent-connect/botgo/dto"
+	"github.com/tencent-connect/botgo/openapi"
 )
 
 // PostUserMessage 单独发动消息给用户 (c2c)
@@ -23,7 +24,7 @@ func (o *openAPI) PostUserMessage(ctx context.Context, openID string, msg *dto.M
 // PostRichMedia 发送富文本消息到用户
 func (o *openAPI) PostRichMediaToUser(ctx context.Context, openID string, media *dto.Media) (*dto.MediaReturnParam, error) {
 	resp, err := o.request(ctx).
-		SetResult(dto.Message{}).
+		SetResult(dto.MediaReturnParam{}).
 		SetPathParam("openid", openID).
 		SetBody(media).
 		Post(o.getURL(usersFileURI))
@@ -33,3 +34,18 @@ func (o *openAPI) PostRichMediaToUser(ctx context.Context, openID string, media
 
 	return resp.Result().(*dto.MediaReturnParam), nil
 }
+
+// RetractUserMessage 撤回机器人发送的单聊消息
+func (o *openAPI) RetractUserMessage(ctx context.Context,
+	openID, msgID string, options ...openapi.RetractMessageOption) error {
+	request := o.request(ctx).
+		SetPathParam("openid", openID).
+		SetPathParam("message_id", msgID)
+	for _, option := range options {
+		if option == openapi.RetractMessageOptionHidetip {
+			request = request.SetQueryParam("hidetip", "true")
+		}
+	}
+	_, err := request.Delete(o.getURL(usersMessageIDURI))
+	return err
+}
@@ -0,0 +1,127 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// fileMode 队列文件的权限
+const fileMode = 0600
+
+// FileStore 基于本地文件的 Store，待发送的消息与额度使用量保存在同一个 json 文件中
+// 每次修改都会先写入临时文件再重命名，避免进程异常退出导致文件损坏
+type FileStore struct {
+	path   string
+	lock   sync.Mutex
+	loaded bool
+	data   fileData
+}
+
+type fileData struct {
+	Items map[string]*Item `json:"items"`
+	Usage usage            `json:"usage"`
+}
+
+// NewFileStore 创建文件 Store，path 为保存队列的文件路径，所在目录需要存在
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Load 加载全部待发送的消息
+func (s *FileStore) Load(_ context.Context) ([]*Item, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	items := make([]*Item, 0, len(s.data.Items))
+	for _, item := range s.data.Items {
+		items = append(items, item.clone())
+	}
+	return items, nil
+}
+
+// Save 保存待发送的消息
+func (s *FileStore) Save(_ context.Context, item *Item) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err := s.load(); err != nil {
+		return err
+	}
+	s.data.Items[item.ID] = item.clone()
+	return s.flush()
+}
+
+// Delete 删除消息
+func (s *FileStore) Delete(_ context.Context, id string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err := s.load(); err != nil {
+		return err
+	}
+	if _, ok := s.data.Items[id]; !ok {
+		return nil
+	}
+	delete(s.data.Items, id)
+	return s.flush()
+}
+
+// AddUsage 增加额度使用量
+func (s *FileStore) AddUsage(_ context.Context, day, key string, delta int) (int, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err := s.load(); err != nil {
+		return 0, err
+	}
+	n := s.data.Usage.add(day, key, delta)
+	return n, s.flush()
+}
+
+// load 首次使用时从文件中加载，文件不存在时为空，调用方需要持有锁
+func (s *FileStore) load() error {
+	if s.loaded {
+		return nil
+	}
+	data, err := ioutil.ReadFile(s.path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &s.data); err != nil {
+			return err
+		}
+	}
+	if s.data.Items == nil {
+		s.data.Items = map[string]*Item{}
+	}
+	s.loaded = true
+	return nil
+}
+
+// flush 写入文件，调用方需要持有锁
+func (s *FileStore) flush() error {
+	data, err := json.Marshal(s.data)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), fileMode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
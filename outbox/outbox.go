@@ -0,0 +1,396 @@
+// Package outbox 主动消息的发送队列。
+//
+// 主动消息（非被动回复）在子频道，私信，单聊与群聊中每天有发送额度，Queue 在发送前按照目标统计当天的额度使用量，
+// 按照目标限速发送，失败时退避重试。待发送的消息与额度使用量保存在 Store 中，进程重启后继续发送，发送结果通过回调通知：
+//
+//	queue := outbox.New(api,
+//		outbox.WithStore(outbox.NewFileStore("outbox.json")),
+//		outbox.WithQuota(outbox.SceneQuota(map[reply.Scene]int{reply.SceneGuild: 20})),
+//		outbox.WithResultHandler(func(result *outbox.Result) { log.Println(result.Item.ID, result.Err) }),
+//	)
+//	go queue.Run(ctx)
+//	_, err := queue.Enqueue(ctx, reply.Target{Scene: reply.SceneGuild, ChannelID: channelID}, msg)
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/tencent-connect/botgo/dto"
+	"github.com/tencent-connect/botgo/errs"
+	"github.com/tencent-connect/botgo/log"
+	"github.com/tencent-connect/botgo/openapi"
+	"github.com/tencent-connect/botgo/reply"
+)
+
+// 默认配置
+const (
+	// DefaultRate 每个目标每秒发送的消息数
+	DefaultRate = 1
+	// DefaultBurst 每个目标允许突发发送的消息数
+	DefaultBurst = 5
+	// DefaultMaxAttempts 默认的最大发送次数
+	DefaultMaxAttempts = 3
+	// DefaultRetryDelay 第一次重试前的等待时间，之后每次重试翻倍
+	DefaultRetryDelay = time.Second
+)
+
+// ErrQuotaExceeded 目标当天的主动消息额度已经用完
+var ErrQuotaExceeded = errors.New("outbox: daily quota exceeded")
+
+// Item 一条待发送的消息
+type Item struct {
+	ID        string               `json:"id"`
+	Target    reply.Target         `json:"target"`
+	Message   *dto.MessageToCreate `json:"message"`
+	Attempts  int                  `json:"attempts"`   // 已经发送的次数
+	CreatedAt time.Time            `json:"created_at"` // 加入队列的时间
+	NotBefore time.Time            `json:"not_before"` // 下一次发送的时间，重试或者额度顺延时设置
+}
+
+func (i *Item) clone() *Item {
+	item := *i
+	if i.Message != nil {
+		msg := *i.Message
+		item.Message = &msg
+	}
+	return &item
+}
+
+// Result 一条消息的发送结果，发送成功，或者失败并且不再重试时通知
+type Result struct {
+	Item    *Item
+	Message *dto.Message // 发送成功时开放平台返回的消息
+	Err     error        // 发送失败的原因，额度用完时为 ErrQuotaExceeded
+}
+
+// Queue 主动消息的发送队列，由 Run 按照 NotBefore 与加入顺序发送
+// 同一个目标（见 QuotaKey）的消息依次发送，不同目标的消息并发发送，某个目标限流或者退避时不影响其他目标
+type Queue struct {
+	api         openapi.OpenAPI
+	store       Store
+	limiter     openapi.Limiter
+	quota       QuotaFunc
+	maxAttempts int
+	retryDelay  time.Duration
+	carryOver   bool
+	onResult    func(result *Result)
+	now         func() time.Time
+
+	lock    sync.Mutex
+	items   map[string]*Item
+	sending map[string]bool // 正在发送的目标，每个目标同时只发送一条消息
+	seq     uint64
+	wake    chan struct{}
+	senders sync.WaitGroup
+}
+
+// Option 发送队列的配置
+type Option func(q *Queue)
+
+// WithStore 设置持久化待发送消息与额度使用量的 Store，默认为 MemoryStore
+func WithStore(store Store) Option {
+	return func(q *Queue) {
+		q.store = store
+	}
+}
+
+// WithLimiter 设置发送的限流器，以 QuotaKey 作为路由，默认每个目标每秒 DefaultRate 条，突发 DefaultBurst 条
+func WithLimiter(limiter openapi.Limiter) Option {
+	return func(q *Queue) {
+		q.limiter = limiter
+	}
+}
+
+// WithQuota 设置每个目标每天的额度，默认不限制
+func WithQuota(quota QuotaFunc) Option {
+	return func(q *Queue) {
+		q.quota = quota
+	}
+}
+
+// WithMaxAttempts 设置可以重试的错误（见 errs.IsRetryable）的最大发送次数，默认为 DefaultMaxAttempts
+//
+// 超时等错误时消息可能已经发送成功，重试可能导致重复发送
+func WithMaxAttempts(n int) Option {
+	return func(q *Queue) {
+		q.maxAttempts = n
+	}
+}
+
+// WithRetryDelay 设置第一次重试前的等待时间，之后每次重试翻倍，默认为 DefaultRetryDelay
+func WithRetryDelay(delay time.Duration) Option {
+	return func(q *Queue) {
+		q.retryDelay = delay
+	}
+}
+
+// WithCarryOver 额度用完时顺延到第二天发送，默认通知 ErrQuotaExceeded 并丢弃
+func WithCarryOver() Option {
+	return func(q *Queue) {
+		q.carryOver = true
+	}
+}
+
+// WithResultHandler 设置发送结果的回调，在发送消息的 goroutine 中调用，不同目标的结果可能并发通知
+func WithResultHandler(handler func(result *Result)) Option {
+	return func(q *Queue) {
+		q.onResult = handler
+	}
+}
+
+// New 创建发送队列，需要调用 Run 开始发送
+func New(api openapi.OpenAPI, opts ...Option) *Queue {
+	q := &Queue{
+		api:         api,
+		maxAttempts: DefaultMaxAttempts,
+		retryDelay:  DefaultRetryDelay,
+		now:         time.Now,
+		items:       map[string]*Item{},
+		sending:     map[string]bool{},
+		wake:        make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(q)
+	}
+	if q.store == nil {
+		q.store = NewMemoryStore()
+	}
+	if q.limiter == nil {
+		q.limiter = openapi.NewRouteLimiter(DefaultRate, DefaultBurst)
+	}
+	return q
+}
+
+// Enqueue 将消息加入队列并持久化，消息作为主动消息发送
+func (q *Queue) Enqueue(ctx context.Context, target reply.Target, msg *dto.MessageToCreate) (*Item, error) {
+	now := q.now()
+	q.lock.Lock()
+	q.seq++
+	item := (&Item{
+		ID:        fmt.Sprintf("%d-%d", now.UnixNano(), q.seq),
+		Target:    target,
+		Message:   msg,
+		CreatedAt: now,
+		NotBefore: now,
+	}).clone()
+	q.lock.Unlock()
+	if err := q.store.Save(ctx, item); err != nil {
+		return nil, err
+	}
+	queued := item.clone()
+	q.lock.Lock()
+	q.items[item.ID] = queued
+	q.lock.Unlock()
+	q.notify()
+	return item, nil
+}
+
+// Pending 返回等待发送的消息数量
+func (q *Queue) Pending() int {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return len(q.items)
+}
+
+// Usage 返回目标当天已经使用的额度
+func (q *Queue) Usage(ctx context.Context, target reply.Target) (int, error) {
+	return q.store.AddUsage(ctx, Day(q.now()), QuotaKey(target), 0)
+}
+
+// Run 加载 Store 中未发送的消息，并持续发送队列中的消息，直到 ctx 结束，返回前等待正在发送的消息
+func (q *Queue) Run(ctx context.Context) error {
+	if err := q.restore(ctx); err != nil {
+		return err
+	}
+	defer q.senders.Wait()
+	for {
+		item, wait := q.next()
+		if item != nil {
+			q.senders.Add(1)
+			go q.sendTarget(ctx, item)
+			continue
+		}
+		if err := q.wait(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// wait 等待 wait 时间或者有新的消息加入队列，wait 为 0 时只等待新的消息
+func (q *Queue) wait(ctx context.Context, wait time.Duration) error {
+	var timeout <-chan time.Time
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-q.wake:
+	case <-timeout:
+	}
+	return nil
+}
+
+// restore 加载 Store 中未发送的消息
+func (q *Queue) restore(ctx context.Context) error {
+	items, err := q.store.Load(ctx)
+	if err != nil {
+		return err
+	}
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	for _, item := range items {
+		q.items[item.ID] = item
+	}
+	return nil
+}
+
+// next 返回没有正在发送的目标中最早可以发送的消息，并将目标标记为正在发送
+// 没有到发送时间时返回需要等待的时间，没有可以发送的消息时返回 0
+func (q *Queue) next() (*Item, time.Duration) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	var first *Item
+	for _, item := range q.items {
+		if q.sending[QuotaKey(item.Target)] {
+			continue
+		}
+		if first == nil || before(item, first) {
+			first = item
+		}
+	}
+	if first == nil {
+		return nil, 0
+	}
+	if wait := first.NotBefore.Sub(q.now()); wait > 0 {
+		return nil, wait
+	}
+	q.sending[QuotaKey(first.Target)] = true
+	return first, 0
+}
+
+// sendTarget 发送目标的一条消息，完成后唤醒 Run 调度这个目标的下一条消息
+func (q *Queue) sendTarget(ctx context.Context, item *Item) {
+	defer q.senders.Done()
+	q.send(ctx, item)
+	q.lock.Lock()
+	delete(q.sending, QuotaKey(item.Target))
+	q.lock.Unlock()
+	q.notify()
+}
+
+func before(a, b *Item) bool {
+	if !a.NotBefore.Equal(b.NotBefore) {
+		return a.NotBefore.Before(b.NotBefore)
+	}
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	return a.ID < b.ID
+}
+
+// send 发送一条消息，ctx 结束时消息保留在队列中
+func (q *Queue) send(ctx context.Context, item *Item) {
+	day, key := Day(q.now()), QuotaKey(item.Target)
+	reserved, err := q.reserve(ctx, day, item)
+	if err != nil {
+		if errors.Is(err, ErrQuotaExceeded) && q.carryOver {
+			item.NotBefore = nextDay(q.now())
+			q.save(ctx, item)
+			return
+		}
+		q.finish(ctx, item, nil, err)
+		return
+	}
+	if err = q.limiter.Wait(ctx, key); err == nil {
+		item.Attempts++
+		var msg *dto.Message
+		if msg, err = reply.Post(ctx, q.api, item.Target, item.Message); err == nil {
+			q.finish(ctx, item, msg, nil)
+			return
+		}
+	}
+	if reserved {
+		q.release(day, key)
+	}
+	if ctx.Err() != nil {
+		return
+	}
+	q.retry(ctx, item, key, err)
+}
+
+// reserve 占用目标当天的一条额度，目标不限制额度时返回 false
+func (q *Queue) reserve(ctx context.Context, day string, item *Item) (bool, error) {
+	if q.quota == nil {
+		return false, nil
+	}
+	limit, err := q.quota(ctx, item.Target)
+	if err != nil || limit <= 0 {
+		return false, err
+	}
+	key := QuotaKey(item.Target)
+	used, err := q.store.AddUsage(ctx, day, key, 1)
+	if err != nil {
+		return false, err
+	}
+	if used > limit {
+		q.release(day, key)
+		return false, ErrQuotaExceeded
+	}
+	return true, nil
+}
+
+// release 发送失败时归还额度，使用独立的 ctx，避免 ctx 结束后无法归还
+func (q *Queue) release(day, key string) {
+	if _, err := q.store.AddUsage(context.Background(), day, key, -1); err != nil {
+		log.Warnf("[outbox] release quota of %s failed, %v", key, err)
+	}
+}
+
+// retry 可以重试的错误在退避之后重新发送，否则通知发送失败
+func (q *Queue) retry(ctx context.Context, item *Item, key string, err error) {
+	if !errs.IsRetryable(err) || item.Attempts >= q.maxAttempts {
+		q.finish(ctx, item, nil, err)
+		return
+	}
+	delay := q.retryDelay << uint(item.Attempts-1)
+	if errs.IsRateLimit(err) {
+		q.limiter.Backoff(key, delay)
+	}
+	log.Warnf("[outbox] send %s failed, retry after %v, %v", item.ID, delay, err)
+	item.NotBefore = q.now().Add(delay)
+	q.save(ctx, item)
+}
+
+// save 保存重新调度的消息，保存失败时仍然在内存中重试
+func (q *Queue) save(ctx context.Context, item *Item) {
+	if err := q.store.Save(ctx, item); err != nil {
+		log.Warnf("[outbox] save %s failed, %v", item.ID, err)
+	}
+}
+
+// finish 从队列中移除消息并通知发送结果
+func (q *Queue) finish(ctx context.Context, item *Item, msg *dto.Message, err error) {
+	q.lock.Lock()
+	delete(q.items, item.ID)
+	q.lock.Unlock()
+	if deleteErr := q.store.Delete(ctx, item.ID); deleteErr != nil {
+		log.Warnf("[outbox] delete %s failed, %v", item.ID, deleteErr)
+	}
+	if q.onResult != nil {
+		q.onResult(&Result{Item: item.clone(), Message: msg, Err: err})
+	}
+}
+
+func (q *Queue) notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,219 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tencent-connect/botgo/dto"
+	"github.com/tencent-connect/botgo/errs"
+	"github.com/tencent-connect/botgo/openapi"
+	"github.com/tencent-connect/botgo/openapi/fake"
+	"github.com/tencent-connect/botgo/reply"
+)
+
+var (
+	guildTarget = reply.Target{Scene: reply.SceneGuild, ChannelID: "channel", GuildID: "guild"}
+	groupTarget = reply.Target{Scene: reply.SceneGroup, GroupOpenID: "group"}
+)
+
+func newFake() *fake.OpenAPI {
+	f := fake.New()
+	f.AddGuild(&dto.Guild{ID: "guild"})
+	f.AddChannel(&dto.Channel{ID: "channel", GuildID: "guild"})
+	return f
+}
+
+// run 在后台运行队列，返回接收发送结果的 channel 与停止函数
+func run(q *Queue) (chan *Result, func()) {
+	results := make(chan *Result, 10)
+	q.onResult = func(result *Result) { results <- result }
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		_ = q.Run(ctx)
+		close(done)
+	}()
+	return results, func() {
+		cancel()
+		<-done
+	}
+}
+
+func wait(t *testing.T, results chan *Result) *Result {
+	select {
+	case result := <-results:
+		return result
+	case <-time.After(5 * time.Second):
+		t.Fatal("no result")
+		return nil
+	}
+}
+
+// sendNext 在当前 goroutine 中发送下一条可以发送的消息
+func sendNext(ctx context.Context, t *testing.T, q *Queue) {
+	item, _ := q.next()
+	if !assert.NotNil(t, item) {
+		return
+	}
+	q.senders.Add(1)
+	q.sendTarget(ctx, item)
+}
+
+func TestQueue_Quota(t *testing.T) {
+	ctx := context.Background()
+	f := newFake()
+	q := New(f, WithQuota(SceneQuota(map[reply.Scene]int{reply.SceneGuild: 2})))
+	results, stop := run(q)
+	defer stop()
+
+	for _, content := range []string{"1", "2", "3"} {
+		_, err := q.Enqueue(ctx, guildTarget, &dto.MessageToCreate{Content: content})
+		assert.Nil(t, err)
+	}
+	for _, content := range []string{"1", "2"} {
+		result := wait(t, results)
+		assert.Nil(t, result.Err)
+		assert.Equal(t, content, result.Message.Content)
+	}
+	assert.Equal(t, ErrQuotaExceeded, wait(t, results).Err)
+	f.AssertPostedCount(t, "channel", 2)
+	used, err := q.Usage(ctx, guildTarget)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, used)
+	assert.Equal(t, 0, q.Pending())
+
+	// 不限制额度的场景
+	_, err = q.Enqueue(ctx, groupTarget, &dto.MessageToCreate{Content: "group"})
+	assert.Nil(t, err)
+	assert.Nil(t, wait(t, results).Err)
+	assert.Len(t, f.PostedGroupMessages("group"), 1)
+}
+
+func TestQueue_Retry(t *testing.T) {
+	ctx := context.Background()
+	f := newFake()
+	limited := errs.NewAPIError(http.StatusTooManyRequests, []byte(`{"code":22009,"message":"too frequent"}`), "")
+	f.InjectError("PostGroupMessage", limited, nil)
+	q := New(f, WithRetryDelay(10*time.Millisecond),
+		WithQuota(SceneQuota(map[reply.Scene]int{reply.SceneGroup: 4})))
+	results, stop := run(q)
+	defer stop()
+
+	_, err := q.Enqueue(ctx, groupTarget, &dto.MessageToCreate{Content: "hello"})
+	assert.Nil(t, err)
+	result := wait(t, results)
+	assert.Nil(t, result.Err)
+	assert.Equal(t, 2, result.Item.Attempts)
+	// 失败的发送归还了额度
+	used, _ := q.Usage(ctx, groupTarget)
+	assert.Equal(t, 1, used)
+
+	// 不可重试的错误直接通知失败
+	f.ResetErrors()
+	forbidden := errors.New("forbidden")
+	f.InjectError("PostGroupMessage", forbidden)
+	_, err = q.Enqueue(ctx, groupTarget, &dto.MessageToCreate{Content: "hello"})
+	assert.Nil(t, err)
+	result = wait(t, results)
+	assert.Equal(t, forbidden, result.Err)
+	assert.Equal(t, 1, result.Item.Attempts)
+}
+
+func TestQueue_TargetBackoff(t *testing.T) {
+	ctx := context.Background()
+	f := newFake()
+	limiter := openapi.NewRouteLimiter(DefaultRate, DefaultBurst)
+	q := New(f, WithLimiter(limiter))
+	results, stop := run(q)
+	defer stop()
+
+	// 一个目标退避时，其他目标的消息仍然可以发送
+	limiter.Backoff(QuotaKey(guildTarget), time.Hour)
+	_, err := q.Enqueue(ctx, guildTarget, &dto.MessageToCreate{Content: "guild"})
+	assert.Nil(t, err)
+	_, err = q.Enqueue(ctx, groupTarget, &dto.MessageToCreate{Content: "group"})
+	assert.Nil(t, err)
+	result := wait(t, results)
+	assert.Nil(t, result.Err)
+	assert.Equal(t, groupTarget, result.Item.Target)
+	assert.Equal(t, 1, q.Pending())
+}
+
+func TestQueue_CarryOver(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2022, 1, 1, 23, 0, 0, 0, Location)
+	q := New(newFake(), WithCarryOver(), WithQuota(SceneQuota(map[reply.Scene]int{reply.SceneGuild: 1})))
+	q.now = func() time.Time { return now }
+	for i := 0; i < 2; i++ {
+		_, err := q.Enqueue(ctx, guildTarget, &dto.MessageToCreate{Content: "hello"})
+		assert.Nil(t, err)
+	}
+	sendNext(ctx, t, q)
+	sendNext(ctx, t, q)
+	// 第二条顺延到第二天零点
+	assert.Equal(t, 1, q.Pending())
+	_, wait := q.next()
+	assert.Equal(t, time.Hour, wait)
+
+	now = now.Add(time.Hour)
+	sendNext(ctx, t, q)
+	assert.Equal(t, 0, q.Pending())
+	used, _ := q.Usage(ctx, guildTarget)
+	assert.Equal(t, 1, used)
+}
+
+func TestQueue_Persistence(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "outbox.json")
+	f := newFake()
+	_, err := New(f, WithStore(NewFileStore(path))).Enqueue(ctx, guildTarget, &dto.MessageToCreate{Content: "hello"})
+	assert.Nil(t, err)
+	f.AssertPostedCount(t, "channel", 0)
+
+	// 重启后继续发送
+	store := NewFileStore(path)
+	q := New(f, WithStore(store))
+	results, stop := run(q)
+	defer stop()
+	assert.Nil(t, wait(t, results).Err)
+	f.AssertPostedContent(t, "channel", "hello")
+	items, err := NewFileStore(path).Load(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, items, 0)
+}
+
+func TestMessageSettingQuota(t *testing.T) {
+	ctx := context.Background()
+	f := newFake()
+	f.SetMessageSetting("guild", &dto.MessageSetting{ChannelPushMaxNum: 3})
+	quota := MessageSettingQuota(f, time.Minute, SceneQuota(map[reply.Scene]int{reply.SceneGuild: 1}))
+	for i := 0; i < 2; i++ {
+		limit, err := quota(ctx, guildTarget)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, limit)
+	}
+	assert.Len(t, f.Calls("GetMessageSetting"), 1)
+	limit, _ := quota(ctx, reply.Target{Scene: reply.SceneGuild, ChannelID: "other"})
+	assert.Equal(t, 1, limit)
+}
+
+func TestMemoryStore_AddUsage(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+	used, _ := s.AddUsage(ctx, "20220101", "key", 1)
+	assert.Equal(t, 1, used)
+	used, _ = s.AddUsage(ctx, "20220102", "key", 1)
+	assert.Equal(t, 1, used)
+	// 跨天之后归还前一天的额度，不影响当天的使用量
+	used, _ = s.AddUsage(ctx, "20220101", "key", -1)
+	assert.Equal(t, 0, used)
+	used, _ = s.AddUsage(ctx, "20220102", "key", 0)
+	assert.Equal(t, 1, used)
+	used, _ = s.AddUsage(ctx, "20220102", "key", -2)
+	assert.Equal(t, 0, used)
+}
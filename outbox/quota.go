@@ -0,0 +1,89 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/tencent-connect/botgo/openapi"
+	"github.com/tencent-connect/botgo/reply"
+)
+
+// Location 统计每天额度使用的时区，与开放平台一致使用东八区
+var Location = time.FixedZone("UTC+8", 8*60*60)
+
+// QuotaFunc 返回目标每天可以发送的主动消息数量，0 表示不限制
+type QuotaFunc func(ctx context.Context, target reply.Target) (int, error)
+
+// SceneQuota 按照场景返回固定的额度，没有配置的场景不限制
+func SceneQuota(quotas map[reply.Scene]int) QuotaFunc {
+	return func(_ context.Context, target reply.Target) (int, error) {
+		return quotas[target.Scene], nil
+	}
+}
+
+// MessageSettingQuota 频道子频道的额度使用频道消息设置中的 channel_push_max_num，其他场景以及没有设置时使用 fallback
+//
+// 频道的消息设置缓存 ttl 时间，guild 场景的目标需要设置 GuildID
+func MessageSettingQuota(api openapi.MessageSettingAPI, ttl time.Duration, fallback QuotaFunc) QuotaFunc {
+	type cached struct {
+		limit     int
+		expiresAt time.Time
+	}
+	var lock sync.Mutex
+	settings := map[string]cached{}
+	return func(ctx context.Context, target reply.Target) (int, error) {
+		if target.Scene != reply.SceneGuild || target.GuildID == "" {
+			return fallbackQuota(ctx, fallback, target)
+		}
+		lock.Lock()
+		c, ok := settings[target.GuildID]
+		lock.Unlock()
+		if !ok || time.Now().After(c.expiresAt) {
+			setting, err := api.GetMessageSetting(ctx, target.GuildID)
+			if err != nil {
+				return 0, err
+			}
+			c = cached{limit: setting.ChannelPushMaxNum, expiresAt: time.Now().Add(ttl)}
+			lock.Lock()
+			settings[target.GuildID] = c
+			lock.Unlock()
+		}
+		if c.limit > 0 {
+			return c.limit, nil
+		}
+		return fallbackQuota(ctx, fallback, target)
+	}
+}
+
+func fallbackQuota(ctx context.Context, fallback QuotaFunc, target reply.Target) (int, error) {
+	if fallback == nil {
+		return 0, nil
+	}
+	return fallback(ctx, target)
+}
+
+// Day 返回 t 所在的日期，用于统计每天的额度
+func Day(t time.Time) string {
+	return t.In(Location).Format("20060102")
+}
+
+// nextDay 返回 t 的第二天零点
+func nextDay(t time.Time) time.Time {
+	y, m, d := t.In(Location).Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, Location)
+}
+
+// QuotaKey 额度统计的 key，子频道与私信按照子频道，单聊按照用户，群聊按照群统计
+func QuotaKey(target reply.Target) string {
+	switch target.Scene {
+	case reply.SceneC2C:
+		return "c2c_" + target.UserOpenID
+	case reply.SceneGroup:
+		return "group_" + target.GroupOpenID
+	case reply.SceneDirect:
+		return "direct_" + target.GuildID
+	default:
+		return "guild_" + target.ChannelID
+	}
+}
@@ -0,0 +1,111 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	redis "github.com/go-redis/redis/v8"
+)
+
+// 默认配置
+const (
+	// DefaultRedisKeyPrefix redis key 的默认前缀
+	DefaultRedisKeyPrefix = "botgo_outbox_"
+	// usageExpire 额度使用量的过期时间，覆盖跨天前后的时间差
+	usageExpire = 48 * time.Hour
+)
+
+// RedisStore 基于 redis 的 Store，待发送的消息保存在一个 hash 中，额度使用量每天每个目标一个 key
+// 使用相同前缀的实例共享额度使用量，待发送的消息按照 WithInstance 区分，每个实例只加载与发送自己的消息，
+// 多个实例使用相同的 instance 时会重复发送消息
+type RedisStore struct {
+	client   redis.UniversalClient
+	prefix   string
+	instance string
+}
+
+// addUsageScript 增加使用量并刷新过期时间，使用量最小为 0，与其他 Store 保持一致
+var addUsageScript = redis.NewScript(`
+local n = redis.call("INCRBY", KEYS[1], ARGV[1])
+if n < 0 then
+	n = 0
+	redis.call("SET", KEYS[1], 0)
+end
+redis.call("EXPIRE", KEYS[1], ARGV[2])
+return n
+`)
+
+// RedisOption RedisStore 的可选配置
+type RedisOption func(s *RedisStore)
+
+// WithKeyPrefix 设置 redis key 的前缀，默认为 DefaultRedisKeyPrefix，多个机器人共用 redis 时需要区分
+func WithKeyPrefix(prefix string) RedisOption {
+	return func(s *RedisStore) {
+		s.prefix = prefix
+	}
+}
+
+// WithInstance 设置实例的标识，多个实例共享额度时需要使用不同的标识，重启后使用相同的标识继续发送之前的消息
+func WithInstance(instance string) RedisOption {
+	return func(s *RedisStore) {
+		s.instance = instance
+	}
+}
+
+// NewRedisStore 创建 redis Store
+func NewRedisStore(client redis.UniversalClient, opts ...RedisOption) *RedisStore {
+	s := &RedisStore{client: client, prefix: DefaultRedisKeyPrefix}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Load 加载全部待发送的消息
+func (s *RedisStore) Load(ctx context.Context) ([]*Item, error) {
+	values, err := s.client.HGetAll(ctx, s.itemsKey()).Result()
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*Item, 0, len(values))
+	for _, value := range values {
+		item := &Item{}
+		if err := json.Unmarshal([]byte(value), item); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// Save 保存待发送的消息
+func (s *RedisStore) Save(ctx context.Context, item *Item) error {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	return s.client.HSet(ctx, s.itemsKey(), item.ID, data).Err()
+}
+
+// Delete 删除消息
+func (s *RedisStore) Delete(ctx context.Context, id string) error {
+	return s.client.HDel(ctx, s.itemsKey(), id).Err()
+}
+
+// AddUsage 增加额度使用量
+func (s *RedisStore) AddUsage(ctx context.Context, day, key string, delta int) (int, error) {
+	usageKey := s.prefix + "usage_" + day + "_" + key
+	n, err := addUsageScript.Run(ctx, s.client, []string{usageKey}, delta, int(usageExpire.Seconds())).Int()
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+func (s *RedisStore) itemsKey() string {
+	if s.instance == "" {
+		return s.prefix + "items"
+	}
+	return s.prefix + "items_" + s.instance
+}
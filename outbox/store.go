@@ -0,0 +1,92 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+)
+
+// Store 持久化待发送的消息与每天的额度使用量，进程重启后继续发送
+type Store interface {
+	// Load 加载全部待发送的消息
+	Load(ctx context.Context) ([]*Item, error)
+	// Save 保存待发送的消息，ID 相同时覆盖
+	Save(ctx context.Context, item *Item) error
+	// Delete 删除已经发送完成的消息
+	Delete(ctx context.Context, id string) error
+	// AddUsage 增加 key 在 day 的额度使用量并返回增加后的值，delta 为负数时归还额度，使用量最小为 0
+	AddUsage(ctx context.Context, day, key string, delta int) (int, error)
+}
+
+// MemoryStore 基于内存的 Store，进程重启后数据丢失，用于测试或者不需要持久化的场景
+type MemoryStore struct {
+	lock  sync.Mutex
+	items map[string]*Item
+	usage usage
+}
+
+// NewMemoryStore 创建内存 Store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{items: map[string]*Item{}}
+}
+
+// Load 加载全部待发送的消息
+func (s *MemoryStore) Load(_ context.Context) ([]*Item, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	items := make([]*Item, 0, len(s.items))
+	for _, item := range s.items {
+		items = append(items, item.clone())
+	}
+	return items, nil
+}
+
+// Save 保存待发送的消息
+func (s *MemoryStore) Save(_ context.Context, item *Item) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.items[item.ID] = item.clone()
+	return nil
+}
+
+// Delete 删除消息
+func (s *MemoryStore) Delete(_ context.Context, id string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.items, id)
+	return nil
+}
+
+// AddUsage 增加额度使用量
+func (s *MemoryStore) AddUsage(_ context.Context, day, key string, delta int) (int, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.usage.add(day, key, delta), nil
+}
+
+// usage 一天的额度使用量，只保留最近一天的数据
+type usage struct {
+	Day    string         `json:"day"`
+	Counts map[string]int `json:"counts"`
+}
+
+// add 修改 day 的使用量，day 比保存的更早时忽略，如跨天之后归还前一天的额度，不能清空当天的使用量
+func (u *usage) add(day, key string, delta int) int {
+	if day < u.Day {
+		return 0
+	}
+	if u.Day != day || u.Counts == nil {
+		u.Day, u.Counts = day, map[string]int{}
+	}
+	n := u.Counts[key] + delta
+	if n < 0 {
+		n = 0
+	}
+	u.Counts[key] = n
+	return n
+}
+
+var (
+	_ Store = (*MemoryStore)(nil)
+	_ Store = (*FileStore)(nil)
+	_ Store = (*RedisStore)(nil)
+)
@@ -0,0 +1,302 @@
+// Package reply 提供绑定到收到的消息或者事件的回复工具，自动维护被动回复需要的 msg_id，event_id 与 msg_seq
+package reply
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tencent-connect/botgo/dto"
+	"github.com/tencent-connect/botgo/errs"
+	"github.com/tencent-connect/botgo/openapi"
+)
+
+// Scene 消息的场景
+type Scene int
+
+// 支持的场景
+const (
+	SceneGuild  Scene = iota + 1 // 频道
+	SceneDirect                  // 频道私信
+	SceneC2C                     // 单聊
+	SceneGroup                   // 群聊
+)
+
+var sceneNames = map[Scene]string{
+	SceneGuild:  "guild",
+	SceneDirect: "direct",
+	SceneC2C:    "c2c",
+	SceneGroup:  "group",
+}
+
+// String 场景名称
+func (s Scene) String() string {
+	return sceneNames[s]
+}
+
+// hasSeq 是否使用 msg_seq 区分对同一条消息的多次回复
+func (s Scene) hasSeq() bool {
+	return s == SceneC2C || s == SceneGroup
+}
+
+// Windows 各个场景被动回复的有效期，从收到消息的时间开始计算
+var Windows = map[Scene]time.Duration{
+	SceneGuild:  5 * time.Minute,
+	SceneDirect: 5 * time.Minute,
+	SceneC2C:    60 * time.Minute,
+	SceneGroup:  5 * time.Minute,
+}
+
+// MaxReplies 同一条消息或者事件最多可以被动回复的次数，没有配置的场景不限制
+var MaxReplies = map[Scene]int{
+	SceneC2C:   5,
+	SceneGroup: 5,
+}
+
+// Target 回复的目标
+type Target struct {
+	Scene       Scene  `json:"scene"`
+	ChannelID   string `json:"channel_id,omitempty"`   // 频道与私信的子频道 ID
+	GuildID     string `json:"guild_id,omitempty"`     // 私信的频道 ID
+	UserOpenID  string `json:"user_openid,omitempty"`  // 单聊的用户 openid
+	GroupOpenID string `json:"group_openid,omitempty"` // 群聊的 openid
+}
+
+// TargetOf 根据收到的消息生成回复的目标
+func TargetOf(scene Scene, msg *dto.Message) Target {
+	target := Target{Scene: scene, ChannelID: msg.ChannelID, GuildID: msg.GuildID, GroupOpenID: msg.GroupOpenID}
+	if msg.Author != nil {
+		target.UserOpenID = msg.Author.OpenID
+	}
+	return target
+}
+
+// WindowClosedError 被动回复的有效期已过，或者回复次数已经达到上限
+//
+// 可以通过 errors.Is(err, errs.ErrPassiveReplyExpired) 判断，与开放平台返回的过期错误一致
+type WindowClosedError struct {
+	Scene    Scene
+	Deadline time.Time // 被动回复的截止时间
+	Limit    int       // 达到上限时为回复次数的上限，否则为 0
+}
+
+// Error 实现 error 接口
+func (e *WindowClosedError) Error() string {
+	if e.Limit > 0 {
+		return fmt.Sprintf("passive reply limit %d of %s reached", e.Limit, e.Scene)
+	}
+	return fmt.Sprintf("passive reply window of %s closed at %s", e.Scene, e.Deadline.Format(time.RFC3339))
+}
+
+// Is 与 errs.ErrPassiveReplyExpired 相等
+func (e *WindowClosedError) Is(target error) bool {
+	return target == errs.ErrPassiveReplyExpired
+}
+
+// Replier 绑定到一条收到的消息或者事件的回复工具，可以并发使用
+//
+// 每次回复自动填充 msg_id 或者 event_id，单聊与群聊中自动递增 msg_seq，避免多次回复同一条消息时失败
+type Replier struct {
+	api        openapi.OpenAPI
+	target     Target
+	msgID      string
+	eventID    string
+	receivedAt time.Time
+	fallback   bool
+	seq        int32
+	now        func() time.Time
+	lock       sync.Mutex
+	sent       []string // 发送成功的消息 ID，用于撤回
+}
+
+// Option 回复工具的配置
+type Option func(r *Replier)
+
+// WithFallback 被动回复的有效期已过或者次数达到上限时，作为主动消息发送，而不是返回 WindowClosedError
+func WithFallback() Option {
+	return func(r *Replier) {
+		r.fallback = true
+	}
+}
+
+// WithReceivedAt 设置收到消息的时间，用于计算被动回复的有效期，默认使用消息的发送时间
+func WithReceivedAt(t time.Time) Option {
+	return func(r *Replier) {
+		r.receivedAt = t
+	}
+}
+
+// New 创建回复收到的消息的工具
+func New(api openapi.OpenAPI, scene Scene, msg *dto.Message, opts ...Option) *Replier {
+	receivedAt, err := msg.Timestamp.Time()
+	if err != nil {
+		receivedAt = time.Now()
+	}
+	r := &Replier{api: api, target: TargetOf(scene, msg), msgID: msg.ID, receivedAt: receivedAt, now: time.Now}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// NewForEvent 创建回复收到的事件的工具，如群聊添加机器人，互动事件等
+func NewForEvent(api openapi.OpenAPI, target Target, eventID string, opts ...Option) *Replier {
+	r := &Replier{api: api, target: target, eventID: eventID, receivedAt: time.Now(), now: time.Now}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Target 返回回复的目标
+func (r *Replier) Target() Target {
+	return r.target
+}
+
+// Deadline 返回被动回复的截止时间
+func (r *Replier) Deadline() time.Time {
+	return r.receivedAt.Add(Windows[r.target.Scene])
+}
+
+// Replies 返回已经发送的被动回复次数
+func (r *Replier) Replies() int {
+	return int(atomic.LoadInt32(&r.seq))
+}
+
+// Text 回复文本消息
+func (r *Replier) Text(ctx context.Context, content string) (*dto.Message, error) {
+	return r.Send(ctx, &dto.MessageToCreate{Content: content})
+}
+
+// Send 回复消息，没有指定 MsgID 与 EventID 时作为被动回复，不会修改传入的 msg
+func (r *Replier) Send(ctx context.Context, msg *dto.MessageToCreate) (*dto.Message, error) {
+	toCreate := *msg
+	if toCreate.MsgID == "" && toCreate.EventID == "" {
+		toCreate.MsgID, toCreate.EventID = r.msgID, r.eventID
+	}
+	if !r.isPassive(&toCreate) {
+		return r.post(ctx, &toCreate)
+	}
+	seq := int(atomic.AddInt32(&r.seq, 1))
+	if err := r.check(seq); err != nil {
+		return r.fallbackOr(ctx, msg, err)
+	}
+	if toCreate.MessageSeq == 0 && r.target.Scene.hasSeq() {
+		toCreate.MessageSeq = seq
+	}
+	resp, err := r.post(ctx, &toCreate)
+	if errors.Is(err, errs.ErrPassiveReplyExpired) {
+		return r.fallbackOr(ctx, msg, err)
+	}
+	return resp, err
+}
+
+// fallbackOr 开启 WithFallback 时作为主动消息发送，否则返回被动回复的错误
+func (r *Replier) fallbackOr(ctx context.Context, msg *dto.MessageToCreate, err error) (*dto.Message, error) {
+	if !r.fallback {
+		return nil, err
+	}
+	return r.post(ctx, active(msg))
+}
+
+// isPassive 是否是对绑定的消息或者事件的被动回复
+func (r *Replier) isPassive(msg *dto.MessageToCreate) bool {
+	return (r.msgID != "" && msg.MsgID == r.msgID) || (r.eventID != "" && msg.EventID == r.eventID)
+}
+
+// check 检查被动回复的有效期与次数
+func (r *Replier) check(seq int) error {
+	deadline := r.Deadline()
+	if !r.now().Before(deadline) {
+		return &WindowClosedError{Scene: r.target.Scene, Deadline: deadline}
+	}
+	if limit := MaxReplies[r.target.Scene]; limit > 0 && seq > limit {
+		return &WindowClosedError{Scene: r.target.Scene, Deadline: deadline, Limit: limit}
+	}
+	return nil
+}
+
+func (r *Replier) post(ctx context.Context, msg *dto.MessageToCreate) (*dto.Message, error) {
+	resp, err := Post(ctx, r.api, r.target, msg)
+	if err == nil && resp != nil && resp.ID != "" {
+		r.lock.Lock()
+		r.sent = append(r.sent, resp.ID)
+		r.lock.Unlock()
+	}
+	return resp, err
+}
+
+// Sent 返回通过回复工具发送成功，并且没有撤回的消息 ID
+func (r *Replier) Sent() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]string(nil), r.sent...)
+}
+
+// Retract 撤回通过回复工具发送的消息
+func (r *Replier) Retract(ctx context.Context, msgID string, options ...openapi.RetractMessageOption) error {
+	if err := Retract(ctx, r.api, r.target, msgID, options...); err != nil {
+		return err
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for i, id := range r.sent {
+		if id == msgID {
+			r.sent = append(r.sent[:i:i], r.sent[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// RetractAll 撤回通过回复工具发送的全部消息，如收到的消息被审核拒绝或者撤回时，一并撤回机器人的回复
+// 某条消息撤回失败时继续撤回其他消息，返回第一个错误
+func (r *Replier) RetractAll(ctx context.Context, options ...openapi.RetractMessageOption) error {
+	var first error
+	for _, msgID := range r.Sent() {
+		if err := r.Retract(ctx, msgID, options...); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Post 按照目标的场景调用对应的发消息接口
+func Post(ctx context.Context, api openapi.OpenAPI, target Target, msg *dto.MessageToCreate) (*dto.Message, error) {
+	switch target.Scene {
+	case SceneDirect:
+		dm := &dto.DirectMessage{GuildID: target.GuildID, ChannelID: target.ChannelID}
+		return api.PostDirectMessage(ctx, dm, msg)
+	case SceneC2C:
+		return api.PostUserMessage(ctx, target.UserOpenID, msg)
+	case SceneGroup:
+		return api.PostGroupMessage(ctx, target.GroupOpenID, msg)
+	default:
+		return api.PostMessage(ctx, target.ChannelID, msg)
+	}
+}
+
+// Retract 按照目标的场景调用对应的撤回消息接口，msgID 为发消息接口返回的消息 ID
+func Retract(ctx context.Context, api openapi.OpenAPI, target Target, msgID string,
+	options ...openapi.RetractMessageOption) error {
+	switch target.Scene {
+	case SceneDirect:
+		return api.RetractDMMessage(ctx, target.GuildID, msgID, options...)
+	case SceneC2C:
+		return api.RetractUserMessage(ctx, target.UserOpenID, msgID, options...)
+	case SceneGroup:
+		return api.RetractGroupMessage(ctx, target.GroupOpenID, msgID, options...)
+	default:
+		return api.RetractMessage(ctx, target.ChannelID, msgID, options...)
+	}
+}
+
+// active 去掉被动回复的字段，作为主动消息发送
+func active(msg *dto.MessageToCreate) *dto.MessageToCreate {
+	toCreate := *msg
+	toCreate.MsgID, toCreate.EventID, toCreate.MessageSeq = "", "", 0
+	return &toCreate
+}
@@ -0,0 +1,126 @@
+package reply
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tencent-connect/botgo/dto"
+	"github.com/tencent-connect/botgo/errs"
+	"github.com/tencent-connect/botgo/openapi"
+	"github.com/tencent-connect/botgo/openapi/fake"
+)
+
+func groupMessage(sentAt time.Time) *dto.Message {
+	return &dto.Message{
+		ID:          "msg",
+		GroupOpenID: "group",
+		Content:     "hello",
+		Timestamp:   dto.Timestamp(sentAt.Format(time.RFC3339)),
+		Author:      &dto.User{MemberOpenID: "member"},
+	}
+}
+
+func TestReplier_Send(t *testing.T) {
+	ctx := context.Background()
+	t.Run("increments seq", func(t *testing.T) {
+		api := fake.New()
+		r := New(api, SceneGroup, groupMessage(time.Now()))
+		for i := 0; i < MaxReplies[SceneGroup]; i++ {
+			_, err := r.Text(ctx, "reply")
+			assert.Nil(t, err)
+		}
+		posted := api.PostedGroupMessages("group")
+		assert.Len(t, posted, 5)
+		for i, msg := range posted {
+			assert.Equal(t, "msg", msg.MsgID)
+			assert.Equal(t, i+1, msg.MessageSeq)
+		}
+		_, err := r.Text(ctx, "reply")
+		var closed *WindowClosedError
+		assert.True(t, errors.As(err, &closed))
+		assert.Equal(t, 5, closed.Limit)
+		assert.True(t, errors.Is(err, errs.ErrPassiveReplyExpired))
+		assert.Len(t, api.PostedGroupMessages("group"), 5)
+	})
+	t.Run("window closed", func(t *testing.T) {
+		api := fake.New()
+		api.AddChannel(&dto.Channel{ID: "channel", GuildID: "guild"})
+		r := New(api, SceneGuild, &dto.Message{ID: "msg", ChannelID: "channel"},
+			WithReceivedAt(time.Now().Add(-10*time.Minute)))
+		_, err := r.Text(ctx, "reply")
+		assert.True(t, errors.Is(err, errs.ErrPassiveReplyExpired))
+		assert.Empty(t, api.PostedMessages("channel"))
+
+		// 回复其他消息时不检查有效期
+		_, err = r.Send(ctx, &dto.MessageToCreate{Content: "reply", MsgID: "other"})
+		assert.Nil(t, err)
+		assert.Equal(t, "other", api.PostedMessages("channel")[0].MsgID)
+	})
+	t.Run("fallback to active message", func(t *testing.T) {
+		api := fake.New()
+		r := New(api, SceneC2C, &dto.Message{ID: "msg", Author: &dto.User{OpenID: "user"}},
+			WithReceivedAt(time.Now().Add(-2*time.Hour)), WithFallback())
+		_, err := r.Text(ctx, "reply")
+		assert.Nil(t, err)
+		posted := api.PostedUserMessages("user")
+		assert.Len(t, posted, 1)
+		assert.Equal(t, "", posted[0].MsgID)
+		assert.Equal(t, 0, posted[0].MessageSeq)
+
+		// 开放平台返回过期时也会作为主动消息发送
+		r = New(api, SceneC2C, &dto.Message{ID: "msg2", Author: &dto.User{OpenID: "user"}}, WithFallback())
+		expired := errs.NewAPIError(http.StatusBadRequest, []byte(`{"code":304027,"message":"expired"}`), "")
+		api.InjectError("PostUserMessage", expired, nil)
+		_, err = r.Text(ctx, "reply")
+		assert.Nil(t, err)
+		posted = api.PostedUserMessages("user")
+		assert.Len(t, posted, 2)
+		assert.Equal(t, "", posted[1].MsgID)
+	})
+	t.Run("event", func(t *testing.T) {
+		api := fake.New()
+		r := NewForEvent(api, Target{Scene: SceneGroup, GroupOpenID: "group"}, "event")
+		msg := &dto.MessageToCreate{Content: "welcome"}
+		_, err := r.Send(ctx, msg)
+		assert.Nil(t, err)
+		assert.Equal(t, "", msg.EventID)
+		posted := api.PostedGroupMessages("group")
+		assert.Equal(t, "event", posted[0].EventID)
+		assert.Equal(t, 1, posted[0].MessageSeq)
+		assert.Equal(t, 1, r.Replies())
+	})
+}
+
+func TestReplier_Retract(t *testing.T) {
+	ctx := context.Background()
+	api := fake.New()
+	r := New(api, SceneGroup, groupMessage(time.Now()))
+	first, err := r.Text(ctx, "first")
+	assert.Nil(t, err)
+	second, err := r.Text(ctx, "second")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{first.ID, second.ID}, r.Sent())
+
+	assert.Nil(t, r.Retract(ctx, first.ID, openapi.RetractMessageOptionHidetip))
+	assert.Equal(t, []string{second.ID}, r.Sent())
+	calls := api.Calls("RetractGroupMessage")
+	assert.Len(t, calls, 1)
+	assert.Equal(t, []interface{}{"group", first.ID,
+		[]openapi.RetractMessageOption{openapi.RetractMessageOptionHidetip}}, calls[0].Args)
+
+	// 已经撤回的消息返回错误，其他消息继续撤回
+	r.sent = append(r.sent, first.ID)
+	assert.NotNil(t, r.RetractAll(ctx))
+	assert.Equal(t, []string{first.ID}, r.Sent())
+	assert.Len(t, api.Calls("RetractGroupMessage"), 3)
+
+	target := Target{Scene: SceneC2C, UserOpenID: "user"}
+	msg, err := Post(ctx, api, target, &dto.MessageToCreate{Content: "hello"})
+	assert.Nil(t, err)
+	assert.Nil(t, Retract(ctx, api, target, msg.ID))
+	assert.Len(t, api.Calls("RetractUserMessage"), 1)
+}
@@ -1,6 +1,8 @@
 package botgo
 
 import (
+	"context"
+
 	"github.com/tencent-connect/botgo/dto"
 	"github.com/tencent-connect/botgo/sessions/local"
 	"github.com/tencent-connect/botgo/token"
@@ -13,5 +15,6 @@ var defaultSessionManager SessionManager = local.New()
 // SessionManager 接口，管理session
 type SessionManager interface {
 	// Start 启动连接，默认使用 apInfo 中的 shards 作为 shard 数量，如果有需要自己指定 shard 数，请修 apInfo 中的信息
-	Start(apInfo *dto.WebsocketAP, token *token.Token, intents *dto.Intent) error
+	// Start 会阻塞直到 ctx 结束，ctx 结束后关闭所有连接并返回，关闭过程中各个 shard 的错误通过 manager.ShardErrors 返回
+	Start(ctx context.Context, apInfo *dto.WebsocketAP, token *token.Token, intents *dto.Intent) error
 }
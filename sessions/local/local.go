@@ -2,10 +2,14 @@
 package local
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/tencent-connect/botgo/dto"
+	"github.com/tencent-connect/botgo/errs"
+	"github.com/tencent-connect/botgo/event"
 	"github.com/tencent-connect/botgo/log"
 	"github.com/tencent-connect/botgo/sessions/manager"
 	"github.com/tencent-connect/botgo/token"
@@ -13,26 +17,41 @@ import (
 )
 
 // New 创建本地session管理器
-func New() *ChanManager {
-	return &ChanManager{}
+func New(opts ...Option) *ChanManager {
+	l := &ChanManager{
+		shutdownTimeout: manager.DefaultShutdownTimeout,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
 }
 
 // ChanManager 默认的本地 session manager 实现
 type ChanManager struct {
-	sessionChan chan dto.Session
+	sessionChan     chan dto.Session
+	dispatcher      *event.Dispatcher
+	shutdownTimeout time.Duration
+	conns           *manager.Connections   // 正在运行的连接
+	store           websocket.SessionStore // 持久化 session 状态，为空时每次启动都重新鉴权
+	budget          *manager.StartBudget   // 鉴权需要消耗的启动额度
+	onStartLimit    manager.StartLimitHandler
 }
 
-// Start 启动本地 session manager
-func (l *ChanManager) Start(apInfo *dto.WebsocketAP, token *token.Token, intents *dto.Intent) error {
+// Start 启动本地 session manager，会阻塞直到 ctx 结束
+// 启动额度不足时，在额度允许的范围内启动 shard，剩余的 shard 在额度重置后启动
+// ctx 结束后会关闭所有连接，等待已经收到的事件处理完成后返回，关闭过程中各个 shard 的错误通过 manager.ShardErrors 返回
+func (l *ChanManager) Start(
+	ctx context.Context, apInfo *dto.WebsocketAP, token *token.Token, intents *dto.Intent,
+) error {
 	defer log.Sync()
-	if err := manager.CheckSessionLimit(apInfo); err != nil {
-		log.Errorf("[ws/session/local] session limited apInfo: %+v", apInfo)
-		return err
-	}
 	startInterval := manager.CalcInterval(apInfo.SessionStartLimit.MaxConcurrency)
 	log.Infof("[ws/session/local] will start %d sessions and per session start interval is %s",
 		apInfo.Shards, startInterval)
 
+	l.conns = manager.NewConnections()
+	l.budget = manager.NewStartBudget(apInfo.SessionStartLimit)
+	log.Infof("[ws/session/local] session start limit: %+v", apInfo.SessionStartLimit)
 	// 按照shards数量初始化，用于启动连接的管理
 	l.sessionChan = make(chan dto.Session, apInfo.Shards)
 	for i := uint32(0); i < apInfo.Shards; i++ {
@@ -46,35 +65,112 @@ func (l *ChanManager) Start(apInfo *dto.WebsocketAP, token *token.Token, intents
 				ShardCount: apInfo.Shards,
 			},
 		}
-		l.sessionChan <- session
+		l.sessionChan <- l.restore(ctx, session)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return l.shutdown(apInfo)
+		case session := <-l.sessionChan:
+			// 启动额度不足，等待额度重置后再启动
+			if wait, ok := l.budget.AcquireFor(session, l.onStartLimit); !ok {
+				l.conns.Add()
+				go l.delayStart(ctx, session, wait)
+				continue
+			}
+			// MaxConcurrency 代表的是每 5s 可以连多少个请求
+			if !manager.Sleep(ctx, startInterval) {
+				return l.shutdown(apInfo)
+			}
+			l.conns.Add()
+			go l.newConnect(ctx, session)
+		}
+	}
+}
+
+// delayStart 等待启动额度重置后，将 session 放回队列启动
+func (l *ChanManager) delayStart(ctx context.Context, session dto.Session, wait time.Duration) {
+	defer l.conns.Done()
+	if manager.Sleep(ctx, wait) {
+		l.requeue(ctx, session)
+	}
+}
+
+// restore 从 store 中恢复 session id 与 seq，启动时优先 resume，resume 失败收到 InvalidSession 后会重新鉴权
+func (l *ChanManager) restore(ctx context.Context, session dto.Session) dto.Session {
+	if l.store == nil {
+		return session
+	}
+	state, err := l.store.Load(ctx, session)
+	if err != nil {
+		log.Errorf("[ws/session/local] load session %s failed, err: %v", &session, err)
+		return session
+	}
+	if state == nil || state.ID == "" {
+		return session
+	}
+	log.Infof("[ws/session/local] restore session %s, id %s, seq %d", &session, state.ID, state.LastSeq)
+	session.ID = state.ID
+	session.LastSeq = state.LastSeq
+	return session
+}
+
+// shutdown 关闭所有连接，并等待连接处理完已经收到的事件
+func (l *ChanManager) shutdown(apInfo *dto.WebsocketAP) error {
+	log.Infof("[ws/session/local] shutting down %d sessions", apInfo.Shards)
+	err := l.conns.Shutdown(l.shutdownTimeout)
+	// 连接都退出后，写入 store 中还没有保存的状态
+	if closer, ok := l.store.(io.Closer); ok {
+		if closeErr := closer.Close(); closeErr != nil {
+			log.Errorf("[ws/session/local] close session store failed: %v", closeErr)
+		}
 	}
+	return err
+}
 
-	for session := range l.sessionChan {
-		// MaxConcurrency 代表的是每 5s 可以连多少个请求
-		time.Sleep(startInterval)
-		go l.newConnect(session)
+// requeue 将 session 放回队列等待重连，关闭过程中不再重连
+func (l *ChanManager) requeue(ctx context.Context, session dto.Session) {
+	if ctx.Err() != nil {
+		return
 	}
-	return nil
+	l.sessionChan <- session
 }
 
 // newConnect 启动一个新的连接，如果连接在监听过程中报错了，或者被远端关闭了链接，需要识别关闭的原因，能否继续 resume
 // 如果能够 resume，则往 sessionChan 中放入带有 sessionID 的 session
 // 如果不能，则清理掉 sessionID，将 session 放入 sessionChan 中
 // session 的启动，交给 start 中的 for 循环执行，session 不自己递归进行重连，避免递归深度过深
-func (l *ChanManager) newConnect(session dto.Session) {
+func (l *ChanManager) newConnect(ctx context.Context, session dto.Session) {
+	defer l.conns.Done()
 	defer func() {
 		// panic 留下日志，放回 session
 		if err := recover(); err != nil {
 			websocket.PanicHandler(err, &session)
-			l.sessionChan <- session
+			l.requeue(ctx, session)
 		}
 	}()
-	wsClient := websocket.ClientImpl.New(session)
+	wsClient := websocket.ClientImpl.New(session, l.dispatcher)
+	if setter, ok := wsClient.(websocket.SessionStoreSetter); ok && l.store != nil {
+		setter.SetSessionStore(l.store)
+	}
 	if err := wsClient.Connect(); err != nil {
 		log.Error(err)
-		l.sessionChan <- session // 连接失败，丢回去队列排队重连
+		l.requeue(ctx, session) // 连接失败，丢回去队列排队重连
+		return
+	}
+	if !l.conns.Track(session.Shards.ShardID, wsClient) {
 		return
 	}
+	var listenErr error
+	defer func() {
+		// 主动关闭时返回 ErrConnClosed 是正常的，其他错误需要报告给调用方
+		if ctx.Err() != nil && listenErr != errs.ErrConnClosed {
+			l.conns.Untrack(session.Shards.ShardID, listenErr)
+			return
+		}
+		l.conns.Untrack(session.Shards.ShardID, nil)
+	}()
 	var err error
 	// 如果 session id 不为空，则执行的是 resume 操作，如果为空，则执行的是 identify 操作
 	if session.ID != "" {
@@ -87,7 +183,12 @@ func (l *ChanManager) newConnect(session dto.Session) {
 		log.Errorf("[ws/session] Identify/Resume err %+v", err)
 		return
 	}
-	if err := wsClient.Listening(); err != nil {
+	if listenErr = wsClient.Listening(); listenErr != nil {
+		err := listenErr
+		if ctx.Err() != nil {
+			log.Infof("[ws/session] %s stopped because manager is shutting down, err %+v", &session, err)
+			return
+		}
 		log.Errorf("[ws/session] Listening err %+v", err)
 		currentSession := wsClient.Session()
 		// 对于不能够进行重连的session，需要清空 session id 与 seq
@@ -102,7 +203,7 @@ func (l *ChanManager) newConnect(session dto.Session) {
 			panic(msg) // 当机器人被下架，或者封禁，将不能再连接，所以 panic
 		}
 		// 将 session 放到 session chan 中，用于启动新的连接，当前连接退出
-		l.sessionChan <- *currentSession
+		l.requeue(ctx, *currentSession)
 		return
 	}
 }
@@ -0,0 +1,95 @@
+package local
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tencent-connect/botgo/dto"
+	"github.com/tencent-connect/botgo/event"
+	"github.com/tencent-connect/botgo/sessions/store"
+	"github.com/tencent-connect/botgo/token"
+	"github.com/tencent-connect/botgo/websocket/client"
+	"github.com/tencent-connect/botgo/websocket/gatewaytest"
+)
+
+var testLimit = dto.SessionStartLimit{Total: 10, Remaining: 10, MaxConcurrency: 10}
+
+// run 启动 manager 直到 cond 满足，然后关闭 manager
+func run(t *testing.T, server *gatewaytest.Server, s *store.MemoryStore, cond func(gatewaytest.Stats) bool) {
+	t.Helper()
+	m := New(WithDispatcher(event.NewDispatcher()), WithSessionStore(s), WithShutdownTimeout(time.Second))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	intent := dto.IntentGuildAtMessage
+	go func() { done <- m.Start(ctx, server.WebsocketAP(), token.BotToken(1, "token"), &intent) }()
+
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer waitCancel()
+	assert.Nil(t, server.Wait(waitCtx, cond))
+	cancel()
+	assert.Nil(t, <-done)
+}
+
+func TestChanManager_SessionStore(t *testing.T) {
+	client.Setup()
+	s := store.NewMemoryStore()
+	server := gatewaytest.NewServer(gatewaytest.WithSessionStartLimit(testLimit))
+	defer server.Close()
+	session := dto.Session{Token: *token.BotToken(1, "token"), Shards: dto.ShardConfig{ShardCount: 1}}
+
+	// 首次启动鉴权，并保存 session
+	run(t, server, s, func(st gatewaytest.Stats) bool { return st.Ready == 1 })
+	state, err := s.Load(context.Background(), session)
+	assert.Nil(t, err)
+	assert.NotNil(t, state)
+
+	// 重启后使用保存的 session resume
+	run(t, server, s, func(st gatewaytest.Stats) bool { return st.Resumes == 1 && st.Ready == 1 })
+	assert.Equal(t, 1, server.Stats().Identifies)
+
+	// session 在服务端已经失效，resume 失败后重新鉴权，并保存新的 session
+	other := gatewaytest.NewServer(gatewaytest.WithSessionStartLimit(testLimit))
+	defer other.Close()
+	run(t, other, s, func(st gatewaytest.Stats) bool { return st.Identifies == 1 && st.Ready == 1 })
+	assert.Equal(t, 1, other.Stats().Resumes)
+	restored, err := s.Load(context.Background(), session)
+	assert.Nil(t, err)
+	assert.NotEqual(t, state.ID, restored.ID)
+}
+
+func TestChanManager_StartLimit(t *testing.T) {
+	client.Setup()
+	// 两个 shard 只剩一次启动额度，第二个 shard 在额度重置后启动
+	server := gatewaytest.NewServer(
+		gatewaytest.WithShards(2),
+		gatewaytest.WithSessionStartLimit(dto.SessionStartLimit{
+			Total: 10, Remaining: 1, ResetAfter: 1500, MaxConcurrency: 10,
+		}),
+	)
+	defer server.Close()
+
+	limited := make(chan uint32, 2)
+	m := New(
+		WithDispatcher(event.NewDispatcher()),
+		WithShutdownTimeout(time.Second),
+		WithStartLimitHandler(func(shard dto.ShardConfig, remaining uint32, resetAfter time.Duration) {
+			assert.Equal(t, uint32(0), remaining)
+			limited <- shard.ShardID
+		}),
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	intent := dto.IntentGuildAtMessage
+	go func() { done <- m.Start(ctx, server.WebsocketAP(), token.BotToken(1, "token"), &intent) }()
+
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer waitCancel()
+	assert.Nil(t, server.Wait(waitCtx, func(st gatewaytest.Stats) bool { return st.Ready == 1 }))
+	assert.Equal(t, uint32(1), <-limited)
+	assert.Nil(t, server.Wait(waitCtx, func(st gatewaytest.Stats) bool { return st.Ready == 2 }))
+	assert.Equal(t, 2, server.Stats().Identifies)
+	cancel()
+	assert.Nil(t, <-done)
+}
@@ -0,0 +1,41 @@
+package local
+
+import (
+	"time"
+
+	"github.com/tencent-connect/botgo/event"
+	"github.com/tencent-connect/botgo/sessions/manager"
+	"github.com/tencent-connect/botgo/websocket"
+)
+
+// Option 本地 session manager 的可选配置
+type Option func(manager *ChanManager)
+
+// WithDispatcher 指定事件分发器，未指定时使用 event.DefaultDispatcher
+func WithDispatcher(dispatcher *event.Dispatcher) Option {
+	return func(m *ChanManager) {
+		m.dispatcher = dispatcher
+	}
+}
+
+// WithShutdownTimeout 设置关闭时等待连接处理完已收到事件的超时时间
+func WithShutdownTimeout(timeout time.Duration) Option {
+	return func(m *ChanManager) {
+		m.shutdownTimeout = timeout
+	}
+}
+
+// WithSessionStore 设置持久化 session 状态的 store，实现见 sessions/store
+// 连接会在收到事件时保存 session id 与 seq，进程重启后优先 resume，避免丢失事件与消耗连接数限制
+func WithSessionStore(store websocket.SessionStore) Option {
+	return func(m *ChanManager) {
+		m.store = store
+	}
+}
+
+// WithStartLimitHandler 设置启动额度不足时的回调，shard 会在额度重置后启动
+func WithStartLimitHandler(handler manager.StartLimitHandler) Option {
+	return func(m *ChanManager) {
+		m.onStartLimit = handler
+	}
+}
@@ -0,0 +1,87 @@
+package manager
+
+import (
+	"sync"
+	"time"
+
+	"github.com/tencent-connect/botgo/dto"
+	"github.com/tencent-connect/botgo/log"
+)
+
+// DefaultResetWindow 启动额度重置后，到下一次重置的默认间隔
+const DefaultResetWindow = 24 * time.Hour
+
+// StartLimitHandler 启动额度不足，shard 需要等待额度重置后再启动时的回调
+// remaining 为当前剩余的额度，resetAfter 为距离额度重置的时间
+type StartLimitHandler func(shard dto.ShardConfig, remaining uint32, resetAfter time.Duration)
+
+// StartBudget 连接启动额度，对应 `/gateway/bot` 返回的 session_start_limit
+// 鉴权（identify）需要消耗额度，resume 不消耗额度
+// 额度用完后，在 ResetAfter 之后恢复为 Total，之后每隔 DefaultResetWindow 恢复一次
+type StartBudget struct {
+	lock      sync.Mutex
+	total     uint32
+	remaining uint32
+	resetAt   time.Time
+	unlimited bool // total 为 0 时认为没有返回限制信息，不做限制
+	now       func() time.Time
+}
+
+// NewStartBudget 根据 session_start_limit 创建启动额度，ResetAfter 的单位为毫秒
+func NewStartBudget(limit dto.SessionStartLimit) *StartBudget {
+	return newStartBudget(limit, time.Now)
+}
+
+func newStartBudget(limit dto.SessionStartLimit, now func() time.Time) *StartBudget {
+	return &StartBudget{
+		total:     limit.Total,
+		remaining: limit.Remaining,
+		resetAt:   now().Add(time.Duration(limit.ResetAfter) * time.Millisecond),
+		unlimited: limit.Total == 0,
+		now:       now,
+	}
+}
+
+// Acquire 尝试消耗一次启动额度，额度不足时返回 false 与距离额度重置的等待时间
+func (b *StartBudget) Acquire() (time.Duration, bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.unlimited {
+		return 0, true
+	}
+	now := b.now()
+	if !now.Before(b.resetAt) {
+		b.remaining = b.total
+		b.resetAt = now.Add(DefaultResetWindow)
+	}
+	if b.remaining == 0 {
+		return b.resetAt.Sub(now), false
+	}
+	b.remaining--
+	return 0, true
+}
+
+// AcquireFor 为 session 的启动消耗额度，带有 session id 的 session 会执行 resume，不需要消耗额度
+// 额度不足时调用 handler，并返回 false 与距离额度重置的等待时间
+func (b *StartBudget) AcquireFor(session dto.Session, handler StartLimitHandler) (time.Duration, bool) {
+	if session.ID != "" {
+		return 0, true
+	}
+	wait, ok := b.Acquire()
+	if ok {
+		log.Infof("[ws/session] %s acquired start budget, remaining %d", &session, b.Remaining())
+		return 0, true
+	}
+	log.Warnf("[ws/session] %s start budget exhausted, will start after %s", &session, wait)
+	if handler != nil {
+		handler(session.Shards, b.Remaining(), wait)
+	}
+	return wait, false
+}
+
+// Remaining 返回剩余的启动额度
+func (b *StartBudget) Remaining() uint32 {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.remaining
+}
@@ -0,0 +1,55 @@
+package manager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tencent-connect/botgo/dto"
+)
+
+func TestStartBudget_Acquire(t *testing.T) {
+	now := time.Unix(0, 0)
+	clock := func() time.Time { return now }
+	b := newStartBudget(dto.SessionStartLimit{Total: 2, Remaining: 1, ResetAfter: 1000}, clock)
+
+	_, ok := b.Acquire()
+	assert.True(t, ok)
+	wait, ok := b.Acquire()
+	assert.False(t, ok)
+	assert.Equal(t, time.Second, wait)
+
+	// 重置后恢复为 total，下一次重置在 DefaultResetWindow 之后
+	now = now.Add(time.Second)
+	_, ok = b.Acquire()
+	assert.True(t, ok)
+	_, ok = b.Acquire()
+	assert.True(t, ok)
+	wait, ok = b.Acquire()
+	assert.False(t, ok)
+	assert.Equal(t, DefaultResetWindow, wait)
+
+	// 没有限制信息时不限制
+	unlimited := NewStartBudget(dto.SessionStartLimit{})
+	_, ok = unlimited.Acquire()
+	assert.True(t, ok)
+}
+
+func TestStartBudget_AcquireFor(t *testing.T) {
+	b := NewStartBudget(dto.SessionStartLimit{Total: 1, Remaining: 0, ResetAfter: 60000})
+	called := 0
+	handler := func(shard dto.ShardConfig, remaining uint32, resetAfter time.Duration) {
+		called++
+		assert.Equal(t, uint32(1), shard.ShardID)
+		assert.Equal(t, uint32(0), remaining)
+		assert.True(t, resetAfter > 0)
+	}
+	// resume 不需要消耗额度
+	_, ok := b.AcquireFor(dto.Session{ID: "session"}, handler)
+	assert.True(t, ok)
+	assert.Equal(t, 0, called)
+
+	_, ok = b.AcquireFor(dto.Session{Shards: dto.ShardConfig{ShardID: 1, ShardCount: 2}}, handler)
+	assert.False(t, ok)
+	assert.Equal(t, 1, called)
+}
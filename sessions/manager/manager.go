@@ -53,6 +53,8 @@ func CanNotIdentify(err error) bool {
 }
 
 // CheckSessionLimit 检查链接数是否达到限制，如果达到限制需要等待重置
+//
+// Deprecated: session manager 使用 StartBudget 在额度不足时等待重置，不再直接返回错误
 func CheckSessionLimit(apInfo *dto.WebsocketAP) error {
 	if apInfo.Shards > apInfo.SessionStartLimit.Remaining {
 		return errs.ErrSessionLimit
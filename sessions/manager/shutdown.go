@@ -0,0 +1,147 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tencent-connect/botgo/errs"
+	"github.com/tencent-connect/botgo/websocket"
+)
+
+// DefaultShutdownTimeout 关闭 session manager 时，等待所有连接处理完已收到事件的默认超时时间
+const DefaultShutdownTimeout = 10 * time.Second
+
+// ShardErrors 关闭 session manager 时各个 shard 发生的错误，key 为 shardID
+type ShardErrors map[uint32]error
+
+// Error 输出错误信息
+func (e ShardErrors) Error() string {
+	shardIDs := make([]int, 0, len(e))
+	for shardID := range e {
+		shardIDs = append(shardIDs, int(shardID))
+	}
+	sort.Ints(shardIDs)
+	msgs := make([]string, 0, len(shardIDs))
+	for _, shardID := range shardIDs {
+		msgs = append(msgs, fmt.Sprintf("shard %d: %v", shardID, e[uint32(shardID)]))
+	}
+	return fmt.Sprintf("shutdown with errors, %s", strings.Join(msgs, "; "))
+}
+
+// Sleep 等待指定的时间，如果 ctx 先结束则提前返回 false
+func Sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// Connections 记录 session manager 中正在运行的连接，用于关闭时统一关闭连接，并收集各个 shard 的错误
+type Connections struct {
+	lock    sync.Mutex
+	closed  bool
+	clients map[uint32]websocket.WebSocket // key 为 shardID
+	errs    ShardErrors
+	wg      sync.WaitGroup
+}
+
+// NewConnections 创建连接记录
+func NewConnections() *Connections {
+	return &Connections{
+		clients: map[uint32]websocket.WebSocket{},
+		errs:    ShardErrors{},
+	}
+}
+
+// Add 在启动连接的 goroutine 之前调用，Shutdown 会等待所有的连接 goroutine 调用 Done
+func (c *Connections) Add() {
+	c.wg.Add(1)
+}
+
+// Done 连接 goroutine 退出时调用
+func (c *Connections) Done() {
+	c.wg.Done()
+}
+
+// Track 记录正在运行的连接，如果已经在关闭中，会直接关闭连接并返回 false
+func (c *Connections) Track(shardID uint32, client websocket.WebSocket) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.closed {
+		client.Close()
+		return false
+	}
+	c.clients[shardID] = client
+	return true
+}
+
+// Untrack 连接退出后移除记录，关闭过程中连接返回的错误通过 err 传入
+func (c *Connections) Untrack(shardID uint32, err error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.clients, shardID)
+	if err != nil {
+		c.errs[shardID] = err
+	}
+}
+
+// SetError 记录 shard 在关闭过程中发生的错误
+func (c *Connections) SetError(shardID uint32, err error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.errs[shardID] = err
+}
+
+// Shutdown 关闭所有连接，并等待连接处理完已经收到的事件，超过 timeout 仍未退出的 shard 记为超时错误
+func (c *Connections) Shutdown(timeout time.Duration) error {
+	c.lock.Lock()
+	c.closed = true
+	clients := make([]websocket.WebSocket, 0, len(c.clients))
+	for _, client := range c.clients {
+		clients = append(clients, client)
+	}
+	c.lock.Unlock()
+	for _, client := range clients {
+		client.Close()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(done)
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		c.lock.Lock()
+		for shardID := range c.clients {
+			c.errs[shardID] = errs.ErrShutdownTimeout
+		}
+		c.lock.Unlock()
+	}
+	return c.Err()
+}
+
+// Err 返回关闭过程中各个 shard 的错误，没有错误时返回 nil
+func (c *Connections) Err() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if len(c.errs) == 0 {
+		return nil
+	}
+	shardErrs := make(ShardErrors, len(c.errs))
+	for shardID, err := range c.errs {
+		shardErrs[shardID] = err
+	}
+	return shardErrs
+}
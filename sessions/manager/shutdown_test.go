@@ -0,0 +1,64 @@
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tencent-connect/botgo/dto"
+	"github.com/tencent-connect/botgo/errs"
+	"github.com/tencent-connect/botgo/event"
+	"github.com/tencent-connect/botgo/websocket"
+)
+
+type fakeClient struct {
+	closed chan struct{}
+}
+
+func (f *fakeClient) New(dto.Session, *event.Dispatcher) websocket.WebSocket { return f }
+func (f *fakeClient) Connect() error                                         { return nil }
+func (f *fakeClient) Identify() error                                        { return nil }
+func (f *fakeClient) Session() *dto.Session                                  { return &dto.Session{} }
+func (f *fakeClient) Resume() error                                          { return nil }
+func (f *fakeClient) Write(*dto.WSPayload) error                             { return nil }
+func (f *fakeClient) Close()                                                 { close(f.closed) }
+func (f *fakeClient) Listening() error {
+	<-f.closed
+	return errs.ErrConnClosed
+}
+
+func TestConnections_Shutdown(t *testing.T) {
+	t.Run("close and wait", func(t *testing.T) {
+		conns := NewConnections()
+		for i := uint32(0); i < 2; i++ {
+			conns.Add()
+			client := &fakeClient{closed: make(chan struct{})}
+			assert.True(t, conns.Track(i, client))
+			go func(shardID uint32) {
+				defer conns.Done()
+				_ = client.Listening()
+				conns.Untrack(shardID, nil)
+			}(i)
+		}
+		assert.Nil(t, conns.Shutdown(time.Second))
+		// 关闭后新的连接不再被记录
+		assert.False(t, conns.Track(2, &fakeClient{closed: make(chan struct{})}))
+	})
+	t.Run("timeout", func(t *testing.T) {
+		conns := NewConnections()
+		conns.Add()
+		conns.Track(1, &fakeClient{closed: make(chan struct{})})
+		err := conns.Shutdown(10 * time.Millisecond)
+		shardErrs, ok := err.(ShardErrors)
+		assert.True(t, ok)
+		assert.Equal(t, errs.ErrShutdownTimeout, shardErrs[1])
+	})
+}
+
+func TestSleep(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	assert.True(t, Sleep(ctx, time.Millisecond))
+	cancel()
+	assert.False(t, Sleep(ctx, time.Hour))
+}
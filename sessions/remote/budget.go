@@ -0,0 +1,81 @@
+package remote
+
+import (
+	"context"
+	"time"
+
+	"github.com/tencent-connect/botgo/dto"
+	"github.com/tencent-connect/botgo/log"
+	"github.com/tencent-connect/botgo/sessions/manager"
+	"github.com/tencent-connect/botgo/sessions/remote/lock"
+)
+
+const (
+	// 共享启动额度的key后缀，实际上的key为 `fmt.Sprintf("%s_%s", r.clusterKey, startQuotaSuffix)`
+	startQuotaSuffix = "startQuota"
+	// 访问共享启动额度的超时时间
+	quotaTimeout = 3 * time.Second
+	// 访问共享启动额度失败后，session 重新放回队列前的等待时间
+	quotaRetryInterval = 5 * time.Second
+)
+
+// startBudget 启动额度，manager.StartBudget 只在本实例内生效，sharedBudget 由所有实例共享
+type startBudget interface {
+	AcquireFor(session dto.Session, handler manager.StartLimitHandler) (time.Duration, bool)
+}
+
+// newStartBudget 锁后端实现了 lock.Quota 时，使用所有实例共享的启动额度，否则每个实例按照自己拉取到的额度控制
+func newStartBudget(locker lock.Backend, key string, limit dto.SessionStartLimit) startBudget {
+	quota, ok := locker.(lock.Quota)
+	if !ok || limit.Total == 0 {
+		if !ok {
+			log.Warnf("[ws/session/remote] lock backend %T does not implement lock.Quota, "+
+				"start budget is not shared between instances", locker)
+		}
+		return manager.NewStartBudget(limit)
+	}
+	return &sharedBudget{
+		quota:   quota,
+		key:     key,
+		limit:   limit,
+		resetAt: time.Now().Add(time.Duration(limit.ResetAfter) * time.Millisecond),
+	}
+}
+
+// sharedBudget 保存在锁后端中的启动额度，同一个集群的所有实例共同消耗
+// 额度第一次使用时按照 session_start_limit 初始化，在 ResetAfter 后过期，过期后按照 Total 重新初始化
+type sharedBudget struct {
+	quota   lock.Quota
+	key     string
+	limit   dto.SessionStartLimit
+	resetAt time.Time // 本实例拉取到的额度的重置时间
+}
+
+// AcquireFor 为 session 的启动消耗共享额度，带有 session id 的 session 会执行 resume，不需要消耗额度
+// 额度不足时调用 handler，并返回 false 与距离额度重置的等待时间，访问额度失败时稍后重试
+func (b *sharedBudget) AcquireFor(session dto.Session, handler manager.StartLimitHandler) (time.Duration, bool) {
+	if session.ID != "" {
+		return 0, true
+	}
+	initial, expire := int64(b.limit.Remaining), time.Until(b.resetAt)
+	if expire <= 0 {
+		initial, expire = int64(b.limit.Total), manager.DefaultResetWindow
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), quotaTimeout)
+	defer cancel()
+	wait, ok, err := b.quota.Take(ctx, b.key, initial, expire)
+	if err != nil {
+		log.Errorf("[ws/session/remote] %s take start budget failed, will retry after %s, err: %v",
+			&session, quotaRetryInterval, err)
+		return quotaRetryInterval, false
+	}
+	if ok {
+		log.Infof("[ws/session/remote] %s acquired shared start budget", &session)
+		return 0, true
+	}
+	log.Warnf("[ws/session/remote] %s shared start budget exhausted, will start after %s", &session, wait)
+	if handler != nil {
+		handler(session.Shards, 0, wait)
+	}
+	return wait, false
+}
@@ -0,0 +1,47 @@
+package remote
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tencent-connect/botgo/dto"
+	"github.com/tencent-connect/botgo/sessions/manager"
+	"github.com/tencent-connect/botgo/sessions/remote/lock"
+)
+
+func TestSharedBudget(t *testing.T) {
+	locker := lock.NewMemoryBackend()
+	limit := dto.SessionStartLimit{Total: 10, Remaining: 2, ResetAfter: 60000}
+	// 两个实例拉取到相同的额度，总共只能鉴权 Remaining 次
+	b1 := newStartBudget(locker, "cluster_startQuota", limit)
+	b2 := newStartBudget(locker, "cluster_startQuota", limit)
+	session := dto.Session{Shards: dto.ShardConfig{ShardID: 1, ShardCount: 2}}
+
+	_, ok := b1.AcquireFor(session, nil)
+	assert.True(t, ok)
+	_, ok = b2.AcquireFor(session, nil)
+	assert.True(t, ok)
+
+	var limited bool
+	wait, ok := b1.AcquireFor(session, func(shard dto.ShardConfig, remaining uint32, resetAfter time.Duration) {
+		limited = true
+		assert.Equal(t, uint32(0), remaining)
+	})
+	assert.False(t, ok)
+	assert.True(t, limited)
+	assert.True(t, wait > 0 && wait <= time.Minute)
+
+	// resume 不消耗额度
+	session.ID = "session"
+	_, ok = b2.AcquireFor(session, nil)
+	assert.True(t, ok)
+}
+
+func TestNewStartBudget_LocalFallback(t *testing.T) {
+	limit := dto.SessionStartLimit{Total: 10, Remaining: 1, ResetAfter: 60000}
+	_, ok := newStartBudget(nil, "key", limit).(*manager.StartBudget)
+	assert.True(t, ok)
+	_, ok = newStartBudget(lock.NewMemoryBackend(), "key", dto.SessionStartLimit{}).(*manager.StartBudget)
+	assert.True(t, ok)
+}
@@ -1,4 +1,7 @@
-// Package lock 一个基于 redis 的分布式锁实现。
+// Package lock 分布式锁实现，加锁、续期与释放由可替换的 Backend 完成。
+//
+// 内置了基于 redis 的 RedisBackend，基于 PostgreSQL advisory lock 的 PostgresBackend，
+// 以及用于测试与单机场景的 MemoryBackend。
 package lock
 
 import (
@@ -10,30 +13,53 @@ import (
 	"github.com/tencent-connect/botgo/log"
 )
 
-// ErrorNotOk redis 写失败
+// ErrorNotOk 锁已经被占用
 var ErrorNotOk = errors.New("redis write not ok")
 
-// Lock 一个基于redis的锁实现
+// Quota 多个实例共享的额度，Backend 可以选择实现，remote.Manager 使用它在实例之间共享 session 启动额度
+type Quota interface {
+	// Take 从 key 对应的额度中消耗一次，key 不存在或者已经过期时，先初始化为 initial，并在 expire 后过期
+	// 额度不足时返回 false 与距离额度过期的时间
+	Take(ctx context.Context, key string, initial int64, expire time.Duration) (time.Duration, bool, error)
+}
+
+// Backend 分布式锁的存储后端，同一个 key 同时只能被一个 value 持有
+type Backend interface {
+	// Acquire 尝试加锁，锁已经被其他 value 持有时返回 false
+	Acquire(ctx context.Context, key, value string, expire time.Duration) (bool, error)
+	// Renew 续期锁，锁不属于 value 时不做任何操作
+	Renew(ctx context.Context, key, value string, expire time.Duration) error
+	// Release 释放锁，锁不属于 value 时不做任何操作
+	Release(ctx context.Context, key, value string) error
+}
+
+// Lock 一个分布式锁，负责维护续期任务
 type Lock struct {
 	lockKey       string
 	lockValue     string
-	client        *redis.Client
+	backend       Backend
 	renewTicker   *time.Ticker // 用于续期的ticker，默认为超时时间的 1/3
 	stopRenewChan chan bool    // 用于停止 renew
 }
 
-// New 创建一个锁
-func New(key, value string, client *redis.Client) *Lock {
+// New 创建一个基于 redis 的锁
+func New(key, value string, client redis.UniversalClient) *Lock {
+	return NewWithBackend(key, value, NewRedisBackend(client))
+}
+
+// NewWithBackend 创建一个使用指定后端的锁
+func NewWithBackend(key, value string, backend Backend) *Lock {
 	return &Lock{
-		lockKey:   key,
-		lockValue: value,
-		client:    client,
+		lockKey:       key,
+		lockValue:     value,
+		backend:       backend,
+		stopRenewChan: make(chan bool, 1),
 	}
 }
 
 // Lock 加锁
 func (l *Lock) Lock(ctx context.Context, expire time.Duration) error {
-	success, err := l.client.SetNX(ctx, l.lockKey, l.lockValue, expire).Result()
+	success, err := l.backend.Acquire(ctx, l.lockKey, l.lockValue, expire)
 	if err != nil {
 		return err
 	}
@@ -48,7 +74,6 @@ func (l *Lock) StartRenew(ctx context.Context, expire time.Duration) {
 	if expire == 0 {
 		return
 	}
-	l.stopRenewChan = make(chan bool)
 	l.renewTicker = time.NewTicker(expire / 3)
 	defer l.renewTicker.Stop()
 	for {
@@ -69,36 +94,26 @@ func (l *Lock) StartRenew(ctx context.Context, expire time.Duration) {
 	}
 }
 
-// StopRenew 停掉续期
+// StopRenew 停掉续期，续期任务已经因为 ctx 结束退出时也不会阻塞
 func (l *Lock) StopRenew() {
-	if l.stopRenewChan == nil {
-		return
+	select {
+	case l.stopRenewChan <- true:
+	default:
 	}
-	l.stopRenewChan <- true
 }
 
 // Renew 续期锁
 func (l *Lock) Renew(ctx context.Context, expire time.Duration) error {
-	script := `
-	if redis.call("get", KEYS[1]) == ARGV[1] then
-		return redis.call("expire", KEYS[1], ARGV[2])
-	else
-		return 0
-	end
-	`
-	renew := redis.NewScript(script)
-	return renew.Run(ctx, l.client, []string{l.lockKey}, l.lockValue, expire.Seconds()).Err()
+	return l.backend.Renew(ctx, l.lockKey, l.lockValue, expire)
 }
 
 // Release 释放锁
 func (l *Lock) Release(ctx context.Context) error {
-	script := `
-	if redis.call("get", KEYS[1]) == ARGV[1] then
-		return redis.call("del", KEYS[1])
-	else
-		return 0
-	end
-	`
-	release := redis.NewScript(script)
-	return release.Run(ctx, l.client, []string{l.lockKey}, l.lockValue).Err()
+	return l.backend.Release(ctx, l.lockKey, l.lockValue)
 }
+
+var (
+	_ Quota = (*RedisBackend)(nil)
+	_ Quota = (*PostgresBackend)(nil)
+	_ Quota = (*MemoryBackend)(nil)
+)
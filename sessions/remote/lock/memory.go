@@ -0,0 +1,79 @@
+package lock
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryBackend 基于内存的锁后端，只在单个进程内生效，用于测试或者单机部署
+type MemoryBackend struct {
+	lock   sync.Mutex
+	locks  map[string]memoryLock
+	quotas map[string]memoryQuota
+}
+
+type memoryQuota struct {
+	remaining int64
+	expireAt  time.Time
+}
+
+type memoryLock struct {
+	value    string
+	expireAt time.Time
+}
+
+// NewMemoryBackend 创建内存锁后端
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{locks: map[string]memoryLock{}, quotas: map[string]memoryQuota{}}
+}
+
+// Acquire 加锁，已经过期的锁可以被重新获取
+func (b *MemoryBackend) Acquire(_ context.Context, key, value string, expire time.Duration) (bool, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if l, ok := b.locks[key]; ok && time.Now().Before(l.expireAt) {
+		return false, nil
+	}
+	b.locks[key] = memoryLock{value: value, expireAt: time.Now().Add(expire)}
+	return true, nil
+}
+
+// Renew 续期锁
+func (b *MemoryBackend) Renew(_ context.Context, key, value string, expire time.Duration) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if l, ok := b.locks[key]; ok && l.value == value && time.Now().Before(l.expireAt) {
+		b.locks[key] = memoryLock{value: value, expireAt: time.Now().Add(expire)}
+	}
+	return nil
+}
+
+// Release 释放锁
+func (b *MemoryBackend) Release(_ context.Context, key, value string) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if l, ok := b.locks[key]; ok && l.value == value {
+		delete(b.locks, key)
+	}
+	return nil
+}
+
+// Take 消耗一次额度
+func (b *MemoryBackend) Take(_ context.Context,
+	key string, initial int64, expire time.Duration) (time.Duration, bool, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	now := time.Now()
+	q, ok := b.quotas[key]
+	if !ok || !now.Before(q.expireAt) {
+		q = memoryQuota{remaining: initial, expireAt: now.Add(expire)}
+	}
+	if q.remaining <= 0 {
+		b.quotas[key] = q
+		return q.expireAt.Sub(now), false, nil
+	}
+	q.remaining--
+	b.quotas[key] = q
+	return 0, true, nil
+}
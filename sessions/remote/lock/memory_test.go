@@ -0,0 +1,58 @@
+package lock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryBackend(t *testing.T) {
+	ctx := context.Background()
+	backend := NewMemoryBackend()
+	expire := 50 * time.Millisecond
+	l1 := NewWithBackend("key", "v1", backend)
+	l2 := NewWithBackend("key", "v2", backend)
+
+	assert.Nil(t, l1.Lock(ctx, expire))
+	assert.Equal(t, ErrorNotOk, l2.Lock(ctx, expire))
+	// 不属于自己的锁不能释放
+	assert.Nil(t, l2.Release(ctx))
+	assert.Equal(t, ErrorNotOk, l2.Lock(ctx, expire))
+
+	// 续期后不会过期
+	renewCtx, cancel := context.WithCancel(ctx)
+	go l1.StartRenew(renewCtx, expire)
+	time.Sleep(2 * expire)
+	assert.Equal(t, ErrorNotOk, l2.Lock(ctx, expire))
+	cancel()
+
+	// 停止续期后过期，可以被其他人获取
+	time.Sleep(2 * expire)
+	assert.Nil(t, l2.Lock(ctx, expire))
+	assert.Nil(t, l2.Release(ctx))
+	assert.Nil(t, l1.Lock(ctx, expire))
+}
+
+func TestMemoryBackend_Take(t *testing.T) {
+	ctx := context.Background()
+	backend := NewMemoryBackend()
+	expire := 50 * time.Millisecond
+
+	for i := 0; i < 2; i++ {
+		_, ok, err := backend.Take(ctx, "quota", 2, expire)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+	}
+	// 额度用完后返回距离过期的时间，initial 只在初始化时生效
+	wait, ok, err := backend.Take(ctx, "quota", 10, expire)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+	assert.True(t, wait > 0 && wait <= expire)
+
+	// 过期后重新初始化
+	time.Sleep(wait + 10*time.Millisecond)
+	_, ok, _ = backend.Take(ctx, "quota", 1, expire)
+	assert.True(t, ok)
+}
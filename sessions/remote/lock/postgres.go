@@ -0,0 +1,156 @@
+package lock
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// DefaultPostgresQuotaTable PostgresBackend 保存共享额度使用的表名
+const DefaultPostgresQuotaTable = "botgo_session_quota"
+
+// PostgresBackend 基于 PostgreSQL advisory lock 的锁后端
+//
+// advisory lock 绑定在数据库连接上，所以每个持有的锁会独占一个连接，释放锁时归还连接。
+// 进程退出或者连接断开时，数据库会自动释放锁，所以 expire 不生效，续期时只检查连接是否可用。
+// 共享额度保存在 DefaultPostgresQuotaTable 表中，使用前需要通过 CreateTable 创建。
+type PostgresBackend struct {
+	db         *sql.DB
+	quotaTable string
+	lock       sync.Mutex
+	conns      map[string]*heldLock // key -> 持有锁的连接
+}
+
+type heldLock struct {
+	value string
+	conn  *sql.Conn
+}
+
+// NewPostgresBackend 创建 PostgreSQL 锁后端，db 需要使用 PostgreSQL 的驱动打开
+func NewPostgresBackend(db *sql.DB) *PostgresBackend {
+	return &PostgresBackend{
+		db:         db,
+		quotaTable: DefaultPostgresQuotaTable,
+		conns:      map[string]*heldLock{},
+	}
+}
+
+// CreateTable 创建保存共享额度的表，表已经存在时不做任何操作
+func (b *PostgresBackend) CreateTable(ctx context.Context) error {
+	_, err := b.db.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		quota_key TEXT PRIMARY KEY,
+		remaining BIGINT NOT NULL,
+		expire_at TIMESTAMPTZ NOT NULL
+	)`, b.quotaTable))
+	return err
+}
+
+// Take 消耗一次额度，额度不存在或者已经过期时先重新初始化
+func (b *PostgresBackend) Take(ctx context.Context,
+	key string, initial int64, expire time.Duration) (time.Duration, bool, error) {
+	now := time.Now()
+	_, err := b.db.ExecContext(ctx, fmt.Sprintf(`INSERT INTO %[1]s (quota_key, remaining, expire_at)
+		VALUES ($1, $2, $3) ON CONFLICT (quota_key) DO UPDATE
+		SET remaining = EXCLUDED.remaining, expire_at = EXCLUDED.expire_at WHERE %[1]s.expire_at <= $4`,
+		b.quotaTable), key, initial, now.Add(expire), now)
+	if err != nil {
+		return 0, false, err
+	}
+	var remaining int64
+	err = b.db.QueryRowContext(ctx, fmt.Sprintf(
+		"UPDATE %s SET remaining = remaining - 1 WHERE quota_key = $1 AND remaining > 0 RETURNING remaining",
+		b.quotaTable), key).Scan(&remaining)
+	if err == nil {
+		return 0, true, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, false, err
+	}
+	var expireAt time.Time
+	err = b.db.QueryRowContext(ctx, fmt.Sprintf("SELECT expire_at FROM %s WHERE quota_key = $1", b.quotaTable),
+		key).Scan(&expireAt)
+	if err != nil {
+		return 0, false, err
+	}
+	return expireAt.Sub(now), false, nil
+}
+
+// Acquire 使用 pg_try_advisory_lock 加锁
+func (b *PostgresBackend) Acquire(ctx context.Context, key, value string, _ time.Duration) (bool, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if _, ok := b.conns[key]; ok {
+		return false, nil
+	}
+	conn, err := b.db.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+	var locked bool
+	if err = conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", advisoryKey(key)).Scan(&locked); err != nil {
+		_ = conn.Close()
+		return false, err
+	}
+	if !locked {
+		_ = conn.Close()
+		return false, nil
+	}
+	b.conns[key] = &heldLock{value: value, conn: conn}
+	return true, nil
+}
+
+// Renew 检查持有锁的连接是否可用，连接断开时锁已经被数据库释放，返回错误
+func (b *PostgresBackend) Renew(ctx context.Context, key, value string, _ time.Duration) error {
+	held := b.held(key, value)
+	if held == nil {
+		return nil
+	}
+	if err := held.conn.PingContext(ctx); err != nil {
+		// 连接不可用时锁已经丢失，清理连接，之后可以重新加锁
+		b.forget(key, held)
+		_ = held.conn.Close()
+		return err
+	}
+	return nil
+}
+
+// Release 使用 pg_advisory_unlock 释放锁，并归还连接
+func (b *PostgresBackend) Release(ctx context.Context, key, value string) error {
+	held := b.held(key, value)
+	if held == nil {
+		return nil
+	}
+	b.forget(key, held)
+	defer held.conn.Close()
+	_, err := held.conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", advisoryKey(key))
+	return err
+}
+
+func (b *PostgresBackend) held(key, value string) *heldLock {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	held, ok := b.conns[key]
+	if !ok || held.value != value {
+		return nil
+	}
+	return held
+}
+
+// forget 删除持有锁的记录，避免误删之后重新获取的锁
+func (b *PostgresBackend) forget(key string, held *heldLock) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.conns[key] == held {
+		delete(b.conns, key)
+	}
+}
+
+// advisoryKey 将锁的 key 转换为 advisory lock 使用的 bigint
+func advisoryKey(key string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	return int64(h.Sum64())
+}
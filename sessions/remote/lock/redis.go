@@ -0,0 +1,80 @@
+package lock
+
+import (
+	"context"
+	"time"
+
+	redis "github.com/go-redis/redis/v8"
+)
+
+var (
+	renewScript = redis.NewScript(`
+	if redis.call("get", KEYS[1]) == ARGV[1] then
+		return redis.call("expire", KEYS[1], ARGV[2])
+	else
+		return 0
+	end
+	`)
+	releaseScript = redis.NewScript(`
+	if redis.call("get", KEYS[1]) == ARGV[1] then
+		return redis.call("del", KEYS[1])
+	else
+		return 0
+	end
+	`)
+	// 额度不存在时初始化，额度不足时返回 key 的剩余过期时间，decr 不会改变过期时间
+	takeScript = redis.NewScript(`
+	if redis.call("exists", KEYS[1]) == 0 then
+		redis.call("set", KEYS[1], ARGV[1], "px", ARGV[2])
+	end
+	if tonumber(redis.call("get", KEYS[1])) <= 0 then
+		return {0, redis.call("pttl", KEYS[1])}
+	end
+	redis.call("decr", KEYS[1])
+	return {1, 0}
+	`)
+)
+
+// RedisBackend 基于 redis SETNX 与 lua 脚本实现的锁后端
+// 支持 redis.UniversalClient，即单机，哨兵与集群模式的客户端
+type RedisBackend struct {
+	client redis.UniversalClient
+}
+
+// NewRedisBackend 创建 redis 锁后端
+func NewRedisBackend(client redis.UniversalClient) *RedisBackend {
+	return &RedisBackend{client: client}
+}
+
+// Acquire 加锁
+func (b *RedisBackend) Acquire(ctx context.Context, key, value string, expire time.Duration) (bool, error) {
+	return b.client.SetNX(ctx, key, value, expire).Result()
+}
+
+// Renew 续期锁
+func (b *RedisBackend) Renew(ctx context.Context, key, value string, expire time.Duration) error {
+	return renewScript.Run(ctx, b.client, []string{key}, value, expire.Seconds()).Err()
+}
+
+// Release 释放锁
+func (b *RedisBackend) Release(ctx context.Context, key, value string) error {
+	return releaseScript.Run(ctx, b.client, []string{key}, value).Err()
+}
+
+// Take 消耗一次共享额度，额度保存在单个 key 中，集群模式下同样可用
+func (b *RedisBackend) Take(ctx context.Context,
+	key string, initial int64, expire time.Duration) (time.Duration, bool, error) {
+	result, err := takeScript.Run(ctx, b.client, []string{key}, initial, expireMillis(expire)).Int64Slice()
+	if err != nil {
+		return 0, false, err
+	}
+	return time.Duration(result[1]) * time.Millisecond, result[0] == 1, nil
+}
+
+// expireMillis 过期时间转换为毫秒，至少为 1 毫秒
+func expireMillis(expire time.Duration) int64 {
+	if ms := expire.Milliseconds(); ms > 0 {
+		return ms
+	}
+	return 1
+}
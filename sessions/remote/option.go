@@ -1,11 +1,39 @@
 package remote
 
+import (
+	"time"
+
+	"github.com/tencent-connect/botgo/event"
+	"github.com/tencent-connect/botgo/sessions/manager"
+)
+
 // Option is a function that configures a Remote.
-type Option func(manager *RedisManager)
+type Option func(manager *Manager)
 
-// WithClusterKey 自定义集群key，用于创建分布式锁与redis list
+// WithClusterKey 自定义集群key，用于创建分布式锁与 session 队列
 func WithClusterKey(key string) Option {
-	return func(m *RedisManager) {
+	return func(m *Manager) {
 		m.clusterKey = key
 	}
 }
+
+// WithDispatcher 指定事件分发器，未指定时使用 event.DefaultDispatcher
+func WithDispatcher(dispatcher *event.Dispatcher) Option {
+	return func(m *Manager) {
+		m.dispatcher = dispatcher
+	}
+}
+
+// WithShutdownTimeout 设置关闭时等待连接退出、释放锁的超时时间，默认为 manager.DefaultShutdownTimeout
+func WithShutdownTimeout(timeout time.Duration) Option {
+	return func(m *Manager) {
+		m.shutdownTimeout = timeout
+	}
+}
+
+// WithStartLimitHandler 设置启动额度不足时的回调，shard 会在额度重置后放回队列
+func WithStartLimitHandler(handler manager.StartLimitHandler) Option {
+	return func(m *Manager) {
+		m.onStartLimit = handler
+	}
+}
@@ -0,0 +1,70 @@
+package queue
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryQueue 基于内存的队列，只在单个进程内生效，用于测试或者单机部署
+type MemoryQueue struct {
+	lock   sync.Mutex
+	queues map[string][][]byte
+	notify chan struct{} // 有新数据时关闭，唤醒等待中的 Pop
+}
+
+// NewMemoryQueue 创建内存队列
+func NewMemoryQueue() *MemoryQueue {
+	return &MemoryQueue{
+		queues: map[string][][]byte{},
+		notify: make(chan struct{}),
+	}
+}
+
+// Push 放入一条数据
+func (q *MemoryQueue) Push(_ context.Context, key string, data []byte) error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	q.queues[key] = append(q.queues[key], data)
+	close(q.notify)
+	q.notify = make(chan struct{})
+	return nil
+}
+
+// Pop 取出最早放入的一条数据
+func (q *MemoryQueue) Pop(ctx context.Context, key string, timeout time.Duration) ([]byte, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		q.lock.Lock()
+		if items := q.queues[key]; len(items) > 0 {
+			q.queues[key] = items[1:]
+			q.lock.Unlock()
+			return items[0], nil
+		}
+		notify := q.notify
+		q.lock.Unlock()
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timer.C:
+			return nil, ErrEmpty
+		case <-notify:
+		}
+	}
+}
+
+// Clear 清空队列
+func (q *MemoryQueue) Clear(_ context.Context, key string) error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	delete(q.queues, key)
+	return nil
+}
+
+// Len 返回队列长度
+func (q *MemoryQueue) Len(key string) int {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return len(q.queues[key])
+}
@@ -0,0 +1,42 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryQueue(t *testing.T) {
+	ctx := context.Background()
+	q := NewMemoryQueue()
+
+	_, err := q.Pop(ctx, "key", 10*time.Millisecond)
+	assert.Equal(t, ErrEmpty, err)
+
+	assert.Nil(t, q.Push(ctx, "key", []byte("1")))
+	assert.Nil(t, q.Push(ctx, "key", []byte("2")))
+	assert.Nil(t, q.Push(ctx, "other", []byte("3")))
+	data, err := q.Pop(ctx, "key", time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, "1", string(data))
+
+	assert.Nil(t, q.Clear(ctx, "key"))
+	assert.Equal(t, 0, q.Len("key"))
+	assert.Equal(t, 1, q.Len("other"))
+
+	// 等待中的 Pop 会被新数据唤醒
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		_ = q.Push(ctx, "key", []byte("4"))
+	}()
+	data, err = q.Pop(ctx, "key", time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, "4", string(data))
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = q.Pop(cancelled, "key", time.Second)
+	assert.Equal(t, context.Canceled, err)
+}
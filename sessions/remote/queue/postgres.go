@@ -0,0 +1,78 @@
+package queue
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// DefaultPostgresTable PostgresQueue 默认使用的表名
+const DefaultPostgresTable = "botgo_session_queue"
+
+// postgresPollInterval 队列为空时轮询的间隔
+const postgresPollInterval = 200 * time.Millisecond
+
+// PostgresQueue 基于 PostgreSQL 表的队列，使用 FOR UPDATE SKIP LOCKED 保证每条数据只被一个实例取出
+type PostgresQueue struct {
+	db    *sql.DB
+	table string
+}
+
+// NewPostgresQueue 创建 PostgreSQL 队列，table 为空时使用 DefaultPostgresTable
+// 表需要提前通过 CreateTable 创建
+func NewPostgresQueue(db *sql.DB, table string) *PostgresQueue {
+	if table == "" {
+		table = DefaultPostgresTable
+	}
+	return &PostgresQueue{db: db, table: table}
+}
+
+// CreateTable 创建队列使用的表，表已经存在时不做任何操作
+func (q *PostgresQueue) CreateTable(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		id BIGSERIAL PRIMARY KEY,
+		queue_key TEXT NOT NULL,
+		data BYTEA NOT NULL
+	)`, q.table))
+	return err
+}
+
+// Push 放入一条数据
+func (q *PostgresQueue) Push(ctx context.Context, key string, data []byte) error {
+	_, err := q.db.ExecContext(ctx,
+		fmt.Sprintf("INSERT INTO %s (queue_key, data) VALUES ($1, $2)", q.table), key, data)
+	return err
+}
+
+// Pop 取出最早放入的一条数据，队列为空时轮询直到超时
+func (q *PostgresQueue) Pop(ctx context.Context, key string, timeout time.Duration) ([]byte, error) {
+	query := fmt.Sprintf(`DELETE FROM %[1]s WHERE id = (
+		SELECT id FROM %[1]s WHERE queue_key = $1 ORDER BY id LIMIT 1 FOR UPDATE SKIP LOCKED
+	) RETURNING data`, q.table)
+	deadline := time.Now().Add(timeout)
+	for {
+		var data []byte
+		err := q.db.QueryRowContext(ctx, query, key).Scan(&data)
+		if err == nil {
+			return data, nil
+		}
+		if err != sql.ErrNoRows {
+			return nil, err
+		}
+		if !time.Now().Before(deadline) {
+			return nil, ErrEmpty
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(postgresPollInterval):
+		}
+	}
+}
+
+// Clear 清空队列
+func (q *PostgresQueue) Clear(ctx context.Context, key string) error {
+	_, err := q.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE queue_key = $1", q.table), key)
+	return err
+}
@@ -0,0 +1,23 @@
+// Package queue 分布式 session manager 使用的 session 队列，队列按照先进先出的顺序消费。
+//
+// 内置了基于 redis list 的 RedisQueue，基于 PostgreSQL 表的 PostgresQueue，以及用于测试与单机场景的 MemoryQueue。
+package queue
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrEmpty 在等待时间内队列中没有数据
+var ErrEmpty = errors.New("queue is empty")
+
+// Queue session 队列
+type Queue interface {
+	// Push 放入一条数据
+	Push(ctx context.Context, key string, data []byte) error
+	// Pop 取出最早放入的一条数据，最多等待 timeout，没有数据时返回 ErrEmpty
+	Pop(ctx context.Context, key string, timeout time.Duration) ([]byte, error)
+	// Clear 清空队列
+	Clear(ctx context.Context, key string) error
+}
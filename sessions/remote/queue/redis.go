@@ -0,0 +1,45 @@
+package queue
+
+import (
+	"context"
+	"time"
+
+	redis "github.com/go-redis/redis/v8"
+)
+
+// RedisQueue 基于 redis list 的队列，使用 LPUSH 放入，BRPOP 取出
+// 支持 redis.UniversalClient，即单机，哨兵与集群模式的客户端
+type RedisQueue struct {
+	client redis.UniversalClient
+}
+
+// NewRedisQueue 创建 redis 队列
+func NewRedisQueue(client redis.UniversalClient) *RedisQueue {
+	return &RedisQueue{client: client}
+}
+
+// Push 放入一条数据
+func (q *RedisQueue) Push(ctx context.Context, key string, data []byte) error {
+	return q.client.LPush(ctx, key, data).Err()
+}
+
+// Pop 取出最早放入的一条数据
+func (q *RedisQueue) Pop(ctx context.Context, key string, timeout time.Duration) ([]byte, error) {
+	// brpop 返回 key value
+	data, err := q.client.BRPop(ctx, timeout, key).Result()
+	if err == redis.Nil {
+		return nil, ErrEmpty
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 2 {
+		return nil, ErrEmpty
+	}
+	return []byte(data[1]), nil
+}
+
+// Clear 清空队列
+func (q *RedisQueue) Clear(ctx context.Context, key string) error {
+	return q.client.Del(ctx, key).Err()
+}
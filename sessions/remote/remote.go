@@ -1,4 +1,6 @@
-// Package remote 基于 redis list 实现的分布式 session manager。
+// Package remote 基于分布式队列与分布式锁实现的 session manager。
+//
+// 默认使用 redis，也可以通过 NewWithBackend 使用 queue 与 lock 包中的其他后端，如 PostgreSQL，内存。
 package remote
 
 import (
@@ -10,9 +12,12 @@ import (
 	"github.com/go-redis/redis/v8"
 	"github.com/google/uuid"
 	"github.com/tencent-connect/botgo/dto"
+	"github.com/tencent-connect/botgo/errs"
+	"github.com/tencent-connect/botgo/event"
 	"github.com/tencent-connect/botgo/log"
 	"github.com/tencent-connect/botgo/sessions/manager"
 	"github.com/tencent-connect/botgo/sessions/remote/lock"
+	"github.com/tencent-connect/botgo/sessions/remote/queue"
 	"github.com/tencent-connect/botgo/token"
 	"github.com/tencent-connect/botgo/websocket"
 )
@@ -28,20 +33,39 @@ const (
 	shardLockExpireTime = 30 * time.Second
 )
 
-// RedisManager 基于 redis 的 session 管理器，实现分布式 websocket 监听
-type RedisManager struct {
+// Manager 分布式 session 管理器，实现分布式 websocket 监听
+type Manager struct {
 	clusterKey         string
 	sessionQueueKey    string
-	client             *redis.Client
-	sessionProduceChan chan dto.Session // 抢到锁的服务，用于持续生产session到redis list的本地chan
+	queue              queue.Queue
+	locker             lock.Backend
+	token              *token.Token
+	dispatcher         *event.Dispatcher
+	shutdownTimeout    time.Duration
+	conns              *manager.Connections // 本实例正在运行的连接
+	sessionProduceChan chan dto.Session     // 抢到锁的服务，用于持续生产session到队列的本地chan
+	budget             startBudget          // 鉴权需要消耗的启动额度，锁后端支持时由所有实例共享
+	onStartLimit       manager.StartLimitHandler
 }
 
-// New 创建一个新的基于 redis 的 session 管理器
+// RedisManager 基于 redis 的 session 管理器
+//
+// Deprecated: 使用 Manager
+type RedisManager = Manager
+
+// New 创建一个新的基于 redis 的 session 管理器，client 可以是单机，哨兵或者集群模式的客户端
 // 使用 go-redis 调用 redis，超时时间请在 NewClient 时候设置
-func New(client *redis.Client, opts ...Option) *RedisManager {
-	r := &RedisManager{
-		clusterKey: defaultClusterKey,
-		client:     client,
+func New(client redis.UniversalClient, opts ...Option) *Manager {
+	return NewWithBackend(queue.NewRedisQueue(client), lock.NewRedisBackend(client), opts...)
+}
+
+// NewWithBackend 创建一个使用指定队列与锁后端的 session 管理器
+func NewWithBackend(q queue.Queue, locker lock.Backend, opts ...Option) *Manager {
+	r := &Manager{
+		clusterKey:      defaultClusterKey,
+		queue:           q,
+		locker:          locker,
+		shutdownTimeout: manager.DefaultShutdownTimeout,
 	}
 	for _, opt := range opts {
 		opt(r)
@@ -51,75 +75,146 @@ func New(client *redis.Client, opts ...Option) *RedisManager {
 	return r
 }
 
-// Start 启动 redis 的 session 管理器
-func (r *RedisManager) Start(apInfo *dto.WebsocketAP, token *token.Token, intents *dto.Intent) error {
+// Start 启动分布式 session 管理器，会阻塞直到 ctx 结束
+// 启动额度不足时，在额度允许的范围内启动 shard，剩余的 shard 在额度重置后放回队列
+// 锁后端实现了 lock.Quota 时，启动额度保存在锁后端中，由所有实例共同消耗
+// ctx 结束后会关闭本实例的所有连接，释放 shard 锁，并将 session 放回队列，由其他实例 resume
+// 关闭过程中各个 shard 的错误通过 manager.ShardErrors 返回
+func (r *Manager) Start(
+	ctx context.Context, apInfo *dto.WebsocketAP, token *token.Token, intents *dto.Intent,
+) error {
 	defer log.Sync()
-	if err := manager.CheckSessionLimit(apInfo); err != nil {
-		log.Errorf("[ws/session/redis] session limited apInfo: %+v", apInfo)
-		return err
-	}
 	startInterval := manager.CalcInterval(apInfo.SessionStartLimit.MaxConcurrency)
-	log.Infof("[ws/session/redis] will start %d sessions and per session start interval is %s",
+	log.Infof("[ws/session/remote] will start %d sessions and per session start interval is %s",
 		apInfo.Shards, startInterval)
 
+	r.token = token
+	r.conns = manager.NewConnections()
+	r.budget = newStartBudget(r.locker, fmt.Sprintf("%s_%s", r.clusterKey, startQuotaSuffix), apInfo.SessionStartLimit)
+	log.Infof("[ws/session/remote] session start limit: %+v", apInfo.SessionStartLimit)
 	// session 生产队列
 	r.sessionProduceChan = make(chan dto.Session, apInfo.Shards)
 
 	// 进行初始的session分发，抢锁，分发
 	// 锁60s，抢到锁的进程，需要每30s续期一次，只要自己还存活，就不能够让另外的进程抢到锁重新进行shards分发
-	ctx := context.Background()
-	distributeLock := lock.New(r.clusterKey, uuid.New().String(), r.client)
+	distributeLock := lock.NewWithBackend(r.clusterKey, uuid.New().String(), r.locker)
 	if err := distributeLock.Lock(ctx, distributeLockExpireTime); err == nil {
-		log.Infof("[ws/session/redis] got distribute lock! i will do distributeSession, key: %s", r.clusterKey)
+		log.Infof("[ws/session/remote] got distribute lock! i will do distributeSession, key: %s", r.clusterKey)
 		// 抢到锁的进行初次分发
 		if err = r.distributeSession(apInfo, token, intents); err != nil {
-			log.Errorf("[ws/session/redis] distribute sessions failed: %v", err)
+			log.Errorf("[ws/session/remote] distribute sessions failed: %v", err)
 			return err
 		}
 		go distributeLock.StartRenew(ctx, distributeLockExpireTime)
+		defer func() {
+			if err := r.releaseLock(distributeLock); err != nil {
+				log.Errorf("[ws/session/remote] release distribute lock failed, err: %v", err)
+			}
+		}()
 	} else {
 		log.Errorf("got lock failed, err: %v", err)
 	}
 
 	// 持续 produce session，遇到网络问题在 chan 中重试
-	// 对于抢到了锁的服务，生产第一批session到redis list
-	// 对于没有抢到锁的服务，当ws异常，把session放回到 redis list 中，重新分发
-	go r.sessionProducer(startInterval)
+	// 对于抢到了锁的服务，生产第一批session到队列
+	// 对于没有抢到锁的服务，当ws异常，把session放回到队列中，重新分发
+	go r.sessionProducer(ctx, startInterval)
 
-	return r.consume(startInterval)
+	return r.consume(ctx, apInfo, startInterval)
 }
 
-func (r *RedisManager) consume(startInterval time.Duration) error {
-	log.Debug("[ws/session/redis] start consume for session")
+func (r *Manager) consume(ctx context.Context, apInfo *dto.WebsocketAP, startInterval time.Duration) error {
+	log.Debug("[ws/session/remote] start consume for session")
 	for {
-		// brpop 返回 key value
-		data, err := r.client.BRPop(context.Background(), startInterval*2, r.sessionQueueKey).Result()
+		if ctx.Err() != nil {
+			return r.shutdown(apInfo)
+		}
+		data, err := r.queue.Pop(ctx, r.sessionQueueKey, startInterval*2)
 		if err != nil {
-			if err != redis.Nil {
-				log.Errorf("[ws/session/redis] rpop failed, err: %v", err)
+			if err != queue.ErrEmpty && ctx.Err() == nil {
+				log.Errorf("[ws/session/remote] pop failed, err: %v", err)
 			}
 			continue
 		}
-		if len(data) < 2 {
-			log.Errorf("[ws/session/redis] data is not valid, data: %+v", data)
-			continue
-		}
-		log.Debugf("[ws/session/redis] consume data: %s", data)
+		log.Debugf("[ws/session/remote] consume data: %s", data)
 
 		session := &dto.Session{}
-		if err := json.Unmarshal([]byte(data[1]), session); err != nil {
+		if err := json.Unmarshal(data, session); err != nil {
 			// 解析出错，不放回去，直接丢弃
-			log.Errorf("[ws/session/redis] unmarshal session failed, err: %v", err)
+			log.Errorf("[ws/session/remote] unmarshal session failed, err: %v", err)
+			continue
+		}
+		// token 的 source 不会被序列化，使用本进程的 token，保证能够获取到最新的 access token
+		session.Token = *r.token
+
+		// 启动额度不足，等待额度重置后再放回队列
+		if wait, ok := r.budget.AcquireFor(*session, r.onStartLimit); !ok {
+			r.conns.Add()
+			go r.delayStart(ctx, *session, wait)
 			continue
 		}
+		r.conns.Add()
+		go r.newConnect(ctx, *session)
+		// 启动一个连接后，等待一下，避免触发服务端的并发控制
+		if !manager.Sleep(ctx, startInterval) {
+			return r.shutdown(apInfo)
+		}
+	}
+}
 
-		go r.newConnect(*session)
-		time.Sleep(startInterval) // 启动一个连接后，等待一下，避免触发服务端的并发控制
+// shutdown 关闭本实例的所有连接，等待连接退出后，将还未生产到队列 的 session 放回队列
+func (r *Manager) shutdown(apInfo *dto.WebsocketAP) error {
+	log.Infof("[ws/session/remote] shutting down, shard count %d", apInfo.Shards)
+	if err := r.conns.Shutdown(r.shutdownTimeout); err != nil {
+		log.Errorf("[ws/session/remote] close connections failed: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), r.shutdownTimeout)
+	defer cancel()
+	for {
+		select {
+		case session := <-r.sessionProduceChan:
+			if err := r.produce(ctx, session); err != nil {
+				log.Errorf("[ws/session/remote] produce session %s on shutdown failed: %v", &session, err)
+				r.conns.SetError(session.Shards.ShardID, err)
+			}
+		default:
+			return r.conns.Err()
+		}
 	}
 }
 
+// releaseLock 停止续期并释放锁，关闭时 ctx 已经结束，所以使用新的 ctx
+func (r *Manager) releaseLock(l *lock.Lock) error {
+	l.StopRenew()
+	ctx, cancel := context.WithTimeout(context.Background(), r.shutdownTimeout)
+	defer cancel()
+	return l.Release(ctx)
+}
+
+// requeue 将 session 放回生产队列，由 sessionProducer 放回队列
+// 关闭过程中直接写回队列，让其他实例可以尽快 resume
+func (r *Manager) requeue(ctx context.Context, session dto.Session) {
+	if ctx.Err() == nil {
+		r.sessionProduceChan <- session
+		return
+	}
+	produceCtx, cancel := context.WithTimeout(context.Background(), r.shutdownTimeout)
+	defer cancel()
+	if err := r.produce(produceCtx, session); err != nil {
+		log.Errorf("[ws/session/remote] produce session %s on shutdown failed: %v", &session, err)
+		r.conns.SetError(session.Shards.ShardID, err)
+	}
+}
+
+// delayStart 等待启动额度重置后，将 session 放回队列，关闭过程中直接放回队列由其他实例启动
+func (r *Manager) delayStart(ctx context.Context, session dto.Session, wait time.Duration) {
+	defer r.conns.Done()
+	manager.Sleep(ctx, wait)
+	r.requeue(ctx, session)
+}
+
 // getShardLockKey 获取 shard 的锁
-func (r *RedisManager) getShardLockKey(session dto.Session) string {
+func (r *Manager) getShardLockKey(session dto.Session) string {
 	return fmt.Sprintf("%s_shard_%d_%d",
 		r.clusterKey, session.Shards.ShardID, session.Shards.ShardCount)
 }
@@ -128,23 +223,38 @@ func (r *RedisManager) getShardLockKey(session dto.Session) string {
 // 如果能够 resume，则往 sessionChan 中放入带有 sessionID 的 session
 // 如果不能，则清理掉 sessionID，将 session 放入 sessionChan 中
 // session 的启动，交给 start 中的 for 循环执行，session 不自己递归进行重连，避免递归深度过深
-func (r *RedisManager) newConnect(session dto.Session) {
-	ctx := context.Background()
+func (r *Manager) newConnect(ctx context.Context, session dto.Session) {
+	defer r.conns.Done()
 	// 锁 shard，避免针对相同 shard 消费重复了
-	shardLock := lock.New(r.getShardLockKey(session), uuid.NewString(), r.client)
+	shardLock := lock.NewWithBackend(r.getShardLockKey(session), uuid.NewString(), r.locker)
 	if err := shardLock.Lock(ctx, shardLockExpireTime); err != nil {
 		// shard 抢锁失败，把 session 放回去，避免上一个 session 的锁释放失败，导致下一个 session 无法启动
-		r.sessionProduceChan <- session
+		r.requeue(ctx, session)
 		return
 	}
 	go shardLock.StartRenew(ctx, shardLockExpireTime)
 
-	wsClient := websocket.ClientImpl.New(session)
+	wsClient := websocket.ClientImpl.New(session, r.dispatcher)
 	if err := wsClient.Connect(); err != nil {
 		log.Error(err)
-		r.sessionProduceChan <- session // 连接失败，丢回去队列排队重连
+		r.releaseShardLock(shardLock, session)
+		r.requeue(ctx, session) // 连接失败，丢回去队列排队重连
 		return
 	}
+	if !r.conns.Track(session.Shards.ShardID, wsClient) {
+		r.releaseShardLock(shardLock, session)
+		r.requeue(ctx, session)
+		return
+	}
+	var listenErr error
+	defer func() {
+		// 主动关闭时返回 ErrConnClosed 是正常的，其他错误需要报告给调用方
+		if ctx.Err() != nil && listenErr != errs.ErrConnClosed {
+			r.conns.Untrack(session.Shards.ShardID, listenErr)
+			return
+		}
+		r.conns.Untrack(session.Shards.ShardID, nil)
+	}()
 	var err error
 	// 如果 session id 不为空，则执行的是 resume 操作，如果为空，则执行的是 identify 操作
 	if session.ID != "" {
@@ -157,9 +267,17 @@ func (r *RedisManager) newConnect(session dto.Session) {
 		log.Errorf("[ws/session/remote] Identify/Resume err %+v", err)
 		return
 	}
-	if err := wsClient.Listening(); err != nil {
-		log.Errorf("[ws/session/remote] Listening err %+v", err)
+	if listenErr = wsClient.Listening(); listenErr != nil {
+		err := listenErr
 		currentSession := wsClient.Session()
+		if ctx.Err() != nil {
+			// 关闭过程中，释放锁，并保留 session id 与 seq 放回队列，由其他实例 resume
+			log.Infof("[ws/session/remote] %s stopped because manager is shutting down, err %+v", &session, err)
+			r.releaseShardLock(shardLock, session)
+			r.requeue(ctx, *currentSession)
+			return
+		}
+		log.Errorf("[ws/session/remote] Listening err %+v", err)
 		// 对于不能够进行重连的session，需要清空 session id 与 seq
 		if manager.CanNotResume(err) {
 			currentSession.ID = ""
@@ -172,11 +290,16 @@ func (r *RedisManager) newConnect(session dto.Session) {
 			panic(msg) // 当机器人被下架，或者封禁，将不能再连接，所以 panic
 		}
 		// 将 session 放到 session chan 中，用于启动新的连接，释放锁，当前连接退出
-		shardLock.StopRenew()
-		if err := shardLock.Release(ctx); err != nil {
-			log.Errorf("[ws/session/remote] release shardLock failed, err: %s", err)
-		}
-		r.sessionProduceChan <- *currentSession
+		r.releaseShardLock(shardLock, session)
+		r.requeue(ctx, *currentSession)
 		return
 	}
 }
+
+// releaseShardLock 释放 shard 锁，关闭过程中释放失败会作为该 shard 的错误返回
+func (r *Manager) releaseShardLock(shardLock *lock.Lock, session dto.Session) {
+	if err := r.releaseLock(shardLock); err != nil {
+		log.Errorf("[ws/session/remote] release shardLock failed, err: %s", err)
+		r.conns.SetError(session.Shards.ShardID, err)
+	}
+}
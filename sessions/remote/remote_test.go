@@ -0,0 +1,55 @@
+package remote
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tencent-connect/botgo/dto"
+	"github.com/tencent-connect/botgo/event"
+	"github.com/tencent-connect/botgo/sessions/remote/lock"
+	"github.com/tencent-connect/botgo/sessions/remote/queue"
+	"github.com/tencent-connect/botgo/token"
+	"github.com/tencent-connect/botgo/websocket/client"
+	"github.com/tencent-connect/botgo/websocket/gatewaytest"
+)
+
+func TestManager_MemoryBackend(t *testing.T) {
+	client.Setup()
+	server := gatewaytest.NewServer(
+		gatewaytest.WithShards(2),
+		gatewaytest.WithSessionStartLimit(dto.SessionStartLimit{Total: 10, Remaining: 10, MaxConcurrency: 10}),
+	)
+	defer server.Close()
+
+	q := queue.NewMemoryQueue()
+	locker := lock.NewMemoryBackend()
+	opts := []Option{
+		WithClusterKey("test"),
+		WithDispatcher(event.NewDispatcher()),
+		WithShutdownTimeout(time.Second),
+	}
+	// 两个实例共享队列与锁，共同消费两个 shard
+	m1 := NewWithBackend(q, locker, opts...)
+	m2 := NewWithBackend(q, locker, opts...)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 2)
+	intent := dto.IntentGuildAtMessage
+	for _, m := range []*Manager{m1, m2} {
+		m := m
+		go func() { done <- m.Start(ctx, server.WebsocketAP(), token.BotToken(1, "token"), &intent) }()
+	}
+
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer waitCancel()
+	assert.Nil(t, server.Wait(waitCtx, func(s gatewaytest.Stats) bool { return s.Ready == 2 }))
+	assert.Equal(t, 2, server.Stats().Identifies)
+
+	cancel()
+	assert.Nil(t, <-done)
+	assert.Nil(t, <-done)
+	// 关闭后 session 放回队列，由其他实例 resume
+	assert.Equal(t, 2, q.Len("test_"+sessionQueueSuffix))
+}
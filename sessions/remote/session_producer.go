@@ -7,14 +7,15 @@ import (
 
 	"github.com/tencent-connect/botgo/dto"
 	"github.com/tencent-connect/botgo/log"
+	"github.com/tencent-connect/botgo/sessions/manager"
 	"github.com/tencent-connect/botgo/token"
 )
 
-// distributeSession 根据 shards 生产初始化的 session，这里需要抢一个分布式锁，抢到锁的服务器，负责把session都生产到 redis 中
-func (r *RedisManager) distributeSession(apInfo *dto.WebsocketAP, token *token.Token, intents *dto.Intent) error {
+// distributeSession 根据 shards 生产初始化的 session，这里需要抢一个分布式锁，抢到锁的服务器，负责把session都生产到队列中
+func (r *Manager) distributeSession(apInfo *dto.WebsocketAP, token *token.Token, intents *dto.Intent) error {
 	// clear，报错也不影响
-	if err := r.client.Del(context.Background(), r.sessionQueueKey); err != nil {
-		log.Errorf("[ws/session/redis] clear session list failed: %v", err)
+	if err := r.queue.Clear(context.Background(), r.sessionQueueKey); err != nil {
+		log.Errorf("[ws/session/remote] clear session list failed: %v", err)
 	}
 	for i := uint32(0); i < apInfo.Shards; i++ {
 		session := dto.Session{
@@ -33,22 +34,32 @@ func (r *RedisManager) distributeSession(apInfo *dto.WebsocketAP, token *token.T
 	return nil
 }
 
-// sessionProducer 从 chan 取到session，push 到 redis，push 失败放回 chan
-func (r *RedisManager) sessionProducer(startInterval time.Duration) {
-	for session := range r.sessionProduceChan {
-		time.Sleep(startInterval) // 每次生产需要等待一个间隔，控制消费者连接并发
-		if err := r.produce(session); err != nil {
-			log.Errorf("[ws/session/redis] produce session failed: %v", err)
-			r.sessionProduceChan <- session // 放回去重试
+// sessionProducer 从 chan 取到session，push 到队列，push 失败放回 chan
+// ctx 结束后退出，chan 中剩余的 session 由 shutdown 放回队列
+func (r *Manager) sessionProducer(ctx context.Context, startInterval time.Duration) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case session := <-r.sessionProduceChan:
+			// 每次生产需要等待一个间隔，控制消费者连接并发
+			if !manager.Sleep(ctx, startInterval) {
+				r.sessionProduceChan <- session
+				return
+			}
+			if err := r.produce(ctx, session); err != nil {
+				log.Errorf("[ws/session/remote] produce session failed: %v", err)
+				r.sessionProduceChan <- session // 放回去重试
+			}
 		}
 	}
 }
 
-func (r *RedisManager) produce(session dto.Session) error {
+func (r *Manager) produce(ctx context.Context, session dto.Session) error {
 	data, err := json.Marshal(session)
-	log.Debugf("[ws][session/redis] produce session data is %s", string(data))
+	log.Debugf("[ws/session/remote] produce session data is %s", string(data))
 	if err != nil {
 		return ErrSessionMarshalFailed
 	}
-	return r.client.LPush(context.Background(), r.sessionQueueKey, data).Err()
+	return r.queue.Push(ctx, r.sessionQueueKey, data)
 }
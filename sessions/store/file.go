@@ -0,0 +1,183 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/tencent-connect/botgo/dto"
+	"github.com/tencent-connect/botgo/log"
+	"github.com/tencent-connect/botgo/websocket"
+)
+
+// fileMode session 文件的权限
+const fileMode = 0600
+
+// DefaultFlushInterval FileStore 默认合并 seq 更新写入文件的间隔
+const DefaultFlushInterval = time.Second
+
+// FileStore 基于本地文件的 session store，所有 shard 的状态保存在同一个 json 文件中
+// 每次写入都会先写入临时文件再重命名，避免进程异常退出导致文件损坏
+// 每个事件都会更新 seq，只有 seq 变化时不立即写入，而是按照 flush 间隔合并写入，session id 变化与删除时立即写入
+// 进程退出前需要调用 Close 写入还没有保存的 seq，使用 local session manager 时会在关闭时调用
+type FileStore struct {
+	path     string
+	interval time.Duration
+	lock     sync.Mutex
+	states   map[string]websocket.SessionState // 为空时从文件中加载
+	dirty    bool                              // 有还没有写入文件的 seq 更新
+	timer    *time.Timer                       // 合并写入的定时器，为空时没有等待中的写入
+}
+
+// FileOption FileStore 的可选配置
+type FileOption func(s *FileStore)
+
+// WithFlushInterval 设置合并 seq 更新写入文件的间隔，默认为 DefaultFlushInterval，小于等于 0 时每次保存都立即写入
+func WithFlushInterval(interval time.Duration) FileOption {
+	return func(s *FileStore) {
+		s.interval = interval
+	}
+}
+
+// NewFileStore 创建文件 session store，path 为保存状态的文件路径，所在目录需要存在
+func NewFileStore(path string, opts ...FileOption) *FileStore {
+	s := &FileStore{path: path, interval: DefaultFlushInterval}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Load 加载 session 状态
+func (s *FileStore) Load(_ context.Context, session dto.Session) (*websocket.SessionState, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	state, ok := s.states[websocket.SessionStoreKey(session)]
+	if !ok {
+		return nil, nil
+	}
+	return &state, nil
+}
+
+// Save 保存 session 状态
+func (s *FileStore) Save(_ context.Context, session dto.Session) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err := s.load(); err != nil {
+		return err
+	}
+	key := websocket.SessionStoreKey(session)
+	old, ok := s.states[key]
+	s.states[key] = *websocket.NewSessionState(session)
+	// 只有 seq 变化时合并写入，resume 使用稍旧的 seq 时服务端会补发之后的事件
+	if ok && old.ID == session.ID && s.interval > 0 {
+		s.dirty = true
+		if s.timer == nil {
+			s.timer = time.AfterFunc(s.interval, s.flushPending)
+		}
+		return nil
+	}
+	return s.flush()
+}
+
+// Close 写入还没有保存的 seq 更新，之后仍然可以继续使用
+func (s *FileStore) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if !s.dirty {
+		return nil
+	}
+	return s.flush()
+}
+
+// flushPending 定时写入合并的 seq 更新，失败时等待下一次保存重试
+func (s *FileStore) flushPending() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.timer = nil
+	if !s.dirty {
+		return
+	}
+	if err := s.flush(); err != nil {
+		log.Errorf("[ws/session/store] flush session file %s failed, %v", s.path, err)
+	}
+}
+
+// Delete 删除 session 状态
+func (s *FileStore) Delete(_ context.Context, session dto.Session) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err := s.load(); err != nil {
+		return err
+	}
+	key := websocket.SessionStoreKey(session)
+	if _, ok := s.states[key]; !ok {
+		return nil
+	}
+	delete(s.states, key)
+	return s.flush()
+}
+
+// load 首次使用时从文件中加载状态，文件不存在时为空，调用方需要持有锁
+func (s *FileStore) load() error {
+	if s.states != nil {
+		return nil
+	}
+	states := map[string]websocket.SessionState{}
+	data, err := ioutil.ReadFile(s.path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &states); err != nil {
+			return err
+		}
+	}
+	s.states = states
+	return nil
+}
+
+// flush 将状态写入文件，调用方需要持有锁
+func (s *FileStore) flush() error {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	s.dirty = false
+	if err := s.write(); err != nil {
+		s.dirty = true
+		return err
+	}
+	return nil
+}
+
+// write 先写入临时文件再重命名，调用方需要持有锁
+func (s *FileStore) write() error {
+	data, err := json.Marshal(s.states)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), fileMode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
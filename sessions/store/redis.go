@@ -0,0 +1,90 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	redis "github.com/go-redis/redis/v8"
+	"github.com/tencent-connect/botgo/dto"
+	"github.com/tencent-connect/botgo/websocket"
+)
+
+// 默认配置
+const (
+	// DefaultRedisKeyPrefix redis key 的默认前缀
+	DefaultRedisKeyPrefix = "botgo_session_"
+	// DefaultRedisExpire 保存的状态默认过期时间，超过这个时间没有更新的 session 已经无法 resume
+	DefaultRedisExpire = 24 * time.Hour
+)
+
+// RedisStore 基于 redis 的 session store，每个 shard 的状态保存在一个 key 中，可以在多个实例之间共享
+type RedisStore struct {
+	client redis.UniversalClient
+	prefix string
+	expire time.Duration
+}
+
+// RedisOption RedisStore 的可选配置
+type RedisOption func(s *RedisStore)
+
+// WithKeyPrefix 设置 redis key 的前缀，默认为 DefaultRedisKeyPrefix
+func WithKeyPrefix(prefix string) RedisOption {
+	return func(s *RedisStore) {
+		s.prefix = prefix
+	}
+}
+
+// WithExpire 设置保存的状态的过期时间，默认为 DefaultRedisExpire
+func WithExpire(expire time.Duration) RedisOption {
+	return func(s *RedisStore) {
+		s.expire = expire
+	}
+}
+
+// NewRedisStore 创建 redis session store
+func NewRedisStore(client redis.UniversalClient, opts ...RedisOption) *RedisStore {
+	s := &RedisStore{
+		client: client,
+		prefix: DefaultRedisKeyPrefix,
+		expire: DefaultRedisExpire,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Load 加载 session 状态
+func (s *RedisStore) Load(ctx context.Context, session dto.Session) (*websocket.SessionState, error) {
+	data, err := s.client.Get(ctx, s.key(session)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	state := &websocket.SessionState{}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// Save 保存 session 状态
+func (s *RedisStore) Save(ctx context.Context, session dto.Session) error {
+	data, err := json.Marshal(websocket.NewSessionState(session))
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, s.key(session), data, s.expire).Err()
+}
+
+// Delete 删除 session 状态
+func (s *RedisStore) Delete(ctx context.Context, session dto.Session) error {
+	return s.client.Del(ctx, s.key(session)).Err()
+}
+
+func (s *RedisStore) key(session dto.Session) string {
+	return s.prefix + websocket.SessionStoreKey(session)
+}
@@ -0,0 +1,56 @@
+// Package store websocket.SessionStore 的实现，用于持久化 session 状态，进程重启后可以 resume 而不是重新鉴权。
+//
+// 内置了基于文件的 FileStore，基于 redis 的 RedisStore，以及用于测试的 MemoryStore。
+package store
+
+import (
+	"context"
+	"sync"
+
+	"github.com/tencent-connect/botgo/dto"
+	"github.com/tencent-connect/botgo/websocket"
+)
+
+// MemoryStore 基于内存的 session store，进程重启后数据丢失，用于测试
+type MemoryStore struct {
+	lock   sync.Mutex
+	states map[string]websocket.SessionState
+}
+
+// NewMemoryStore 创建内存 session store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{states: map[string]websocket.SessionState{}}
+}
+
+// Load 加载 session 状态
+func (s *MemoryStore) Load(_ context.Context, session dto.Session) (*websocket.SessionState, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	state, ok := s.states[websocket.SessionStoreKey(session)]
+	if !ok {
+		return nil, nil
+	}
+	return &state, nil
+}
+
+// Save 保存 session 状态
+func (s *MemoryStore) Save(_ context.Context, session dto.Session) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.states[websocket.SessionStoreKey(session)] = *websocket.NewSessionState(session)
+	return nil
+}
+
+// Delete 删除 session 状态
+func (s *MemoryStore) Delete(_ context.Context, session dto.Session) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.states, websocket.SessionStoreKey(session))
+	return nil
+}
+
+var (
+	_ websocket.SessionStore = (*MemoryStore)(nil)
+	_ websocket.SessionStore = (*FileStore)(nil)
+	_ websocket.SessionStore = (*RedisStore)(nil)
+)
@@ -0,0 +1,91 @@
+package store
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tencent-connect/botgo/dto"
+	"github.com/tencent-connect/botgo/token"
+	"github.com/tencent-connect/botgo/websocket"
+)
+
+func testSession(shardID uint32) dto.Session {
+	return dto.Session{
+		ID:      "session",
+		LastSeq: 10 + shardID,
+		Token:   *token.BotToken(1, "token"),
+		Shards:  dto.ShardConfig{ShardID: shardID, ShardCount: 2},
+	}
+}
+
+func testStore(t *testing.T, s websocket.SessionStore) {
+	ctx := context.Background()
+	state, err := s.Load(ctx, testSession(0))
+	assert.Nil(t, err)
+	assert.Nil(t, state)
+
+	assert.Nil(t, s.Save(ctx, testSession(0)))
+	assert.Nil(t, s.Save(ctx, testSession(1)))
+	state, err = s.Load(ctx, testSession(1))
+	assert.Nil(t, err)
+	assert.Equal(t, "session", state.ID)
+	assert.Equal(t, uint32(11), state.LastSeq)
+
+	// shard 数量变化后不再使用之前的状态
+	changed := testSession(0)
+	changed.Shards.ShardCount = 3
+	state, err = s.Load(ctx, changed)
+	assert.Nil(t, err)
+	assert.Nil(t, state)
+
+	assert.Nil(t, s.Delete(ctx, testSession(0)))
+	state, err = s.Load(ctx, testSession(0))
+	assert.Nil(t, err)
+	assert.Nil(t, state)
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.json")
+	testStore(t, NewFileStore(path))
+
+	// 重新打开文件后可以读到之前保存的状态
+	state, err := NewFileStore(path).Load(context.Background(), testSession(1))
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(11), state.LastSeq)
+}
+
+func TestFileStore_FlushInterval(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "sessions.json")
+	s := NewFileStore(path, WithFlushInterval(time.Hour))
+	session := testSession(0)
+	assert.Nil(t, s.Save(ctx, session))
+
+	// 只有 seq 变化时不立即写入文件
+	session.LastSeq = 100
+	assert.Nil(t, s.Save(ctx, session))
+	state, err := NewFileStore(path).Load(ctx, session)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(10), state.LastSeq)
+
+	assert.Nil(t, s.Close())
+	state, err = NewFileStore(path).Load(ctx, session)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(100), state.LastSeq)
+
+	// 定时写入合并的更新
+	s = NewFileStore(path, WithFlushInterval(10*time.Millisecond))
+	session.LastSeq = 200
+	assert.Nil(t, s.Save(ctx, session))
+	assert.Eventually(t, func() bool {
+		state, err := NewFileStore(path).Load(ctx, session)
+		return err == nil && state.LastSeq == 200
+	}, time.Second, 10*time.Millisecond)
+}
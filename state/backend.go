@@ -0,0 +1,132 @@
+package state
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Backend 缓存的存储后端，多个 shard 或者多个实例之间需要共享缓存时，可以使用 RedisBackend 等外部存储
+type Backend interface {
+	// Get 获取缓存的值，不存在或者已经过期时返回 nil, nil
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set 设置缓存的值，ttl 为 0 时不过期
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete 删除缓存
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// KeyScanner 可以按照前缀列出 key 的存储后端，频道被删除时用于清理频道下的子频道与成员缓存
+// 没有实现时，频道被删除后只清理频道信息，子频道列表与身份组，其他缓存在过期后失效
+type KeyScanner interface {
+	// Keys 返回以 prefix 开头的 key
+	Keys(ctx context.Context, prefix string) ([]string, error)
+}
+
+// MemoryBackend 基于内存的存储后端，超过容量后淘汰最久没有使用的值
+type MemoryBackend struct {
+	lock       sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	lru        *list.List
+	now        func() time.Time
+}
+
+type memoryEntry struct {
+	key      string
+	value    []byte
+	expireAt time.Time
+}
+
+// NewMemoryBackend 创建内存存储后端，maxEntries 小于等于 0 时不限制容量
+func NewMemoryBackend(maxEntries int) *MemoryBackend {
+	return &MemoryBackend{
+		maxEntries: maxEntries,
+		entries:    map[string]*list.Element{},
+		lru:        list.New(),
+		now:        time.Now,
+	}
+}
+
+// Get 获取缓存的值
+func (m *MemoryBackend) Get(_ context.Context, key string) ([]byte, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	elem, ok := m.entries[key]
+	if !ok {
+		return nil, nil
+	}
+	entry := elem.Value.(*memoryEntry)
+	if !entry.expireAt.IsZero() && !m.now().Before(entry.expireAt) {
+		m.remove(elem)
+		return nil, nil
+	}
+	m.lru.MoveToFront(elem)
+	return entry.value, nil
+}
+
+// Set 设置缓存的值
+func (m *MemoryBackend) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	entry := &memoryEntry{key: key, value: value}
+	if ttl > 0 {
+		entry.expireAt = m.now().Add(ttl)
+	}
+	if elem, ok := m.entries[key]; ok {
+		elem.Value = entry
+		m.lru.MoveToFront(elem)
+		return nil
+	}
+	m.entries[key] = m.lru.PushFront(entry)
+	if m.maxEntries > 0 && m.lru.Len() > m.maxEntries {
+		m.remove(m.lru.Back())
+	}
+	return nil
+}
+
+// Delete 删除缓存
+func (m *MemoryBackend) Delete(_ context.Context, keys ...string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for _, key := range keys {
+		if elem, ok := m.entries[key]; ok {
+			m.remove(elem)
+		}
+	}
+	return nil
+}
+
+// Keys 返回以 prefix 开头并且没有过期的 key
+func (m *MemoryBackend) Keys(_ context.Context, prefix string) ([]string, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	now := m.now()
+	var keys []string
+	for key, elem := range m.entries {
+		entry := elem.Value.(*memoryEntry)
+		if strings.HasPrefix(key, prefix) && (entry.expireAt.IsZero() || now.Before(entry.expireAt)) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// Len 返回缓存的数量，包括已经过期但是还没有淘汰的值
+func (m *MemoryBackend) Len() int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.lru.Len()
+}
+
+func (m *MemoryBackend) remove(elem *list.Element) {
+	m.lru.Remove(elem)
+	delete(m.entries, elem.Value.(*memoryEntry).key)
+}
+
+var (
+	_ KeyScanner = (*MemoryBackend)(nil)
+	_ KeyScanner = (*RedisBackend)(nil)
+)
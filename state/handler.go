@@ -0,0 +1,60 @@
+package state
+
+import (
+	"context"
+
+	"github.com/tencent-connect/botgo/dto"
+	"github.com/tencent-connect/botgo/event"
+)
+
+// Handlers 返回更新缓存的事件 handler，通过事件分发器的 RegisterHandlers 注册
+// 可以与业务自己的同类 handler 一起注册，缓存的更新不会影响业务 handler 的调用
+func (c *Cache) Handlers() []interface{} {
+	return []interface{}{
+		event.GuildEventContextHandler(c.handleGuild),
+		event.ChannelEventContextHandler(c.handleChannel),
+		event.GuildMemberEventContextHandler(c.handleMember),
+	}
+}
+
+func (c *Cache) handleGuild(ctx context.Context, payload *dto.WSPayload, data *dto.WSGuildData) error {
+	if payload.Type == dto.EventGuildDelete {
+		c.purgeGuild(ctx, data.ID)
+		return nil
+	}
+	guild := dto.Guild(*data)
+	// GUILD_CREATE 事件携带子频道列表时一并缓存
+	if len(guild.Channels) > 0 {
+		c.set(ctx, channelsKey(guild.ID), guild.Channels)
+		for _, channel := range guild.Channels {
+			c.setChannel(ctx, channel)
+		}
+	}
+	guild.Channels = nil
+	c.set(ctx, guildKey(guild.ID), &guild)
+	return nil
+}
+
+func (c *Cache) handleChannel(ctx context.Context, payload *dto.WSPayload, data *dto.WSChannelData) error {
+	// 子频道变化后，频道的子频道列表在下次读取时重新获取
+	c.delete(ctx, channelsKey(data.GuildID))
+	if payload.Type == dto.EventChannelDelete {
+		c.delete(ctx, channelKey(data.ID), guildChannelKey(data.GuildID, data.ID))
+		return nil
+	}
+	c.setChannel(ctx, (*dto.Channel)(data))
+	return nil
+}
+
+func (c *Cache) handleMember(ctx context.Context, payload *dto.WSPayload, data *dto.WSGuildMemberData) error {
+	if data.User == nil {
+		return nil
+	}
+	key := memberKey(data.GuildID, data.User.ID)
+	if payload.Type == dto.EventGuildMemberRemove {
+		c.delete(ctx, key)
+		return nil
+	}
+	c.set(ctx, key, (*dto.Member)(data))
+	return nil
+}
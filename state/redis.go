@@ -0,0 +1,106 @@
+package state
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	redis "github.com/go-redis/redis/v8"
+)
+
+// DefaultRedisKeyPrefix redis key 的默认前缀
+const DefaultRedisKeyPrefix = "botgo_state_"
+
+// RedisBackend 基于 redis 的存储后端，可以在多个 shard 与多个实例之间共享缓存
+type RedisBackend struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// RedisOption RedisBackend 的可选配置
+type RedisOption func(b *RedisBackend)
+
+// WithKeyPrefix 设置 redis key 的前缀，默认为 DefaultRedisKeyPrefix
+func WithKeyPrefix(prefix string) RedisOption {
+	return func(b *RedisBackend) {
+		b.prefix = prefix
+	}
+}
+
+// NewRedisBackend 创建 redis 存储后端
+func NewRedisBackend(client redis.UniversalClient, opts ...RedisOption) *RedisBackend {
+	b := &RedisBackend{client: client, prefix: DefaultRedisKeyPrefix}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// Get 获取缓存的值
+func (b *RedisBackend) Get(ctx context.Context, key string) ([]byte, error) {
+	data, err := b.client.Get(ctx, b.prefix+key).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	return data, err
+}
+
+// Set 设置缓存的值
+func (b *RedisBackend) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return b.client.Set(ctx, b.prefix+key, value, ttl).Err()
+}
+
+// Delete 删除缓存，集群模式下 key 可能在不同的 slot，所以逐个删除，通过 pipeline 减少请求次数
+func (b *RedisBackend) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	pipe := b.client.Pipeline()
+	for _, key := range keys {
+		pipe.Del(ctx, b.prefix+key)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Keys 使用 SCAN 返回以 prefix 开头的 key，集群模式下扫描所有的 master 节点
+func (b *RedisBackend) Keys(ctx context.Context, prefix string) ([]string, error) {
+	match := escapePattern(b.prefix+prefix) + "*"
+	cluster, ok := b.client.(*redis.ClusterClient)
+	if !ok {
+		return b.scan(ctx, b.client, match)
+	}
+	var lock sync.Mutex
+	var keys []string
+	err := cluster.ForEachMaster(ctx, func(ctx context.Context, client *redis.Client) error {
+		found, err := b.scan(ctx, client, match)
+		lock.Lock()
+		defer lock.Unlock()
+		keys = append(keys, found...)
+		return err
+	})
+	return keys, err
+}
+
+// scan 扫描单个节点上匹配 match 的 key，返回去掉前缀的 key
+func (b *RedisBackend) scan(ctx context.Context, client redis.Cmdable, match string) ([]string, error) {
+	var keys []string
+	iter := client.Scan(ctx, 0, match, 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, strings.TrimPrefix(iter.Val(), b.prefix))
+	}
+	return keys, iter.Err()
+}
+
+// escapePattern 转义 SCAN MATCH 中的通配符
+func escapePattern(s string) string {
+	var builder strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(`*?[]\`, r) {
+			builder.WriteRune('\\')
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
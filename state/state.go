@@ -0,0 +1,247 @@
+// Package state 提供频道状态的缓存，通过 websocket 事件更新，缓存中没有时通过 openapi 获取
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/tencent-connect/botgo/dto"
+	"github.com/tencent-connect/botgo/log"
+	"github.com/tencent-connect/botgo/openapi"
+)
+
+// 默认配置
+const (
+	// DefaultTTL 缓存的默认过期时间，过期后重新通过 openapi 获取
+	DefaultTTL = 10 * time.Minute
+	// DefaultMaxEntries 默认内存存储后端的容量
+	DefaultMaxEntries = 10000
+)
+
+// ErrNotCached 缓存中没有，并且没有设置 openapi
+var ErrNotCached = errors.New("state: not cached")
+
+// Cache 频道，子频道，成员与身份组的缓存
+//
+// 通过 Handlers 注册到事件分发器后，由 GUILD_*，CHANNEL_*，GUILD_MEMBER_* 事件更新，需要对应的 intent
+type Cache struct {
+	backend Backend
+	api     openapi.OpenAPI
+	ttl     time.Duration
+}
+
+// Option 缓存的配置
+type Option func(c *Cache)
+
+// WithBackend 设置存储后端，默认为容量 DefaultMaxEntries 的 MemoryBackend
+func WithBackend(backend Backend) Option {
+	return func(c *Cache) {
+		c.backend = backend
+	}
+}
+
+// WithOpenAPI 设置缓存中没有时用于获取数据的 openapi，不设置时返回 ErrNotCached
+func WithOpenAPI(api openapi.OpenAPI) Option {
+	return func(c *Cache) {
+		c.api = api
+	}
+}
+
+// WithTTL 设置缓存的过期时间，默认为 DefaultTTL，0 表示不过期
+func WithTTL(ttl time.Duration) Option {
+	return func(c *Cache) {
+		c.ttl = ttl
+	}
+}
+
+// New 创建缓存
+func New(opts ...Option) *Cache {
+	c := &Cache{ttl: DefaultTTL}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.backend == nil {
+		c.backend = NewMemoryBackend(DefaultMaxEntries)
+	}
+	return c
+}
+
+// Guild 获取频道信息
+func (c *Cache) Guild(ctx context.Context, guildID string) (*dto.Guild, error) {
+	guild := &dto.Guild{}
+	err := c.load(ctx, guildKey(guildID), guild, func(api openapi.OpenAPI) (interface{}, error) {
+		return api.Guild(ctx, guildID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return guild, nil
+}
+
+// Channel 获取子频道信息
+func (c *Cache) Channel(ctx context.Context, channelID string) (*dto.Channel, error) {
+	channel := &dto.Channel{}
+	err := c.load(ctx, channelKey(channelID), channel, func(api openapi.OpenAPI) (interface{}, error) {
+		fetched, err := api.Channel(ctx, channelID)
+		if err == nil {
+			c.set(ctx, guildChannelKey(fetched.GuildID, fetched.ID), fetched.ID)
+		}
+		return fetched, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return channel, nil
+}
+
+// Channels 获取频道下的子频道列表
+func (c *Cache) Channels(ctx context.Context, guildID string) ([]*dto.Channel, error) {
+	var channels []*dto.Channel
+	err := c.load(ctx, channelsKey(guildID), &channels, func(api openapi.OpenAPI) (interface{}, error) {
+		return api.Channels(ctx, guildID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return channels, nil
+}
+
+// Member 获取频道成员信息
+func (c *Cache) Member(ctx context.Context, guildID, userID string) (*dto.Member, error) {
+	member := &dto.Member{}
+	err := c.load(ctx, memberKey(guildID, userID), member, func(api openapi.OpenAPI) (interface{}, error) {
+		return api.GuildMember(ctx, guildID, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// MemberRoles 获取频道成员的身份组 ID
+func (c *Cache) MemberRoles(ctx context.Context, guildID, userID string) ([]string, error) {
+	member, err := c.Member(ctx, guildID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return member.Roles, nil
+}
+
+// Roles 获取频道的身份组列表，身份组没有对应的事件，只在过期后重新获取
+func (c *Cache) Roles(ctx context.Context, guildID string) (*dto.GuildRoles, error) {
+	roles := &dto.GuildRoles{}
+	err := c.load(ctx, rolesKey(guildID), roles, func(api openapi.OpenAPI) (interface{}, error) {
+		return api.Roles(ctx, guildID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// load 从缓存中读取到 value，缓存中没有时通过 fetch 获取并写入缓存
+func (c *Cache) load(ctx context.Context, key string, value interface{},
+	fetch func(api openapi.OpenAPI) (interface{}, error)) error {
+	data, err := c.backend.Get(ctx, key)
+	if err != nil {
+		log.Warnf("[state] get %s failed, %v", key, err)
+	}
+	if data != nil {
+		return json.Unmarshal(data, value)
+	}
+	if c.api == nil {
+		return ErrNotCached
+	}
+	fetched, err := fetch(c.api)
+	if err != nil {
+		return err
+	}
+	if data, err = json.Marshal(fetched); err != nil {
+		return err
+	}
+	if err := c.backend.Set(ctx, key, data, c.ttl); err != nil {
+		log.Warnf("[state] set %s failed, %v", key, err)
+	}
+	return json.Unmarshal(data, value)
+}
+
+// set 写入缓存，失败时只记录日志
+func (c *Cache) set(ctx context.Context, key string, value interface{}) {
+	data, err := json.Marshal(value)
+	if err == nil {
+		err = c.backend.Set(ctx, key, data, c.ttl)
+	}
+	if err != nil {
+		log.Warnf("[state] set %s failed, %v", key, err)
+	}
+}
+
+// setChannel 写入子频道缓存，并记录子频道所属的频道，频道被删除时一并清理
+func (c *Cache) setChannel(ctx context.Context, channel *dto.Channel) {
+	c.set(ctx, channelKey(channel.ID), channel)
+	c.set(ctx, guildChannelKey(channel.GuildID, channel.ID), channel.ID)
+}
+
+// purgeGuild 删除频道以及频道下的子频道，成员与身份组缓存
+// 存储后端没有实现 KeyScanner 时，只能清理子频道列表中的子频道，成员缓存在过期后失效
+func (c *Cache) purgeGuild(ctx context.Context, guildID string) {
+	keys := []string{guildKey(guildID), channelsKey(guildID), rolesKey(guildID)}
+	var channels []*dto.Channel
+	if data, err := c.backend.Get(ctx, channelsKey(guildID)); err == nil && data != nil {
+		_ = json.Unmarshal(data, &channels)
+	}
+	for _, channel := range channels {
+		keys = append(keys, channelKey(channel.ID), guildChannelKey(guildID, channel.ID))
+	}
+	if scanner, ok := c.backend.(KeyScanner); ok {
+		keys = append(keys, c.scan(ctx, scanner, memberKey(guildID, ""))...)
+		for _, key := range c.scan(ctx, scanner, guildChannelKey(guildID, "")) {
+			keys = append(keys, key, channelKey(strings.TrimPrefix(key, guildChannelKey(guildID, ""))))
+		}
+	}
+	c.delete(ctx, keys...)
+}
+
+// scan 列出以 prefix 开头的 key，失败时只记录日志
+func (c *Cache) scan(ctx context.Context, scanner KeyScanner, prefix string) []string {
+	keys, err := scanner.Keys(ctx, prefix)
+	if err != nil {
+		log.Warnf("[state] list keys %s failed, %v", prefix, err)
+	}
+	return keys
+}
+
+// delete 删除缓存，失败时只记录日志
+func (c *Cache) delete(ctx context.Context, keys ...string) {
+	if err := c.backend.Delete(ctx, keys...); err != nil {
+		log.Warnf("[state] delete %v failed, %v", keys, err)
+	}
+}
+
+func guildKey(guildID string) string {
+	return "guild:" + guildID
+}
+
+func channelKey(channelID string) string {
+	return "channel:" + channelID
+}
+
+func channelsKey(guildID string) string {
+	return "channels:" + guildID
+}
+
+// guildChannelKey 记录子频道所属的频道，值为子频道 ID
+func guildChannelKey(guildID, channelID string) string {
+	return "guild_channel:" + guildID + ":" + channelID
+}
+
+func memberKey(guildID, userID string) string {
+	return "member:" + guildID + ":" + userID
+}
+
+func rolesKey(guildID string) string {
+	return "roles:" + guildID
+}
@@ -0,0 +1,127 @@
+package state
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tencent-connect/botgo/dto"
+	"github.com/tencent-connect/botgo/event"
+	"github.com/tencent-connect/botgo/openapi/fake"
+)
+
+func dispatch(t *testing.T, d *event.Dispatcher, eventType dto.EventType, data string) {
+	t.Helper()
+	payload := &dto.WSPayload{
+		WSPayloadBase: dto.WSPayloadBase{OPCode: dto.WSDispatchEvent, Type: eventType},
+		RawMessage:    []byte(`{"op":0,"t":"` + string(eventType) + `","d":` + data + `}`),
+	}
+	assert.Nil(t, d.ParseAndHandle(context.Background(), payload))
+}
+
+func TestCache_Lazy(t *testing.T) {
+	ctx := context.Background()
+	api := fake.New()
+	api.AddGuild(&dto.Guild{ID: "guild", Name: "guild"})
+	api.AddChannel(&dto.Channel{ID: "channel", GuildID: "guild"})
+	api.AddMember("guild", &dto.Member{User: &dto.User{ID: "user"}, Roles: []string{"2"}})
+	c := New(WithOpenAPI(api))
+
+	for i := 0; i < 2; i++ {
+		guild, err := c.Guild(ctx, "guild")
+		assert.Nil(t, err)
+		assert.Equal(t, "guild", guild.Name)
+		channels, err := c.Channels(ctx, "guild")
+		assert.Nil(t, err)
+		assert.Len(t, channels, 1)
+		roles, err := c.MemberRoles(ctx, "guild", "user")
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"2"}, roles)
+	}
+	assert.Len(t, api.Calls("Guild"), 1)
+	assert.Len(t, api.Calls("Channels"), 1)
+	assert.Len(t, api.Calls("GuildMember"), 1)
+
+	_, err := c.Guild(ctx, "unknown")
+	assert.NotNil(t, err)
+	_, err = New().Guild(ctx, "guild")
+	assert.Equal(t, ErrNotCached, err)
+}
+
+func TestCache_Events(t *testing.T) {
+	ctx := context.Background()
+	c := New()
+	d := event.NewDispatcher()
+	d.RegisterHandlers(c.Handlers()...)
+
+	dispatch(t, d, dto.EventGuildCreate, `{"id":"guild","name":"old","channels":[{"id":"c1","guild_id":"guild"}]}`)
+	dispatch(t, d, dto.EventGuildUpdate, `{"id":"guild","name":"new"}`)
+	guild, err := c.Guild(ctx, "guild")
+	assert.Nil(t, err)
+	assert.Equal(t, "new", guild.Name)
+	channels, err := c.Channels(ctx, "guild")
+	assert.Nil(t, err)
+	assert.Len(t, channels, 1)
+
+	dispatch(t, d, dto.EventChannelUpdate, `{"id":"c1","guild_id":"guild","name":"general"}`)
+	channel, err := c.Channel(ctx, "c1")
+	assert.Nil(t, err)
+	assert.Equal(t, "general", channel.Name)
+	// 子频道变化后子频道列表失效
+	_, err = c.Channels(ctx, "guild")
+	assert.Equal(t, ErrNotCached, err)
+	dispatch(t, d, dto.EventChannelDelete, `{"id":"c1","guild_id":"guild"}`)
+	_, err = c.Channel(ctx, "c1")
+	assert.Equal(t, ErrNotCached, err)
+
+	dispatch(t, d, dto.EventGuildMemberAdd, `{"guild_id":"guild","user":{"id":"user"},"roles":["1"]}`)
+	dispatch(t, d, dto.EventGuildMemberUpdate, `{"guild_id":"guild","user":{"id":"user"},"roles":["1","5"]}`)
+	roles, err := c.MemberRoles(ctx, "guild", "user")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"1", "5"}, roles)
+	dispatch(t, d, dto.EventGuildMemberRemove, `{"guild_id":"guild","user":{"id":"user"}}`)
+	_, err = c.Member(ctx, "guild", "user")
+	assert.Equal(t, ErrNotCached, err)
+
+	// 频道被删除时，频道下的子频道与成员一并清理
+	dispatch(t, d, dto.EventChannelCreate, `{"id":"c2","guild_id":"guild"}`)
+	dispatch(t, d, dto.EventGuildMemberAdd, `{"guild_id":"guild","user":{"id":"user"}}`)
+	dispatch(t, d, dto.EventGuildMemberAdd, `{"guild_id":"other","user":{"id":"user"}}`)
+	dispatch(t, d, dto.EventGuildDelete, `{"id":"guild"}`)
+	_, err = c.Guild(ctx, "guild")
+	assert.Equal(t, ErrNotCached, err)
+	_, err = c.Channel(ctx, "c2")
+	assert.Equal(t, ErrNotCached, err)
+	_, err = c.Member(ctx, "guild", "user")
+	assert.Equal(t, ErrNotCached, err)
+	_, err = c.Member(ctx, "other", "user")
+	assert.Nil(t, err)
+}
+
+func TestMemoryBackend(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	b := NewMemoryBackend(2)
+	b.now = func() time.Time { return now }
+
+	assert.Nil(t, b.Set(ctx, "a", []byte("a"), time.Minute))
+	assert.Nil(t, b.Set(ctx, "b", []byte("b"), 0))
+	_, _ = b.Get(ctx, "a")
+	// 超过容量时淘汰最久没有使用的 b
+	assert.Nil(t, b.Set(ctx, "c", []byte("c"), 0))
+	value, _ := b.Get(ctx, "b")
+	assert.Nil(t, value)
+	value, _ = b.Get(ctx, "a")
+	assert.Equal(t, []byte("a"), value)
+
+	now = now.Add(time.Minute)
+	value, _ = b.Get(ctx, "a")
+	assert.Nil(t, value)
+	assert.Equal(t, 1, b.Len())
+	keys, err := b.Keys(ctx, "c")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"c"}, keys)
+	assert.Nil(t, b.Delete(ctx, "c", "unknown"))
+	assert.Equal(t, 0, b.Len())
+}
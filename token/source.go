@@ -0,0 +1,205 @@
+package token
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/tencent-connect/botgo/log"
+)
+
+// DefaultAppAccessTokenURL 默认的 AppAccessToken 获取地址
+const DefaultAppAccessTokenURL = "https://bots.qq.com/app/getAppAccessToken"
+
+const (
+	// 平台在 token 过期前 60s 内才会下发新的 token，所以默认在过期前 50s 开始刷新
+	defaultRefreshAhead = 50 * time.Second
+	// 刷新失败后的重试间隔
+	defaultRetryInterval = 5 * time.Second
+)
+
+// TokenSource 提供 access token 的来源，实现方需要保证并发安全
+type TokenSource interface {
+	// AccessToken 返回当前有效的 access token
+	AccessToken() (string, error)
+}
+
+// SourceOption AppAccessTokenSource 的可选配置
+type SourceOption func(s *AppAccessTokenSource)
+
+// WithEndpoint 自定义获取 access token 的地址
+func WithEndpoint(endpoint string) SourceOption {
+	return func(s *AppAccessTokenSource) {
+		s.endpoint = endpoint
+	}
+}
+
+// WithHTTPClient 自定义获取 access token 使用的 http client
+func WithHTTPClient(client *http.Client) SourceOption {
+	return func(s *AppAccessTokenSource) {
+		s.client = client
+	}
+}
+
+// WithRefreshAhead 自定义提前刷新的时间，token 剩余有效期小于该值时会重新获取
+func WithRefreshAhead(duration time.Duration) SourceOption {
+	return func(s *AppAccessTokenSource) {
+		s.refreshAhead = duration
+	}
+}
+
+// AppAccessTokenSource 基于 AppID + AppSecret 调用 getAppAccessToken 获取 access token，并缓存到过期前
+type AppAccessTokenSource struct {
+	appID        uint64
+	secret       string
+	endpoint     string
+	client       *http.Client
+	refreshAhead time.Duration
+
+	// refreshLock 保证同一时间只有一个刷新请求，等待中的调用方复用这次刷新的结果
+	refreshLock sync.Mutex
+	lock        sync.RWMutex
+	accessToken string
+	expireAt    time.Time
+	// generation 每次刷新成功后加一，用于判断等待 refreshLock 期间是否已经有其他调用方刷新过
+	generation uint64
+}
+
+// NewAppAccessTokenSource 创建一个 AppAccessTokenSource
+func NewAppAccessTokenSource(appID uint64, secret string, opts ...SourceOption) *AppAccessTokenSource {
+	s := &AppAccessTokenSource{
+		appID:        appID,
+		secret:       secret,
+		endpoint:     DefaultAppAccessTokenURL,
+		client:       &http.Client{Timeout: 10 * time.Second},
+		refreshAhead: defaultRefreshAhead,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+type appAccessTokenReq struct {
+	AppID        string `json:"appId"`
+	ClientSecret string `json:"clientSecret"`
+}
+
+type appAccessTokenRsp struct {
+	AccessToken string      `json:"access_token"`
+	ExpiresIn   json.Number `json:"expires_in"` // 平台返回的是字符串格式的秒数
+}
+
+// AccessToken 返回缓存的 access token，缓存即将过期时同步刷新
+func (s *AppAccessTokenSource) AccessToken() (string, error) {
+	s.lock.RLock()
+	accessToken, expireAt, generation := s.accessToken, s.expireAt, s.generation
+	s.lock.RUnlock()
+	if accessToken != "" && time.Until(expireAt) > s.refreshAhead {
+		return accessToken, nil
+	}
+	return s.refresh(context.Background(), generation)
+}
+
+// Refresh 立即从服务端获取新的 access token 并更新缓存，
+// 并发调用时只会发起一次请求，其他调用方等待并返回这次刷新的结果
+func (s *AppAccessTokenSource) Refresh(ctx context.Context) (string, error) {
+	s.lock.RLock()
+	generation := s.generation
+	s.lock.RUnlock()
+	return s.refresh(ctx, generation)
+}
+
+// refresh 在 generation 之后没有其他调用方刷新过时，从服务端获取新的 access token
+func (s *AppAccessTokenSource) refresh(ctx context.Context, generation uint64) (string, error) {
+	s.refreshLock.Lock()
+	defer s.refreshLock.Unlock()
+	s.lock.RLock()
+	accessToken, refreshed := s.accessToken, s.generation != generation
+	s.lock.RUnlock()
+	if refreshed {
+		return accessToken, nil
+	}
+	accessToken, expiresIn, err := s.fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+	s.lock.Lock()
+	s.accessToken = accessToken
+	s.expireAt = time.Now().Add(time.Duration(expiresIn) * time.Second)
+	s.generation++
+	s.lock.Unlock()
+	log.Infof("[token] app access token refreshed, expires in %ds", expiresIn)
+	return accessToken, nil
+}
+
+// fetch 调用 getAppAccessToken 获取 access token 与有效期秒数
+func (s *AppAccessTokenSource) fetch(ctx context.Context) (string, int64, error) {
+	body, _ := json.Marshal(appAccessTokenReq{
+		AppID:        strconv.FormatUint(s.appID, 10),
+		ClientSecret: s.secret,
+	})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", 0, fmt.Errorf("get app access token failed, status: %s", resp.Status)
+	}
+	rsp := &appAccessTokenRsp{}
+	if err = json.NewDecoder(resp.Body).Decode(rsp); err != nil {
+		return "", 0, err
+	}
+	if rsp.AccessToken == "" {
+		return "", 0, fmt.Errorf("get app access token failed, empty access token")
+	}
+	expiresIn, err := rsp.ExpiresIn.Int64()
+	if err != nil {
+		return "", 0, fmt.Errorf("get app access token failed, invalid expires_in: %v", rsp.ExpiresIn)
+	}
+	return rsp.AccessToken, expiresIn, nil
+}
+
+// StartRefresh 开始后台刷新任务，在 token 过期前自动获取新的 token，需要放到 goroutine 中执行，ctx 结束后退出
+func (s *AppAccessTokenSource) StartRefresh(ctx context.Context) {
+	var wait time.Duration
+	for {
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			log.Infof("[token] context done, stop refresh app access token")
+			return
+		case <-timer.C:
+		}
+		if _, err := s.Refresh(ctx); err != nil {
+			log.Errorf("[token] refresh app access token failed, err: %v", err)
+			wait = defaultRetryInterval
+			continue
+		}
+		wait = s.nextRefreshAfter()
+	}
+}
+
+// nextRefreshAfter 计算距离下一次刷新的时间
+func (s *AppAccessTokenSource) nextRefreshAfter() time.Duration {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	wait := time.Until(s.expireAt) - s.refreshAhead
+	// 服务端在刷新窗口内可能返回旧的 token，避免短时间内频繁请求
+	if wait < time.Second {
+		wait = time.Second
+	}
+	return wait
+}
@@ -0,0 +1,91 @@
+package token
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppAccessTokenSource(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := &appAccessTokenReq{}
+		_ = json.NewDecoder(r.Body).Decode(req)
+		assert.Equal(t, "1024", req.AppID)
+		assert.Equal(t, "secret", req.ClientSecret)
+		n := atomic.AddInt32(&calls, 1)
+		_, _ = fmt.Fprintf(w, `{"access_token":"token-%d","expires_in":"7200"}`, n)
+	}))
+	defer server.Close()
+
+	source := NewAppAccessTokenSource(1024, "secret", WithEndpoint(server.URL))
+	tk := QQBotToken(1024, source)
+
+	t.Run("fetch and cache", func(t *testing.T) {
+		assert.Equal(t, "token-1", tk.GetString())
+		assert.Equal(t, "token-1", tk.GetString())
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+	t.Run("refresh when expiring", func(t *testing.T) {
+		source.expireAt = time.Now().Add(10 * time.Second)
+		accessToken, err := tk.GetAccessToken()
+		assert.Nil(t, err)
+		assert.Equal(t, "token-2", accessToken)
+	})
+	t.Run("bot token without source", func(t *testing.T) {
+		assert.Equal(t, "1024.abc", BotToken(1024, "abc").GetString())
+	})
+}
+
+type errSource struct{}
+
+func (errSource) AccessToken() (string, error) {
+	return "", errors.New("refresh failed")
+}
+
+func TestToken_SourceError(t *testing.T) {
+	tk := QQBotToken(1024, errSource{})
+	tk.AccessToken = "static"
+	_, err := tk.GetAccessToken()
+	assert.EqualError(t, err, "refresh failed")
+	_, err = tk.AuthString()
+	assert.EqualError(t, err, "refresh failed")
+	assert.Equal(t, "", tk.GetString())
+}
+
+func TestAppAccessTokenSource_ConcurrentRefresh(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		time.Sleep(50 * time.Millisecond)
+		_, _ = fmt.Fprintf(w, `{"access_token":"token-%d","expires_in":"7200"}`, n)
+	}))
+	defer server.Close()
+
+	source := NewAppAccessTokenSource(1024, "secret", WithEndpoint(server.URL))
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			accessToken, err := source.AccessToken()
+			assert.Nil(t, err)
+			assert.Equal(t, "token-1", accessToken)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	accessToken, err := source.Refresh(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, "token-2", accessToken)
+}
@@ -16,6 +16,8 @@ type Type string
 const (
 	TypeBot    Type = "Bot"
 	TypeNormal Type = "Bearer"
+	// TypeQQBot 使用 AppID + AppSecret 换取的 access token
+	TypeQQBot Type = "QQBot"
 )
 
 // Token 用于调用接口的 token 结构
@@ -23,6 +25,8 @@ type Token struct {
 	AppID       uint64
 	AccessToken string
 	Type        Type
+
+	source TokenSource // 设置后 access token 从 source 获取，AccessToken 字段不再生效
 }
 
 // New 创建一个新的 Token
@@ -50,12 +54,52 @@ func UserToken(appID uint64, accessToken string) *Token {
 	}
 }
 
-// GetString 获取授权头字符串
+// QQBotToken 使用 AppAccessToken 鉴权的机器人 token，access token 由 source 负责获取和刷新
+func QQBotToken(appID uint64, source TokenSource) *Token {
+	return &Token{
+		AppID:  appID,
+		Type:   TypeQQBot,
+		source: source,
+	}
+}
+
+// SetSource 设置 access token 的来源
+func (t *Token) SetSource(source TokenSource) {
+	t.source = source
+}
+
+// Source 获取 access token 的来源，未设置时返回 nil
+func (t *Token) Source() TokenSource {
+	return t.source
+}
+
+// GetAccessToken 获取当前有效的 access token，如果设置了 source，则从 source 中获取，获取失败时返回 source 的错误
+func (t *Token) GetAccessToken() (string, error) {
+	if t.source == nil {
+		return t.AccessToken, nil
+	}
+	return t.source.AccessToken()
+}
+
+// AuthString 获取授权头字符串，access token 获取失败时返回错误
+func (t *Token) AuthString() (string, error) {
+	accessToken, err := t.GetAccessToken()
+	if err != nil {
+		return "", err
+	}
+	if t.Type == TypeNormal || t.Type == TypeQQBot {
+		return accessToken, nil
+	}
+	return fmt.Sprintf("%v.%s", t.AppID, accessToken), nil
+}
+
+// GetString 获取授权头字符串，access token 获取失败时返回空字符串，需要处理错误时使用 AuthString
 func (t *Token) GetString() string {
-	if t.Type == TypeNormal {
-		return t.AccessToken
+	s, err := t.AuthString()
+	if err != nil {
+		log.Errorf("get access token from source failed, err: %v", err)
 	}
-	return fmt.Sprintf("%v.%s", t.AppID, t.AccessToken)
+	return s
 }
 
 // LoadFromConfig 从配置中读取 appid 和 token
@@ -2,10 +2,13 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"os/signal"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -14,23 +17,47 @@ import (
 	"github.com/tencent-connect/botgo/errs"
 	"github.com/tencent-connect/botgo/event"
 	"github.com/tencent-connect/botgo/log"
+	"github.com/tencent-connect/botgo/token"
 	"github.com/tencent-connect/botgo/websocket"
 )
 
-// DefaultQueueSize 监听队列的缓冲长度
+// DefaultQueueSize 监听队列的缓冲长度，可以通过 event.Dispatcher 的 WithQueueSize 修改
 const DefaultQueueSize = 10000
 
+// closeWriteWait 发送 close frame 的超时时间
+const closeWriteWait = time.Second
+
+// storeTimeout 读写 session store 的超时时间
+const storeTimeout = 3 * time.Second
+
+// SessionSaveInterval 保存 session 状态的间隔，间隔内 seq 的更新合并为一次保存，小于等于 0 时使用 1s
+// 收到 READY、RESUMED 事件与连接退出时立即保存，保存在后台协程中执行，不阻塞事件的读取与分发
+var SessionSaveInterval = time.Second
+
+// MaxMissedHeartbeats 连续多少次心跳没有收到 ack 时认为连接已经失效，关闭连接并 resume，小于等于 0 时不检测
+var MaxMissedHeartbeats = 3
+
 // Setup 依赖注册
 func Setup() {
 	websocket.Register(&Client{})
 }
 
 // New 新建一个连接对象
-func (c *Client) New(session dto.Session) websocket.WebSocket {
+func (c *Client) New(session dto.Session, dispatcher *event.Dispatcher) websocket.WebSocket {
+	if dispatcher == nil {
+		dispatcher = event.DefaultDispatcher
+	}
+	queueSize := dispatcher.QueueSize()
+	if queueSize <= 0 {
+		queueSize = DefaultQueueSize
+	}
 	return &Client{
-		messageQueue:    make(messageChan, DefaultQueueSize),
+		messageQueue:    make(messageChan, queueSize),
 		session:         &session,
+		dispatcher:      dispatcher,
 		closeChan:       make(closeErrorChan, 10),
+		handleDone:      make(chan struct{}),
+		saveNow:         make(chan struct{}, 1),
 		heartBeatTicker: time.NewTicker(60 * time.Second), // 先给一个默认 ticker，在收到 hello 包之后，会 reset
 	}
 }
@@ -41,9 +68,22 @@ type Client struct {
 	conn            *wss.Conn
 	messageQueue    messageChan
 	session         *dto.Session
+	sessionLock     sync.RWMutex // 保护 session，ready 与 seq 的更新和心跳、日志等读取在不同的协程
 	user            *dto.WSUser
+	dispatcher      *event.Dispatcher
 	closeChan       closeErrorChan
-	heartBeatTicker *time.Ticker // 用于维持定时心跳
+	heartBeatTicker *time.Ticker  // 用于维持定时心跳
+	handleDone      chan struct{} // 事件队列处理完成后关闭
+	closeOnce       sync.Once
+	closing         int32                  // 是否由客户端主动关闭
+	store           websocket.SessionStore // 持久化 session 状态，为空时不保存
+	saveDirty       int32                  // session 有还没有保存的更新
+	saveNow         chan struct{}          // 通知保存协程立即保存
+	heartbeatSentAt int64                  // 最近一次发送心跳的时间，UnixNano
+	heartbeatAckAt  int64                  // 最近一次收到心跳 ack 的时间，UnixNano
+	latency         int64                  // 最近一次心跳的延迟，纳秒
+	missedAcks      int32                  // 连续没有收到 ack 的心跳数量
+	readBlocked     int32                  // 事件队列已满，读协程阻塞在投递事件上
 }
 
 type messageChan chan *dto.WSPayload
@@ -51,17 +91,18 @@ type closeErrorChan chan error
 
 // Connect 连接到 websocket
 func (c *Client) Connect() error {
-	if c.session.URL == "" {
+	url := c.currentSession().URL
+	if url == "" {
 		return errs.ErrURLInvalid
 	}
 
 	var err error
-	c.conn, _, err = wss.DefaultDialer.Dial(c.session.URL, nil)
+	c.conn, _, err = wss.DefaultDialer.Dial(url, nil)
 	if err != nil {
-		log.Errorf("%s, connect err: %v", c.session, err)
+		log.Errorf("%s, connect err: %v", c, err)
 		return err
 	}
-	log.Infof("%s, url %s, connected", c.session, c.session.URL)
+	log.Infof("%s, url %s, connected", c, url)
 
 	return nil
 }
@@ -69,11 +110,22 @@ func (c *Client) Connect() error {
 // Listening 开始监听，会阻塞进程，内部会从事件队列不断的读取事件，解析后投递到注册的 event handler，如果读取消息过程中发生错误，会循环
 // 定时心跳也在这里维护
 func (c *Client) Listening() error {
-	defer c.Close()
+	defer c.release()
+	// 连接级别的 context，Listening 退出时取消，通知正在处理事件的 handler
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// 后台保存 session 状态，退出前保存最后的状态
+	saveDone := make(chan struct{})
+	go c.saveSessionLoop(ctx, saveDone)
+	defer func() {
+		cancel()
+		<-saveDone
+		c.flushSession()
+	}()
 	// reading message
 	go c.readMessageToQueue()
 	// read message from queue and handle,in goroutine to avoid business logic block closeChan and heartBeatTicker
-	go c.listenMessageAndHandle()
+	go c.listenMessageAndHandle(ctx)
 
 	// 接收 resume signal
 	resumeSignal := make(chan os.Signal, 1)
@@ -85,46 +137,97 @@ func (c *Client) Listening() error {
 	for {
 		select {
 		case <-resumeSignal: // 使用信号量控制连接立即重连
-			log.Infof("%s, received resumeSignal signal", c.session)
+			log.Infof("%s, received resumeSignal signal", c)
 			return errs.ErrNeedReConnect
 		case err := <-c.closeChan:
+			// 客户端主动关闭，等待已经收到的事件处理完成后退出
+			if atomic.LoadInt32(&c.closing) == 1 {
+				log.Infof("%s closed by client, waiting for queued events, err is %v", c, err)
+				<-c.handleDone
+				return errs.ErrConnClosed
+			}
 			// 关闭连接的错误码 https://bot.q.qq.com/wiki/develop/api/gateway/error/error.html
-			log.Errorf("%s Listening stop. err is %v", c.session, err)
+			log.Errorf("%s Listening stop. err is %v", c, err)
 			// 不能够 identify 的错误
 			if wss.IsCloseError(err, 4914, 4915) {
-				err = errs.New(errs.CodeConnCloseCantIdentify, err.Error())
+				err = errs.Wrap(errs.CodeConnCloseCantIdentify, err)
 			}
 			// 这里用 UnexpectedCloseError，如果有需要排除在外的 close error code，可以补充在第二个参数上
 			// 4009: session time out, 发了 reconnect 之后马上关闭连接时候的错误码，这个是允许 resumeSignal 的
 			if wss.IsUnexpectedCloseError(err, 4009) {
-				err = errs.New(errs.CodeConnCloseCantResume, err.Error())
-			}
-			if event.DefaultHandlers.ErrorNotify != nil {
-				// 通知到使用方错误
-				event.DefaultHandlers.ErrorNotify(err)
+				err = errs.Wrap(errs.CodeConnCloseCantResume, err)
 			}
+			// 通知到使用方错误
+			c.dispatcher.NotifyError(err)
 			return err
 		case <-c.heartBeatTicker.C:
-			log.Debugf("%s listened heartBeat", c.session)
-			heartBeatEvent := &dto.WSPayload{
-				WSPayloadBase: dto.WSPayloadBase{
-					OPCode: dto.WSHeartbeat,
-				},
-				Data: c.session.LastSeq,
+			if err := c.heartbeat(); err != nil {
+				c.dispatcher.NotifyError(err)
+				return err
 			}
-			// 不处理错误，Write 内部会处理，如果发生发包异常，会通知主协程退出
-			_ = c.Write(heartBeatEvent)
 		}
 	}
 }
 
+// heartbeat 发送心跳，连续 MaxMissedHeartbeats 次心跳没有收到 ack 时，认为连接已经失效，返回 errs.ErrHeartbeatTimeout
+// 失效的连接可能是半开的 tcp 连接，不会收到任何错误，需要关闭后 resume
+// 事件队列已满时读协程阻塞，ack 留在连接的缓冲中无法读取，此时不统计没有收到 ack 的心跳，避免把背压误判为连接失效
+func (c *Client) heartbeat() error {
+	log.Debugf("%s listened heartBeat", c)
+	blocked := atomic.LoadInt32(&c.readBlocked) == 1
+	missed := atomic.LoadInt32(&c.missedAcks)
+	if blocked {
+		log.Warnf("%s message queue is full, skip heartbeat ack check", c)
+	} else if MaxMissedHeartbeats > 0 && int(missed) >= MaxMissedHeartbeats {
+		log.Errorf("%s %d heartbeats not acknowledged, last ack at %s, close zombie connection",
+			c, missed, c.LastHeartbeatAck())
+		return errs.ErrHeartbeatTimeout
+	}
+	heartBeatEvent := &dto.WSPayload{
+		WSPayloadBase: dto.WSPayloadBase{
+			OPCode: dto.WSHeartbeat,
+		},
+		Data: c.currentSession().LastSeq,
+	}
+	atomic.StoreInt64(&c.heartbeatSentAt, time.Now().UnixNano())
+	if !blocked {
+		atomic.AddInt32(&c.missedAcks, 1)
+	}
+	// 不处理错误，Write 内部会处理，如果发生发包异常，会通知主协程退出
+	_ = c.Write(heartBeatEvent)
+	return nil
+}
+
+// heartbeatAck 收到心跳 ack，记录时间与延迟
+func (c *Client) heartbeatAck(receivedAt time.Time) {
+	atomic.StoreInt32(&c.missedAcks, 0)
+	atomic.StoreInt64(&c.heartbeatAckAt, receivedAt.UnixNano())
+	if sentAt := atomic.LoadInt64(&c.heartbeatSentAt); sentAt > 0 {
+		atomic.StoreInt64(&c.latency, receivedAt.UnixNano()-sentAt)
+	}
+}
+
+// Latency 最近一次心跳到收到 ack 的延迟，还没有收到 ack 时为 0
+func (c *Client) Latency() time.Duration {
+	return time.Duration(atomic.LoadInt64(&c.latency))
+}
+
+// LastHeartbeatAck 最近一次收到心跳 ack 的时间，还没有收到 ack 时为零值
+func (c *Client) LastHeartbeatAck() time.Time {
+	ackAt := atomic.LoadInt64(&c.heartbeatAckAt)
+	if ackAt == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ackAt)
+}
+
 // Write 往 ws 写入数据
 func (c *Client) Write(message *dto.WSPayload) error {
 	m, _ := json.Marshal(message)
-	log.Infof("%s write %s message, %v", c.session, dto.OPMeans(message.OPCode), string(m))
+	log.Infof("%s write %s message, %v", c, dto.OPMeans(message.OPCode), string(m))
 
 	if err := c.conn.WriteMessage(wss.TextMessage, m); err != nil {
-		log.Errorf("%s WriteMessage failed, %v", c.session, err)
+		log.Errorf("%s WriteMessage failed, %v", c, err)
 		c.closeChan <- err
 		return err
 	}
@@ -133,11 +236,16 @@ func (c *Client) Write(message *dto.WSPayload) error {
 
 // Resume 重连
 func (c *Client) Resume() error {
+	session := c.currentSession()
+	authToken, err := identifyToken(&session.Token)
+	if err != nil {
+		return err
+	}
 	payload := &dto.WSPayload{
 		Data: &dto.WSResumeData{
-			Token:     c.session.Token.GetString(),
-			SessionID: c.session.ID,
-			Seq:       c.session.LastSeq,
+			Token:     authToken,
+			SessionID: session.ID,
+			Seq:       session.LastSeq,
 		},
 	}
 	payload.OPCode = dto.WSResume // 内嵌结构体字段，单独赋值
@@ -147,16 +255,23 @@ func (c *Client) Resume() error {
 // Identify 对一个连接进行鉴权，并声明监听的 shard 信息
 func (c *Client) Identify() error {
 	// 避免传错 intent
-	if c.session.Intent == 0 {
-		c.session.Intent = dto.IntentGuilds
+	c.updateSession(func(s *dto.Session) {
+		if s.Intent == 0 {
+			s.Intent = dto.IntentGuilds
+		}
+	})
+	session := c.currentSession()
+	authToken, err := identifyToken(&session.Token)
+	if err != nil {
+		return err
 	}
 	payload := &dto.WSPayload{
 		Data: &dto.WSIdentityData{
-			Token:   c.session.Token.GetString(),
-			Intents: c.session.Intent,
+			Token:   authToken,
+			Intents: session.Intent,
 			Shard: []uint32{
-				c.session.Shards.ShardID,
-				c.session.Shards.ShardCount,
+				session.Shards.ShardID,
+				session.Shards.ShardCount,
 			},
 		},
 	}
@@ -164,52 +279,128 @@ func (c *Client) Identify() error {
 	return c.Write(payload)
 }
 
-// Close 关闭连接
+// identifyToken 鉴权与重连时使用的 token，每次都重新获取，避免使用已经过期的 access token
+// QQBot 类型的 token 需要携带类型前缀
+func identifyToken(t *token.Token) (string, error) {
+	authToken, err := t.AuthString()
+	if err != nil {
+		return "", err
+	}
+	if t.Type == token.TypeQQBot {
+		return fmt.Sprintf("%s %s", t.Type, authToken), nil
+	}
+	return authToken, nil
+}
+
+// Close 关闭连接，会向服务端发送 close frame，Listening 会在处理完队列中已经收到的事件后返回 errs.ErrConnClosed
 func (c *Client) Close() {
-	if err := c.conn.Close(); err != nil {
-		log.Errorf("%s, close conn err: %v", c.session, err)
+	atomic.StoreInt32(&c.closing, 1)
+	c.closeOnce.Do(func() {
+		if c.conn != nil {
+			msg := wss.FormatCloseMessage(wss.CloseNormalClosure, "")
+			if err := c.conn.WriteControl(wss.CloseMessage, msg, time.Now().Add(closeWriteWait)); err != nil {
+				log.Errorf("%s, write close message err: %v", c, err)
+			}
+		}
+		c.closeConn()
+	})
+}
+
+// release 释放连接，不发送 close frame，用于连接异常后的重连，保证 session 可以 resume
+func (c *Client) release() {
+	c.closeOnce.Do(c.closeConn)
+}
+
+func (c *Client) closeConn() {
+	if c.conn != nil {
+		if err := c.conn.Close(); err != nil {
+			log.Errorf("%s, close conn err: %v", c, err)
+		}
 	}
 	c.heartBeatTicker.Stop()
 }
 
-// Session 获取client的session信息
+// SetSessionStore 设置持久化 session 状态的 store，需要在 Connect 之前调用
+func (c *Client) SetSessionStore(store websocket.SessionStore) {
+	c.store = store
+}
+
+// Session 获取client的session信息，返回的是当前 session 的拷贝
 func (c *Client) Session() *dto.Session {
-	return c.session
+	session := c.currentSession()
+	return &session
+}
+
+// String 返回连接的 session 信息，用于日志
+func (c *Client) String() string {
+	session := c.currentSession()
+	return session.String()
+}
+
+// currentSession 返回当前 session 的拷贝
+func (c *Client) currentSession() dto.Session {
+	c.sessionLock.RLock()
+	defer c.sessionLock.RUnlock()
+	return *c.session
+}
+
+// updateSession 在锁内修改 session
+func (c *Client) updateSession(update func(s *dto.Session)) {
+	c.sessionLock.Lock()
+	defer c.sessionLock.Unlock()
+	update(c.session)
 }
 
 func (c *Client) readMessageToQueue() {
 	for {
 		_, message, err := c.conn.ReadMessage()
 		if err != nil {
-			log.Errorf("%s read message failed, %v, message %s", c.session, err, string(message))
+			log.Errorf("%s read message failed, %v, message %s", c, err, string(message))
 			close(c.messageQueue)
 			c.closeChan <- err
 			return
 		}
 		payload := &dto.WSPayload{}
 		if err := json.Unmarshal(message, payload); err != nil {
-			log.Errorf("%s json failed, %v", c.session, err)
+			log.Errorf("%s json failed, %v", c, err)
 			continue
 		}
 		payload.RawMessage = message
-		log.Infof("%s receive %s message, %s", c.session, dto.OPMeans(payload.OPCode), string(message))
+		payload.ReceivedAt = time.Now()
+		log.Infof("%s receive %s message, %s", c, dto.OPMeans(payload.OPCode), string(message))
 		// 处理内置的一些事件，如果处理成功，则这个事件不再投递给业务
 		if c.isHandleBuildIn(payload) {
 			continue
 		}
-		c.messageQueue <- payload
+		c.enqueue(payload)
 	}
 }
 
-func (c *Client) listenMessageAndHandle() {
-	defer func() {
-		// panic，一般是由于业务自己实现的 handle 不完善导致
-		// 打印日志后，关闭这个连接，进入重连流程
-		if err := recover(); err != nil {
-			websocket.PanicHandler(err, c.session)
-			c.closeChan <- fmt.Errorf("panic: %v", err)
-		}
-	}()
+// enqueue 投递事件到队列，队列已满时阻塞，将背压传递到连接上
+// 阻塞期间无法读取心跳 ack，通过 readBlocked 暂停 ack 检测，恢复读取后重新计数
+func (c *Client) enqueue(payload *dto.WSPayload) {
+	select {
+	case c.messageQueue <- payload:
+		return
+	default:
+	}
+	log.Warnf("%s message queue is full, stop reading until events are handled", c)
+	atomic.StoreInt32(&c.readBlocked, 1)
+	c.messageQueue <- payload
+	// 连接上能够继续读到数据，说明连接仍然有效
+	atomic.StoreInt32(&c.missedAcks, 0)
+	atomic.StoreInt32(&c.readBlocked, 0)
+}
+
+func (c *Client) listenMessageAndHandle(ctx context.Context) {
+	defer close(c.handleDone)
+	defer c.recoverHandle()
+	// 开启并发处理时，相同 key 的事件由同一个 worker 按顺序处理，处理池的容量用尽时阻塞，不再消费 messageQueue
+	pool := c.dispatcher.NewPool()
+	if pool != nil {
+		// 等待已经提交给 worker 的事件处理完成
+		defer pool.Close()
+	}
 	for payload := range c.messageQueue {
 		c.saveSeq(payload.Seq)
 		// ready 事件需要特殊处理
@@ -217,17 +408,120 @@ func (c *Client) listenMessageAndHandle() {
 			c.readyHandler(payload)
 			continue
 		}
-		// 解析具体事件，并投递给业务注册的 handler
-		if err := event.ParseAndHandle(payload); err != nil {
-			log.Errorf("%s parseAndHandle failed, %v", c.session, err)
+		if payload.Type == "RESUMED" {
+			c.requestSave()
+		}
+		eventCtx := c.eventContext(ctx, payload)
+		if pool == nil {
+			c.handle(eventCtx, payload)
+			continue
+		}
+		payload := payload
+		pool.Submit(event.OrderKey(payload), func() {
+			defer c.recoverHandle()
+			c.handle(eventCtx, payload)
+		})
+	}
+	log.Infof("%s message queue is closed", c)
+}
+
+// handle 解析具体事件，并投递给业务注册的 handler
+func (c *Client) handle(ctx context.Context, payload *dto.WSPayload) {
+	if err := c.dispatcher.ParseAndHandle(ctx, payload); err != nil {
+		log.Errorf("%s parseAndHandle failed, %v", c, err)
+	}
+}
+
+// recoverHandle 处理 handler 的 panic，一般是由于业务自己实现的 handle 不完善导致
+// 打印日志后，关闭这个连接，进入重连流程
+func (c *Client) recoverHandle() {
+	if err := recover(); err != nil {
+		websocket.PanicHandler(err, c.Session())
+		select {
+		case c.closeChan <- fmt.Errorf("panic: %v", err):
+		default:
 		}
 	}
-	log.Infof("%s message queue is closed", c.session)
 }
 
+// eventContext 生成投递给 handler 的 context，携带 session，事件 ID 与收到事件的时间
+func (c *Client) eventContext(ctx context.Context, payload *dto.WSPayload) context.Context {
+	ctx = event.WithSession(ctx, c.currentSession())
+	ctx = event.WithTraceID(ctx, payload.ID)
+	return event.WithReceivedTime(ctx, payload.ReceivedAt)
+}
+
+// saveSeq 更新 seq，由保存协程按照 SessionSaveInterval 合并保存
 func (c *Client) saveSeq(seq uint32) {
 	if seq > 0 {
-		c.session.LastSeq = seq
+		c.updateSession(func(s *dto.Session) { s.LastSeq = seq })
+		atomic.StoreInt32(&c.saveDirty, 1)
+	}
+}
+
+// requestSave 通知保存协程立即保存 session 状态
+func (c *Client) requestSave() {
+	atomic.StoreInt32(&c.saveDirty, 1)
+	select {
+	case c.saveNow <- struct{}{}:
+	default:
+	}
+}
+
+// saveSessionLoop 定时保存 session 状态，store 较慢时只会推迟保存，不会阻塞事件的读取与分发
+func (c *Client) saveSessionLoop(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	if c.store == nil {
+		return
+	}
+	interval := SessionSaveInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-c.saveNow:
+		}
+		c.flushSession()
+	}
+}
+
+// flushSession session 有还没有保存的更新时保存
+func (c *Client) flushSession() {
+	if atomic.SwapInt32(&c.saveDirty, 0) == 1 {
+		c.persistSession()
+	}
+}
+
+// persistSession 保存 session 状态，失败时只记录日志
+func (c *Client) persistSession() {
+	session := c.currentSession()
+	if c.store == nil || session.ID == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	if err := c.store.Save(ctx, session); err != nil {
+		log.Errorf("%s save session failed, %v", c, err)
+	}
+}
+
+// forgetSession 删除保存的 session 状态，session 失效后不能再 resume
+func (c *Client) forgetSession() {
+	if c.store == nil {
+		return
+	}
+	// 丢弃还没有保存的更新，避免之后又保存已经失效的 session
+	atomic.StoreInt32(&c.saveDirty, 0)
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	if err := c.store.Delete(ctx, c.currentSession()); err != nil {
+		log.Errorf("%s delete session failed, %v", c, err)
 	}
 }
 
@@ -237,10 +531,12 @@ func (c *Client) isHandleBuildIn(payload *dto.WSPayload) bool {
 	switch payload.OPCode {
 	case dto.WSHello: // 接收到 hello 后需要开始发心跳
 		c.startHeartBeatTicker(payload.RawMessage)
-	case dto.WSHeartbeatAck: // 心跳 ack 不需要业务处理
+	case dto.WSHeartbeatAck: // 心跳 ack 不需要业务处理，记录延迟用于检测失效的连接
+		c.heartbeatAck(payload.ReceivedAt)
 	case dto.WSReconnect: // 达到连接时长，需要重新连接，此时可以通过 resume 续传原连接上的事件
 		c.closeChan <- errs.ErrNeedReConnect
 	case dto.WSInvalidSession: // 无效的 sessionLog，需要重新鉴权
+		c.forgetSession()
 		c.closeChan <- errs.ErrInvalidSession
 	default:
 		return false
@@ -252,7 +548,7 @@ func (c *Client) isHandleBuildIn(payload *dto.WSPayload) bool {
 func (c *Client) startHeartBeatTicker(message []byte) {
 	helloData := &dto.WSHelloData{}
 	if err := event.ParseData(message, helloData); err != nil {
-		log.Errorf("%s hello data parse failed, %v, message %v", c.session, err, message)
+		log.Errorf("%s hello data parse failed, %v, message %v", c, err, message)
 	}
 	// 根据 hello 的回包，重新设置心跳的定时器时间
 	c.heartBeatTicker.Reset(time.Duration(helloData.HeartbeatInterval) * time.Millisecond)
@@ -262,20 +558,21 @@ func (c *Client) startHeartBeatTicker(message []byte) {
 func (c *Client) readyHandler(payload *dto.WSPayload) {
 	readyData := &dto.WSReadyData{}
 	if err := event.ParseData(payload.RawMessage, readyData); err != nil {
-		log.Errorf("%s parseReadyData failed, %v, message %v", c.session, err, payload.RawMessage)
+		log.Errorf("%s parseReadyData failed, %v, message %v", c, err, payload.RawMessage)
 	}
 	c.version = readyData.Version
 	// 基于 ready 事件，更新 session 信息
-	c.session.ID = readyData.SessionID
-	c.session.Shards.ShardID = readyData.Shard[0]
-	c.session.Shards.ShardCount = readyData.Shard[1]
+	c.updateSession(func(s *dto.Session) {
+		s.ID = readyData.SessionID
+		s.Shards.ShardID = readyData.Shard[0]
+		s.Shards.ShardCount = readyData.Shard[1]
+	})
 	c.user = &dto.WSUser{
 		ID:       readyData.User.ID,
 		Username: readyData.User.Username,
 		Bot:      readyData.User.Bot,
 	}
+	c.requestSave()
 	// 调用自定义的 ready 回调
-	if event.DefaultHandlers.Ready != nil {
-		event.DefaultHandlers.Ready(payload, readyData)
-	}
+	c.dispatcher.NotifyReady(payload, readyData)
 }
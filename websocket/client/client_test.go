@@ -0,0 +1,227 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tencent-connect/botgo/dto"
+	"github.com/tencent-connect/botgo/errs"
+	"github.com/tencent-connect/botgo/event"
+	"github.com/tencent-connect/botgo/sessions/manager"
+	"github.com/tencent-connect/botgo/token"
+	"github.com/tencent-connect/botgo/websocket"
+	"github.com/tencent-connect/botgo/websocket/gatewaytest"
+)
+
+func TestClient_HeartbeatTimeout(t *testing.T) {
+	server := gatewaytest.NewServer(gatewaytest.WithHeartbeatInterval(20 * time.Millisecond))
+	defer server.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	session := dto.Session{
+		URL:    server.WebsocketURL,
+		Token:  *token.BotToken(1, "token"),
+		Shards: dto.ShardConfig{ShardCount: 1},
+	}
+	c := (&Client{}).New(session, event.NewDispatcher()).(*Client)
+	assert.Nil(t, c.Connect())
+	assert.Nil(t, c.Identify())
+	done := make(chan error, 1)
+	go func() { done <- c.Listening() }()
+
+	assert.Nil(t, server.Wait(ctx, func(s gatewaytest.Stats) bool { return s.Heartbeats >= 2 }))
+	assert.False(t, c.LastHeartbeatAck().IsZero())
+	assert.True(t, c.Latency() > 0)
+
+	// 服务端不再回复 ack，连接仍然保持，客户端需要识别失效的连接
+	server.PauseHeartbeatAck(true)
+	select {
+	case err := <-done:
+		assert.Equal(t, errs.ErrHeartbeatTimeout, err)
+		assert.False(t, manager.CanNotResume(err))
+		assert.True(t, errs.IsRetryable(err))
+	case <-ctx.Done():
+		t.Fatal("heartbeat timeout not detected")
+	}
+}
+
+func TestClient_HeartbeatBackpressure(t *testing.T) {
+	server := gatewaytest.NewServer(gatewaytest.WithHeartbeatInterval(20 * time.Millisecond))
+	defer server.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	release := make(chan struct{})
+	dispatcher := event.NewDispatcher().WithQueueSize(1)
+	dispatcher.RegisterHandlers(event.ATMessageEventHandler(func(_ *dto.WSPayload, _ *dto.WSATMessageData) error {
+		<-release
+		return nil
+	}))
+	session := dto.Session{
+		URL:    server.WebsocketURL,
+		Token:  *token.BotToken(1, "token"),
+		Shards: dto.ShardConfig{ShardCount: 1},
+	}
+	c := (&Client{}).New(session, dispatcher).(*Client)
+	assert.Nil(t, c.Connect())
+	assert.Nil(t, c.Identify())
+	done := make(chan error, 1)
+	go func() { done <- c.Listening() }()
+	assert.Nil(t, server.Wait(ctx, func(s gatewaytest.Stats) bool { return s.Ready >= 1 }))
+
+	// handler 阻塞使事件队列写满，读协程阻塞期间的心跳不能被判定为超时
+	for i := 0; i < 5; i++ {
+		server.Dispatch(dto.EventAtMessageCreate, &dto.Message{ChannelID: "1"})
+	}
+	start := server.Stats().Heartbeats
+	assert.Nil(t, server.Wait(ctx, func(s gatewaytest.Stats) bool {
+		return s.Heartbeats >= start+MaxMissedHeartbeats+2
+	}))
+	select {
+	case err := <-done:
+		t.Fatalf("connection closed under backpressure: %v", err)
+	default:
+	}
+	close(release)
+	c.Close()
+	assert.Equal(t, errs.ErrConnClosed, <-done)
+}
+
+func TestClient_Workers(t *testing.T) {
+	server := gatewaytest.NewServer()
+	defer server.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	release := make(chan struct{})
+	handled := make(chan string, 100)
+	var lock sync.Mutex
+	var slow []string
+	dispatcher := event.NewDispatcher().WithWorkers(4, 10)
+	dispatcher.RegisterHandlers(event.ATMessageEventHandler(func(_ *dto.WSPayload, data *dto.WSATMessageData) error {
+		if data.ChannelID == "slow" {
+			<-release
+			lock.Lock()
+			slow = append(slow, data.Content)
+			lock.Unlock()
+		}
+		handled <- data.ChannelID
+		return nil
+	}))
+	session := dto.Session{
+		URL:    server.WebsocketURL,
+		Token:  *token.BotToken(1, "token"),
+		Shards: dto.ShardConfig{ShardCount: 1},
+	}
+	c := (&Client{}).New(session, dispatcher).(*Client)
+	assert.Nil(t, c.Connect())
+	assert.Nil(t, c.Identify())
+	done := make(chan error, 1)
+	go func() { done <- c.Listening() }()
+	assert.Nil(t, server.Wait(ctx, func(s gatewaytest.Stats) bool { return s.Ready >= 1 }))
+
+	for i := 0; i < 3; i++ {
+		server.Dispatch(dto.EventAtMessageCreate, &dto.Message{ChannelID: "slow", Content: fmt.Sprint(i)})
+	}
+	for i := 0; i < 8; i++ {
+		server.Dispatch(dto.EventAtMessageCreate, &dto.Message{ChannelID: fmt.Sprint("fast", i)})
+	}
+	// slow 子频道的事件阻塞时，其他子频道的事件仍然可以处理
+	select {
+	case channelID := <-handled:
+		assert.NotEqual(t, "slow", channelID)
+	case <-ctx.Done():
+		t.Fatal("events are blocked by slow channel")
+	}
+	close(release)
+	for i := 1; i < 11; i++ {
+		<-handled
+	}
+	lock.Lock()
+	assert.Equal(t, []string{"0", "1", "2"}, slow)
+	lock.Unlock()
+	assert.Equal(t, uint64(11), dispatcher.PoolStats().Completed)
+
+	c.Close()
+	<-done
+	assert.Equal(t, 0, dispatcher.PoolStats().Workers)
+}
+
+// slowStore 每次保存都很慢的 store，记录保存的次数与最后保存的 seq
+type slowStore struct {
+	lock    sync.Mutex
+	saves   int
+	lastSeq uint32
+}
+
+func (s *slowStore) Load(context.Context, dto.Session) (*websocket.SessionState, error) {
+	return nil, nil
+}
+
+func (s *slowStore) Save(_ context.Context, session dto.Session) error {
+	time.Sleep(100 * time.Millisecond)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.saves++
+	s.lastSeq = session.LastSeq
+	return nil
+}
+
+func (s *slowStore) Delete(context.Context, dto.Session) error {
+	return nil
+}
+
+func TestClient_SessionSaveInterval(t *testing.T) {
+	server := gatewaytest.NewServer()
+	defer server.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	handled := make(chan struct{}, 100)
+	dispatcher := event.NewDispatcher()
+	dispatcher.RegisterHandlers(event.ATMessageEventHandler(func(_ *dto.WSPayload, _ *dto.WSATMessageData) error {
+		handled <- struct{}{}
+		return nil
+	}))
+	session := dto.Session{
+		URL:    server.WebsocketURL,
+		Token:  *token.BotToken(1, "token"),
+		Shards: dto.ShardConfig{ShardCount: 1},
+	}
+	store := &slowStore{}
+	c := (&Client{}).New(session, dispatcher).(*Client)
+	c.SetSessionStore(store)
+	assert.Nil(t, c.Connect())
+	assert.Nil(t, c.Identify())
+	done := make(chan error, 1)
+	go func() { done <- c.Listening() }()
+	assert.Nil(t, server.Wait(ctx, func(s gatewaytest.Stats) bool { return s.Ready >= 1 }))
+
+	// 保存很慢时事件的分发不会被阻塞
+	start := time.Now()
+	for i := 0; i < 50; i++ {
+		server.Dispatch(dto.EventAtMessageCreate, &dto.Message{ChannelID: "1"})
+	}
+	for i := 0; i < 50; i++ {
+		select {
+		case <-handled:
+		case <-ctx.Done():
+			t.Fatal("events are blocked by session store")
+		}
+	}
+	assert.True(t, time.Since(start) < time.Second)
+
+	// 关闭时保存最后的 seq，期间的更新被合并保存
+	c.Close()
+	assert.Equal(t, errs.ErrConnClosed, <-done)
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	assert.Equal(t, c.Session().LastSeq, store.lastSeq)
+	assert.True(t, store.lastSeq >= 50)
+	assert.True(t, store.saves < 10)
+}
@@ -0,0 +1,75 @@
+package gatewaytest
+
+import (
+	"time"
+
+	"github.com/tencent-connect/botgo/dto"
+)
+
+// 默认配置
+const (
+	DefaultHeartbeatInterval = 100 * time.Millisecond
+	DefaultBotID             = "bot"
+	DefaultBotName           = "bot"
+)
+
+// Option 模拟服务的可选配置
+type Option func(o *options)
+
+type options struct {
+	shards            uint32
+	heartbeatInterval time.Duration
+	token             string
+	botID             string
+	botName           string
+	sessionStartLimit dto.SessionStartLimit
+}
+
+func defaultOptions() *options {
+	return &options{
+		shards:            1,
+		heartbeatInterval: DefaultHeartbeatInterval,
+		botID:             DefaultBotID,
+		botName:           DefaultBotName,
+		sessionStartLimit: dto.SessionStartLimit{
+			Total: 1000, Remaining: 1000, MaxConcurrency: 1,
+		},
+	}
+}
+
+// WithShards 设置 `/gateway/bot` 返回的建议分片数
+func WithShards(shards uint32) Option {
+	return func(o *options) {
+		o.shards = shards
+	}
+}
+
+// WithHeartbeatInterval 设置 Hello 中下发的心跳间隔，默认为 100ms，方便测试快速完成
+func WithHeartbeatInterval(interval time.Duration) Option {
+	return func(o *options) {
+		o.heartbeatInterval = interval
+	}
+}
+
+// WithToken 设置鉴权时校验的 token，即客户端发送的 token 字段，如 "appid.token"
+// 未设置时不校验，校验失败时使用 4004 关闭连接
+func WithToken(token string) Option {
+	return func(o *options) {
+		o.token = token
+	}
+}
+
+// WithBot 设置 READY 事件中的机器人信息
+func WithBot(id, username string) Option {
+	return func(o *options) {
+		o.botID = id
+		o.botName = username
+	}
+}
+
+// WithSessionStartLimit 设置 `/gateway/bot` 返回的连接数限制
+func WithSessionStartLimit(limit dto.SessionStartLimit) Option {
+	return func(o *options) {
+		o.sessionStartLimit = limit
+	}
+}
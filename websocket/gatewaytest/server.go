@@ -0,0 +1,399 @@
+// Package gatewaytest 本地的 websocket 网关模拟服务，用于在没有真实网关的情况下测试 websocket client 与 session manager。
+//
+// 模拟服务提供 `/gateway/bot` 接口返回接入点信息，并实现 dto/websocket_opcode.go 中的 opcode 协议：
+// Hello、Identify、Ready、Heartbeat、Resume、Reconnect 与 InvalidSession。
+// 测试中可以向连接推送事件，要求客户端重连，或者使用指定的错误码关闭连接，如 4009、4914。
+package gatewaytest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	wss "github.com/gorilla/websocket"
+	"github.com/tencent-connect/botgo/dto"
+)
+
+// 模拟服务的路径
+const (
+	GatewayBotPath = "/gateway/bot"
+	GatewayPath    = "/gateway"
+	WebsocketPath  = "/websocket"
+)
+
+// waitPollInterval Wait 检查条件的间隔
+const waitPollInterval = 10 * time.Millisecond
+
+// sessionSeq 用于生成 session id，保证多个模拟服务之间的 session id 不重复
+var sessionSeq uint64
+
+// Stats 模拟服务收到的请求统计
+type Stats struct {
+	Connections int // 当前的连接数
+	Ready       int // 当前已经鉴权或者 resume 成功的连接数
+	Identifies  int // 收到的 Identify 次数
+	Resumes     int // 收到的 Resume 次数，包括失败的 resume
+	Heartbeats  int // 收到的心跳次数
+}
+
+// Server 本地网关模拟服务
+type Server struct {
+	// URL http 地址，可以通过 openapi.WithBaseURL 让 openapi 请求模拟服务
+	URL string
+	// WebsocketURL websocket 接入地址
+	WebsocketURL string
+
+	server   *httptest.Server
+	upgrader wss.Upgrader
+	options  *options
+
+	lock     sync.Mutex
+	seq      uint64
+	conns    map[*conn]struct{}
+	sessions map[string]*session // sessionID -> session
+	stats    Stats
+	ackPause bool // 暂停回复心跳 ack，用于模拟失效的连接
+}
+
+// session 服务端记录的 session，用于 resume 时补发事件
+type session struct {
+	id      string
+	shard   [2]uint32
+	lastSeq uint32
+	history []*dto.WSPayload
+	conn    *conn // 当前使用该 session 的连接，断开后为空
+}
+
+// conn 一个客户端连接
+type conn struct {
+	ws        *wss.Conn
+	writeLock sync.Mutex
+	session   *session // 鉴权或 resume 成功后设置
+}
+
+// NewServer 启动一个模拟服务，使用完成后需要调用 Close
+func NewServer(opts ...Option) *Server {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	s := &Server{
+		options:  o,
+		conns:    map[*conn]struct{}{},
+		sessions: map[string]*session{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(GatewayBotPath, s.handleGateway)
+	mux.HandleFunc(GatewayPath, s.handleGateway)
+	mux.HandleFunc(WebsocketPath, s.handleWebsocket)
+	s.server = httptest.NewServer(mux)
+	s.URL = s.server.URL
+	s.WebsocketURL = "ws" + strings.TrimPrefix(s.server.URL, "http") + WebsocketPath
+	return s
+}
+
+// Close 关闭所有连接，并停止模拟服务
+func (s *Server) Close() {
+	s.CloseConnections(wss.CloseGoingAway, "server closed")
+	s.server.Close()
+}
+
+// WebsocketAP 返回 `/gateway/bot` 接口的接入点信息
+func (s *Server) WebsocketAP() *dto.WebsocketAP {
+	return &dto.WebsocketAP{
+		URL:               s.WebsocketURL,
+		Shards:            s.options.shards,
+		SessionStartLimit: s.options.sessionStartLimit,
+	}
+}
+
+// Stats 返回请求统计
+func (s *Server) Stats() Stats {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.stats
+}
+
+// Wait 等待直到 cond 返回 true，ctx 结束时返回 ctx 的错误
+func (s *Server) Wait(ctx context.Context, cond func(Stats) bool) error {
+	ticker := time.NewTicker(waitPollInterval)
+	defer ticker.Stop()
+	for !cond(s.Stats()) {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// Dispatch 向所有 session 推送事件，返回推送的 session 数
+// 事件会记录到 session 中，session 当前没有连接时，客户端 resume 后会补发
+func (s *Server) Dispatch(eventType dto.EventType, data interface{}) int {
+	return s.dispatch(func(*session) bool { return true }, eventType, data)
+}
+
+// DispatchToShard 向指定 shard 的 session 推送事件，返回推送的 session 数
+func (s *Server) DispatchToShard(shardID uint32, eventType dto.EventType, data interface{}) int {
+	return s.dispatch(func(sess *session) bool { return sess.shard[0] == shardID }, eventType, data)
+}
+
+// Reconnect 向所有连接发送 op 7 Reconnect，要求客户端重连并 resume
+func (s *Server) Reconnect() {
+	s.broadcast(&dto.WSPayload{WSPayloadBase: dto.WSPayloadBase{OPCode: dto.WSReconnect}})
+}
+
+// InvalidSession 向所有连接发送 op 9 InvalidSession，并使所有 session 失效，客户端需要重新鉴权
+func (s *Server) InvalidSession() {
+	s.lock.Lock()
+	s.sessions = map[string]*session{}
+	s.lock.Unlock()
+	s.broadcast(&dto.WSPayload{WSPayloadBase: dto.WSPayloadBase{OPCode: dto.WSInvalidSession}})
+}
+
+// PauseHeartbeatAck 暂停或者恢复回复心跳 ack，暂停后连接仍然保持，用于模拟半开的失效连接
+func (s *Server) PauseHeartbeatAck(paused bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.ackPause = paused
+}
+
+// CloseConnections 使用指定的错误码关闭所有连接，如 4009 会话超时可以 resume，4914 机器人已下架不能再连接
+func (s *Server) CloseConnections(code int, text string) {
+	for _, c := range s.connections() {
+		msg := wss.FormatCloseMessage(code, text)
+		c.writeLock.Lock()
+		_ = c.ws.WriteControl(wss.CloseMessage, msg, time.Now().Add(time.Second))
+		c.writeLock.Unlock()
+		_ = c.ws.Close()
+	}
+}
+
+func (s *Server) handleGateway(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(s.WebsocketAP())
+}
+
+func (s *Server) handleWebsocket(w http.ResponseWriter, r *http.Request) {
+	ws, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	c := &conn{ws: ws}
+	s.lock.Lock()
+	s.conns[c] = struct{}{}
+	s.stats.Connections++
+	s.lock.Unlock()
+	defer s.removeConn(c)
+
+	hello := &dto.WSPayload{
+		WSPayloadBase: dto.WSPayloadBase{OPCode: dto.WSHello},
+		Data:          &dto.WSHelloData{HeartbeatInterval: int(s.options.heartbeatInterval / time.Millisecond)},
+	}
+	if err := c.write(hello); err != nil {
+		return
+	}
+	for {
+		_, message, err := ws.ReadMessage()
+		if err != nil {
+			return
+		}
+		payload := &dto.WSPayload{}
+		if err := json.Unmarshal(message, payload); err != nil {
+			continue
+		}
+		if err := s.handlePayload(c, payload, message); err != nil {
+			return
+		}
+	}
+}
+
+// handlePayload 处理客户端发送的 opcode
+func (s *Server) handlePayload(c *conn, payload *dto.WSPayload, message []byte) error {
+	switch payload.OPCode {
+	case dto.WSHeartbeat:
+		s.lock.Lock()
+		s.stats.Heartbeats++
+		paused := s.ackPause
+		s.lock.Unlock()
+		if paused {
+			return nil
+		}
+		return c.write(&dto.WSPayload{WSPayloadBase: dto.WSPayloadBase{OPCode: dto.WSHeartbeatAck}})
+	case dto.WSIdentity:
+		data := &dto.WSIdentityData{}
+		if err := parseData(message, data); err != nil {
+			return err
+		}
+		return s.identify(c, data)
+	case dto.WSResume:
+		data := &dto.WSResumeData{}
+		if err := parseData(message, data); err != nil {
+			return err
+		}
+		return s.resume(c, data)
+	}
+	return nil
+}
+
+// identify 校验 token，创建 session 并返回 READY 事件
+func (s *Server) identify(c *conn, data *dto.WSIdentityData) error {
+	if s.options.token != "" && data.Token != s.options.token {
+		return s.closeConn(c, 4004, "invalid token")
+	}
+	shard := [2]uint32{0, 1}
+	if len(data.Shard) == 2 {
+		shard = [2]uint32{data.Shard[0], data.Shard[1]}
+	}
+	s.lock.Lock()
+	sess := &session{id: fmt.Sprintf("session-%d", atomic.AddUint64(&sessionSeq, 1)), shard: shard}
+	s.sessions[sess.id] = sess
+	s.stats.Identifies++
+	s.stats.Ready++
+	c.session = sess
+	sess.conn = c
+	s.lock.Unlock()
+
+	ready := &dto.WSReadyData{
+		Version:   1,
+		SessionID: sess.id,
+		Shard:     []uint32{shard[0], shard[1]},
+	}
+	ready.User.ID = s.options.botID
+	ready.User.Username = s.options.botName
+	ready.User.Bot = true
+	return s.send(sess, "READY", ready)
+}
+
+// resume 补发 seq 之后的事件，并发送 RESUMED 事件，session 不存在时返回 InvalidSession
+func (s *Server) resume(c *conn, data *dto.WSResumeData) error {
+	s.lock.Lock()
+	s.stats.Resumes++
+	sess, ok := s.sessions[data.SessionID]
+	if !ok || (s.options.token != "" && data.Token != s.options.token) {
+		s.lock.Unlock()
+		return c.write(&dto.WSPayload{WSPayloadBase: dto.WSPayloadBase{OPCode: dto.WSInvalidSession}})
+	}
+	c.session = sess
+	sess.conn = c
+	s.stats.Ready++
+	// 持有锁补发，保证补发的事件在新事件之前
+	for _, payload := range sess.history {
+		if payload.Seq <= data.Seq {
+			continue
+		}
+		if err := c.write(payload); err != nil {
+			s.lock.Unlock()
+			return err
+		}
+	}
+	s.lock.Unlock()
+	return s.send(sess, "RESUMED", "")
+}
+
+func (s *Server) dispatch(match func(*session) bool, eventType dto.EventType, data interface{}) int {
+	s.lock.Lock()
+	sessions := make([]*session, 0, len(s.sessions))
+	for _, sess := range s.sessions {
+		if match(sess) {
+			sessions = append(sessions, sess)
+		}
+	}
+	s.lock.Unlock()
+	for _, sess := range sessions {
+		_ = s.send(sess, eventType, data)
+	}
+	return len(sessions)
+}
+
+// send 生成带 seq 的事件，记录到 session 的历史中用于 resume，session 有连接时推送给连接
+func (s *Server) send(sess *session, eventType dto.EventType, data interface{}) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.seq++
+	sess.lastSeq++
+	payload := &dto.WSPayload{
+		WSPayloadBase: dto.WSPayloadBase{
+			OPCode: dto.WSDispatchEvent,
+			Seq:    sess.lastSeq,
+			Type:   eventType,
+			ID:     fmt.Sprintf("%s:%d", eventType, s.seq),
+		},
+		Data: data,
+	}
+	sess.history = append(sess.history, payload)
+	if sess.conn == nil {
+		return nil
+	}
+	// 持有锁写入，保证事件按照 seq 的顺序推送
+	return sess.conn.write(payload)
+}
+
+func (s *Server) broadcast(payload *dto.WSPayload) {
+	for _, c := range s.connections() {
+		_ = c.write(payload)
+	}
+}
+
+func (s *Server) closeConn(c *conn, code int, text string) error {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+	msg := wss.FormatCloseMessage(code, text)
+	_ = c.ws.WriteControl(wss.CloseMessage, msg, time.Now().Add(time.Second))
+	return c.ws.Close()
+}
+
+func (s *Server) connections() []*conn {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	conns := make([]*conn, 0, len(s.conns))
+	for c := range s.conns {
+		conns = append(conns, c)
+	}
+	return conns
+}
+
+func (s *Server) removeConn(c *conn) {
+	_ = c.ws.Close()
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.conns[c]; !ok {
+		return
+	}
+	delete(s.conns, c)
+	s.stats.Connections--
+	if c.session != nil {
+		s.stats.Ready--
+		if c.session.conn == c {
+			c.session.conn = nil
+		}
+	}
+}
+
+func (c *conn) write(payload *dto.WSPayload) error {
+	message, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+	return c.ws.WriteMessage(wss.TextMessage, message)
+}
+
+// parseData 解析 payload 中的 d 字段
+func parseData(message []byte, target interface{}) error {
+	payload := &struct {
+		Data json.RawMessage `json:"d"`
+	}{}
+	if err := json.Unmarshal(message, payload); err != nil {
+		return err
+	}
+	return json.Unmarshal(payload.Data, target)
+}
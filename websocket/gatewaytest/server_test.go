@@ -0,0 +1,141 @@
+package gatewaytest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	wss "github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/tencent-connect/botgo/dto"
+	"github.com/tencent-connect/botgo/errs"
+	"github.com/tencent-connect/botgo/event"
+	"github.com/tencent-connect/botgo/sessions/manager"
+	"github.com/tencent-connect/botgo/token"
+	"github.com/tencent-connect/botgo/websocket/client"
+)
+
+const testTimeout = 5 * time.Second
+
+func newClient(session dto.Session, dispatcher *event.Dispatcher) (*client.Client, chan error) {
+	c := (&client.Client{}).New(session, dispatcher).(*client.Client)
+	return c, make(chan error, 1)
+}
+
+func listen(t *testing.T, c *client.Client, done chan error, resume bool) {
+	t.Helper()
+	assert.Nil(t, c.Connect())
+	if resume {
+		assert.Nil(t, c.Resume())
+	} else {
+		assert.Nil(t, c.Identify())
+	}
+	go func() { done <- c.Listening() }()
+}
+
+func TestServer_GatewayBot(t *testing.T) {
+	s := NewServer(WithShards(2))
+	defer s.Close()
+
+	resp, err := http.Get(s.URL + GatewayBotPath)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	ap := &dto.WebsocketAP{}
+	assert.Nil(t, json.NewDecoder(resp.Body).Decode(ap))
+	assert.Equal(t, s.WebsocketURL, ap.URL)
+	assert.Equal(t, uint32(2), ap.Shards)
+}
+
+func TestServer_IdentifyDispatchResume(t *testing.T) {
+	s := NewServer(WithToken("1.token"))
+	defer s.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	received := make(chan string, 10)
+	ready := make(chan string, 1)
+	dispatcher := event.NewDispatcher()
+	dispatcher.RegisterHandlers(
+		event.ReadyHandler(func(_ *dto.WSPayload, data *dto.WSReadyData) {
+			ready <- data.SessionID
+		}),
+		event.ATMessageEventHandler(func(_ *dto.WSPayload, data *dto.WSATMessageData) error {
+			received <- data.Content
+			return nil
+		}),
+	)
+	session := dto.Session{
+		URL:    s.WebsocketURL,
+		Token:  *token.BotToken(1, "token"),
+		Shards: dto.ShardConfig{ShardCount: 1},
+	}
+	c, done := newClient(session, dispatcher)
+	listen(t, c, done, false)
+	sessionID := <-ready
+	assert.NotEmpty(t, sessionID)
+
+	assert.Equal(t, 1, s.Dispatch(dto.EventAtMessageCreate, &dto.Message{Content: "first"}))
+	assert.Equal(t, "first", <-received)
+	assert.Nil(t, s.Wait(ctx, func(st Stats) bool { return st.Heartbeats > 0 }))
+
+	// 要求重连，断开期间的事件在 resume 后补发
+	s.Reconnect()
+	assert.Equal(t, errs.ErrNeedReConnect, <-done)
+	assert.Nil(t, s.Wait(ctx, func(st Stats) bool { return st.Connections == 0 }))
+	assert.Equal(t, 1, s.Dispatch(dto.EventAtMessageCreate, &dto.Message{Content: "missed"}))
+
+	c, done = newClient(*c.Session(), dispatcher)
+	listen(t, c, done, true)
+	assert.Equal(t, "missed", <-received)
+	// 等待 RESUMED 之后的事件处理完成，保证 session 中的 seq 已经更新
+	assert.Equal(t, 1, s.Dispatch(dto.EventAtMessageCreate, &dto.Message{Content: "resumed"}))
+	assert.Equal(t, "resumed", <-received)
+	assert.Equal(t, 1, s.Stats().Identifies)
+	assert.Equal(t, 1, s.Stats().Resumes)
+
+	// 4009 可以 resume
+	s.CloseConnections(4009, "session timed out")
+	err := <-done
+	assert.True(t, wss.IsCloseError(err, 4009))
+	assert.False(t, manager.CanNotResume(err))
+
+	// 4914 机器人已下架，不能再鉴权
+	c, done = newClient(*c.Session(), dispatcher)
+	listen(t, c, done, true)
+	assert.Nil(t, s.Wait(ctx, func(st Stats) bool { return st.Ready == 1 }))
+	s.CloseConnections(4914, "bot offline")
+	assert.True(t, manager.CanNotIdentify(<-done))
+}
+
+func TestServer_InvalidSession(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	session := dto.Session{
+		URL:    s.WebsocketURL,
+		Token:  *token.BotToken(1, "token"),
+		ID:     "unknown",
+		Shards: dto.ShardConfig{ShardCount: 1},
+	}
+	c, done := newClient(session, event.NewDispatcher())
+	listen(t, c, done, true)
+	assert.Equal(t, errs.ErrInvalidSession, <-done)
+	assert.Equal(t, 1, s.Stats().Resumes)
+}
+
+func TestServer_InvalidToken(t *testing.T) {
+	s := NewServer(WithToken("1.token"))
+	defer s.Close()
+
+	session := dto.Session{
+		URL:    s.WebsocketURL,
+		Token:  *token.BotToken(1, "wrong"),
+		Shards: dto.ShardConfig{ShardCount: 1},
+	}
+	c, done := newClient(session, event.NewDispatcher())
+	listen(t, c, done, false)
+	assert.NotNil(t, <-done)
+	assert.Equal(t, 0, s.Stats().Identifies)
+}
@@ -2,12 +2,13 @@ package websocket
 
 import (
 	"github.com/tencent-connect/botgo/dto"
+	"github.com/tencent-connect/botgo/event"
 )
 
 // WebSocket 需要实现的接口
 type WebSocket interface {
-	// New 创建一个新的ws实例，需要传递 session 对象
-	New(session dto.Session) WebSocket
+	// New 创建一个新的ws实例，需要传递 session 对象，以及接收事件的分发器，分发器为 nil 时使用 event.DefaultDispatcher
+	New(session dto.Session, dispatcher *event.Dispatcher) WebSocket
 	// Connect 连接到 wss 地址
 	Connect() error
 	// Identify 鉴权连接
@@ -20,6 +21,6 @@ type WebSocket interface {
 	Listening() error
 	// Write 发送数据
 	Write(message *dto.WSPayload) error
-	// Close 关闭连接
+	// Close 主动关闭连接，发送 close frame，Listening 在已收到的事件处理完成后返回 errs.ErrConnClosed
 	Close()
 }
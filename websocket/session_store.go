@@ -0,0 +1,50 @@
+package websocket
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tencent-connect/botgo/dto"
+)
+
+// SessionState 需要持久化的 session 状态，token 等敏感信息不会被保存
+type SessionState struct {
+	ID         string    `json:"id"`
+	LastSeq    uint32    `json:"last_seq"`
+	ShardID    uint32    `json:"shard_id"`
+	ShardCount uint32    `json:"shard_count"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// SessionStore 持久化 session 状态，进程重启后可以使用保存的 session id 与 seq 进行 resume，而不是重新鉴权
+// 实现见 sessions/store
+type SessionStore interface {
+	// Load 加载 session 对应 shard 保存的状态，不存在时返回 nil
+	Load(ctx context.Context, session dto.Session) (*SessionState, error)
+	// Save 保存 session 的状态，seq 的更新按照间隔合并后调用，收到 READY、RESUMED 事件与连接退出时也会调用
+	Save(ctx context.Context, session dto.Session) error
+	// Delete 删除 session 对应 shard 保存的状态，session 失效时调用
+	Delete(ctx context.Context, session dto.Session) error
+}
+
+// SessionStoreSetter 支持持久化 session 状态的 websocket 实现，session manager 创建连接后会设置 store
+type SessionStoreSetter interface {
+	SetSessionStore(store SessionStore)
+}
+
+// NewSessionState 根据 session 生成需要持久化的状态
+func NewSessionState(session dto.Session) *SessionState {
+	return &SessionState{
+		ID:         session.ID,
+		LastSeq:    session.LastSeq,
+		ShardID:    session.Shards.ShardID,
+		ShardCount: session.Shards.ShardCount,
+		UpdatedAt:  time.Now(),
+	}
+}
+
+// SessionStoreKey 返回 session 在 store 中的 key，由 appid 与 shard 组成，shard 数量变化后之前保存的状态不再使用
+func SessionStoreKey(session dto.Session) string {
+	return fmt.Sprintf("%d_%d_%d", session.Token.AppID, session.Shards.ShardID, session.Shards.ShardCount)
+}